@@ -0,0 +1,88 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/perses/mcp-server/internal/permcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+)
+
+func runApply(args []string) error {
+	flags := flag.NewFlagSet("apply", flag.ExitOnError)
+	configFile := flags.String("config", "", "Path to the YAML configuration file")
+	instance := flags.String("instance", "", "Perses instance to apply to (defaults to the primary instance)")
+	path := flags.String("f", "", "File or directory containing the objects to apply")
+	prune := flags.Bool("prune", false, "Delete the objects that are not in the set, within the kinds and projects present in the set")
+	pruneGlobal := flags.Bool("prune-global", false, "With --prune, also delete the global objects of the kinds present in the set that are not in the set")
+	dryRun := flags.Bool("dry-run", false, "Only report what would be done")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return fmt.Errorf("the -f flag is required")
+	}
+
+	cfg, err := permcp.ResolveConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("unable to resolve configuration: %w", err)
+	}
+	instanceCfg, err := cfg.GetInstance(*instance)
+	if err != nil {
+		return err
+	}
+	if instanceCfg.ReadOnly && !*dryRun {
+		return fmt.Errorf("instance '%s' is read-only", instanceCfg.Name)
+	}
+	list, err := gitops.Load(*path)
+	if err != nil {
+		return err
+	}
+	for _, obj := range list {
+		if r := tools.ResourceFromKind(obj.GetKind()); !instanceCfg.AllowsResource(string(r)) {
+			return fmt.Errorf("%s cannot be applied: resource '%s' is not available on instance '%s'", objects.String(obj), r, instanceCfg.Name)
+		}
+	}
+	client, err := permcp.NewInstanceClient(cfg, *instanceCfg)
+	if err != nil {
+		return err
+	}
+	results, err := gitops.Apply(client, list, gitops.ApplyOptions{Prune: *prune, PruneGlobal: *pruneGlobal, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ACTION\tKIND\tPROJECT\tNAME\tERROR")
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Action, result.Kind, result.Project, result.Name, result.Error)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d object(s) could not be applied", failed)
+	}
+	return nil
+}
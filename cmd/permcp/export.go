@@ -0,0 +1,69 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/perses/mcp-server/internal/permcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	configFile := flags.String("config", "", "Path to the YAML configuration file")
+	instance := flags.String("instance", "", "Perses instance to export from (defaults to the primary instance)")
+	project := flags.String("project", "", "Project to export")
+	output := flags.String("output", ".", "Directory where the files are written")
+	format := flags.String("format", string(gitops.YAMLFormat), "Format of the files: yaml or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *project == "" {
+		return fmt.Errorf("the -project flag is required")
+	}
+	fileFormat, err := gitops.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := permcp.ResolveConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("unable to resolve configuration: %w", err)
+	}
+	instanceCfg, err := cfg.GetInstance(*instance)
+	if err != nil {
+		return err
+	}
+	client, err := permcp.NewInstanceClient(cfg, *instanceCfg)
+	if err != nil {
+		return err
+	}
+	list, err := gitops.ExportProject(client, *project, gitops.ProjectKinds)
+	if err != nil {
+		return err
+	}
+	files, err := gitops.ToFiles(list, fileFormat)
+	if err != nil {
+		return err
+	}
+	if err := gitops.WriteFiles(*output, files); err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Println(file.Path)
+	}
+	return nil
+}
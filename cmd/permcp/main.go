@@ -15,6 +15,7 @@ package main
 
 import (
 	"flag"
+	"os"
 
 	"github.com/perses/common/app"
 	commongLogrus "github.com/perses/common/logrus"
@@ -22,7 +23,22 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// commands are the subcommands of permcp. Without subcommand, the MCP server is started.
+var commands = map[string]func(args []string) error{
+	"export": runExport,
+	"apply":  runApply,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				logrus.WithError(err).Fatalf("%s failed", os.Args[1])
+			}
+			return
+		}
+	}
+
 	commongLogrus.InitFlag()
 	configFile := flag.String("config", "", "Path to the YAML configuration file")
 	flag.Parse()
@@ -0,0 +1,112 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/modelcontextprotocol/go-sdk/oauthex"
+	"github.com/perses/mcp-server/pkg/oauth"
+)
+
+// buildHTTPHandler returns the handler serving the MCP endpoint, protected according to the configuration.
+func (s *server) buildHTTPHandler() (http.Handler, error) {
+	getServer := func(req *http.Request) *mcp.Server {
+		return s.mcpServer
+	}
+	if s.cfg.ForwardUserToken {
+		getServer = s.newUserSessionServer
+	}
+	mcpHandler := mcp.NewStreamableHTTPHandler(getServer, nil)
+
+	if s.cfg.OAuth == nil {
+		if s.cfg.ForwardUserToken {
+			return auth.RequireBearerToken(forwardedTokenVerifier, nil)(mcpHandler), nil
+		}
+		return mcpHandler, nil
+	}
+
+	verifier, err := oauth.NewVerifier(*s.cfg.OAuth)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the OAuth token verifier: %w", err)
+	}
+	metadataHandler := auth.ProtectedResourceMetadataHandler(&oauthex.ProtectedResourceMetadata{
+		Resource:               s.cfg.OAuth.Resource,
+		AuthorizationServers:   s.cfg.OAuth.AuthorizationServers,
+		ScopesSupported:        s.cfg.OAuth.ScopesSupported(),
+		BearerMethodsSupported: []string{"header"},
+		ResourceName:           "Perses MCP Server",
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(oauth.WellKnownPath, metadataHandler)
+	mux.Handle(oauth.WellKnownPath+"/", metadataHandler)
+	mux.Handle("/", auth.RequireBearerToken(verifier, &auth.RequireBearerTokenOptions{
+		ResourceMetadataURL: s.cfg.OAuth.ResourceMetadataURL(),
+	})(mcpHandler))
+	return mux, nil
+}
+
+// scopeMiddleware rejects the tool calls for which the access token doesn't hold the required scopes,
+// and hides these tools from the tool list.
+func scopeMiddleware(requiredScopes map[string][]string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			var tokenInfo *auth.TokenInfo
+			if extra := req.GetExtra(); extra != nil {
+				tokenInfo = extra.TokenInfo
+			}
+			switch method {
+			case "tools/call":
+				params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+				if !ok {
+					break
+				}
+				if missing := missingScopes(tokenInfo, requiredScopes[params.Name]); len(missing) > 0 {
+					result := &mcp.CallToolResult{}
+					result.SetError(fmt.Errorf("insufficient scope: tool '%s' requires the scope(s) %s", params.Name, strings.Join(missing, ", ")))
+					return result, nil
+				}
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if err != nil {
+					return result, err
+				}
+				if list, ok := result.(*mcp.ListToolsResult); ok {
+					list.Tools = slices.DeleteFunc(list.Tools, func(tool *mcp.Tool) bool {
+						return len(missingScopes(tokenInfo, requiredScopes[tool.Name])) > 0
+					})
+				}
+				return result, nil
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func missingScopes(tokenInfo *auth.TokenInfo, required []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if tokenInfo == nil || !slices.Contains(tokenInfo.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,169 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/oauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// newJWKSServer returns a fake authorization server publishing the public part of key.
+func newJWKSServer(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "key-1", Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func signToken(t *testing.T, key *ecdsa.PrivateKey, issuer string, scope string) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{}).WithHeader(jose.HeaderKey("kid"), "key-1"))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(map[string]any{
+		"iss":   issuer,
+		"sub":   "alice",
+		"aud":   testResource,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": scope,
+	}).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+// bearerTransport adds the access token to every request of the MCP client.
+type bearerTransport struct {
+	token string
+}
+
+func (b *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newOAuthServer(t *testing.T) (*httptest.Server, *ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issuer := newJWKSServer(t, key)
+	cfg := Config{
+		Transport: HTTPTransport,
+		OAuth: &oauth.Config{
+			Resource:             testResource,
+			AuthorizationServers: []string{issuer.URL},
+			JWKSURL:              issuer.URL,
+		},
+	}
+	require.NoError(t, cfg.Verify())
+
+	s := &server{cfg: cfg, mcpServer: newMCPServer()}
+	instances, err := s.buildInstances(func(cfg InstanceConfig) (PersesServerConfig, bool) {
+		return cfg.PersesServer, true
+	})
+	require.NoError(t, err)
+	s.registerTools(s.mcpServer, instances)
+	handler, err := s.buildHTTPHandler()
+	require.NoError(t, err)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv, key, issuer.URL
+}
+
+func TestOAuthProtectedResourceMetadata(t *testing.T) {
+	srv, _, issuer := newOAuthServer(t)
+	resp, err := http.Get(srv.URL + oauth.WellKnownPath + "/mcp")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var metadata map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&metadata))
+	assert.Equal(t, testResource, metadata["resource"])
+	assert.Equal(t, []any{issuer}, metadata["authorization_servers"])
+	assert.Equal(t, []any{oauth.DefaultReadScope, oauth.DefaultWriteScope}, metadata["scopes_supported"])
+}
+
+func TestOAuthRejectsInvalidTokens(t *testing.T) {
+	srv, key, issuer := newOAuthServer(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	testSuite := []struct {
+		title string
+		token string
+	}{
+		{title: "no token"},
+		{title: "bad signature", token: signToken(t, otherKey, issuer, oauth.DefaultReadScope)},
+		{title: "wrong issuer", token: signToken(t, key, "https://evil.example.com", oauth.DefaultReadScope)},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "resource_metadata=")
+		})
+	}
+}
+
+func TestOAuthScopes(t *testing.T) {
+	srv, key, issuer := newOAuthServer(t)
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:             srv.URL,
+		HTTPClient:           &http.Client{Transport: &bearerTransport{token: signToken(t, key, issuer, oauth.DefaultReadScope)}},
+		DisableStandaloneSSE: true,
+	}, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	list, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	names := make([]string, 0, len(list.Tools))
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "perses_list_projects")
+	assert.NotContains(t, names, "perses_create_project")
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "perses_create_project", Arguments: map[string]any{"project": "shop"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 1)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "insufficient scope")
+}
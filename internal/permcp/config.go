@@ -15,12 +15,16 @@ package permcp
 
 import (
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	commonconfig "github.com/perses/common/config"
 	"github.com/perses/common/set"
+	"github.com/perses/mcp-server/pkg/lint"
+	"github.com/perses/mcp-server/pkg/oauth"
+	"github.com/perses/mcp-server/pkg/protection"
 	"github.com/perses/mcp-server/pkg/tools"
-	"github.com/perses/perses/pkg/client/config"
 	"github.com/perses/perses/pkg/model/api/v1/common"
 )
 
@@ -47,6 +51,20 @@ type Config struct {
 	// ListenAddress is the address to listen on for HTTP transport (e.g., ":8000")
 	ListenAddress string `yaml:"listen_address,omitempty"`
 
+	// TLSConfig enables TLS (and optionally mTLS) on the HTTP listener.
+	TLSConfig *TLSConfig `yaml:"tls_config,omitempty"`
+
+	// OAuth protects the HTTP endpoint following the MCP authorization specification.
+	// Only supported with the HTTP transport.
+	OAuth *oauth.Config `yaml:"oauth,omitempty"`
+
+	// ForwardUserToken indicates if the bearer token sent by the MCP client in the HTTP Authorization header
+	// should be used to talk to Perses instead of the perses_server credentials.
+	// Each MCP session then gets its own Perses client, so the Perses RBAC applies to each user.
+	// The token is only sent to the instances that set forward_user_token, the other instances are not available.
+	// Only supported with the HTTP transport, and not with OAuth since the tokens are issued for the MCP server.
+	ForwardUserToken bool `yaml:"forward_user_token,omitempty"`
+
 	// ReadOnly indicates if the server should operate in read-only mode
 	ReadOnly bool `yaml:"read_only,omitempty"`
 
@@ -56,10 +74,77 @@ type Config struct {
 	// AllowedResources is the normalized list of resources to register.
 	AllowedResources []string `yaml:"-"`
 
+	// Protected lists the objects that can never be modified through the MCP server,
+	// even when ReadOnly is false.
+	Protected []protection.Rule `yaml:"protected,omitempty"`
+
+	// ManifestsDirectory is the directory of object definitions (e.g. a git checkout of exported projects)
+	// that the tools can read. When empty, the tools only accept definitions given in their arguments.
+	ManifestsDirectory string `yaml:"manifests_directory,omitempty"`
+
+	// SnapshotsDirectory is the directory where the objects can be saved before being deleted (e.g. by perses_delete_project),
+	// and from which perses_diff_dashboards reads the saved dashboards. When empty, the snapshots are disabled.
+	SnapshotsDirectory string `yaml:"snapshots_directory,omitempty"`
+
+	// TemplatesDirectory is the directory of dashboard templates, in addition to the built-in ones.
+	// A template of the directory replaces the built-in template with the same name.
+	TemplatesDirectory string `yaml:"templates_directory,omitempty"`
+	// Lint tunes the rules applied by perses_lint_dashboard. Every built-in rule is enabled when it is not set.
+	Lint *lint.Config `yaml:"lint,omitempty"`
+
 	// PersesServer is the configuration for connecting to the Perses backend server.
 	// Supports multiple authentication methods: Authorization (Bearer token),
 	// OAuth, BasicAuth, K8sAuth, and NativeAuth.
-	PersesServer config.RestConfigClient `yaml:"perses_server"`
+	// The credentials are refreshed automatically when possible (see PersesServerConfig).
+	// It is ignored when Instances is set.
+	PersesServer PersesServerConfig `yaml:"perses_server"`
+
+	// Instances lists the Perses backends the MCP server can talk to.
+	// When empty, a single instance named "default" is built from PersesServer, ReadOnly and Resources.
+	Instances []InstanceConfig `yaml:"instances,omitempty"`
+
+	// PrimaryInstance is the name of the instance used when a tool call doesn't provide the instance argument.
+	// Defaults to the first instance.
+	PrimaryInstance string `yaml:"primary_instance,omitempty"`
+}
+
+// InstanceConfig describes a named Perses backend.
+type InstanceConfig struct {
+	// Name identifies the instance in the instance argument of the tools.
+	Name string `yaml:"name"`
+
+	// PersesServer is the configuration for connecting to this Perses backend.
+	PersesServer PersesServerConfig `yaml:"perses_server"`
+
+	// ReadOnly indicates if the write tools are disabled for this instance.
+	// The instance is also read-only when the global read_only is set.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// Resources is a comma-separated list of resources available on this instance.
+	// Defaults to the global resources.
+	Resources string `yaml:"resources,omitempty"`
+
+	// AllowedResources is the normalized list of resources available on this instance.
+	AllowedResources []string `yaml:"-"`
+
+	// ForwardUserToken indicates if the bearer token of the MCP caller can be sent to this instance,
+	// when the global forward_user_token is set. It is set on the default instance built from perses_server.
+	ForwardUserToken bool `yaml:"forward_user_token,omitempty"`
+}
+
+func (c *InstanceConfig) Verify() error {
+	if c.Name == "" {
+		return fmt.Errorf("name must be set")
+	}
+	if c.PersesServer.URL == nil {
+		c.PersesServer.URL = common.MustParseURL("http://localhost:8080")
+	}
+	if err := c.PersesServer.Validate(); err != nil {
+		return fmt.Errorf("invalid perses client configuration: %w", err)
+	}
+	c.Resources = strings.TrimSpace(c.Resources)
+	c.AllowedResources = parseAllowedResources(c.Resources)
+	return validateAllowedResources(c.AllowedResources)
 }
 
 func (c *Config) Verify() error {
@@ -76,8 +161,30 @@ func (c *Config) Verify() error {
 		return fmt.Errorf("unsupported transport %q. valid values are: stdio, http", c.Transport)
 	}
 
-	if c.PersesServer.URL == nil {
-		c.PersesServer.URL = common.MustParseURL("http://localhost:8080")
+	if c.ForwardUserToken && c.Transport != HTTPTransport {
+		return fmt.Errorf("forward_user_token is only supported with the http transport")
+	}
+
+	if c.TLSConfig != nil {
+		if c.Transport != HTTPTransport {
+			return fmt.Errorf("tls_config is only supported with the http transport")
+		}
+		if err := c.TLSConfig.Verify(); err != nil {
+			return fmt.Errorf("invalid tls_config: %w", err)
+		}
+	}
+
+	if c.OAuth != nil {
+		if c.Transport != HTTPTransport {
+			return fmt.Errorf("oauth is only supported with the http transport")
+		}
+		if c.ForwardUserToken {
+			// The tokens are issued for the MCP server, passing them through to Perses would let Perses (or anyone it leaks them to) replay them.
+			return fmt.Errorf("forward_user_token can't be used with oauth")
+		}
+		if err := c.OAuth.Verify(); err != nil {
+			return fmt.Errorf("invalid oauth configuration: %w", err)
+		}
 	}
 
 	if c.ListenAddress == "" {
@@ -86,20 +193,107 @@ func (c *Config) Verify() error {
 		c.ListenAddress = ":" + c.ListenAddress
 	}
 
-	if err := c.PersesServer.Validate(); err != nil {
-		return fmt.Errorf("invalid perses client configuration: %w", err)
+	if c.ManifestsDirectory != "" {
+		if info, err := os.Stat(c.ManifestsDirectory); err != nil || !info.IsDir() {
+			return fmt.Errorf("manifests_directory %q is not a directory", c.ManifestsDirectory)
+		}
+	}
+
+	if c.SnapshotsDirectory != "" {
+		if info, err := os.Stat(c.SnapshotsDirectory); err != nil || !info.IsDir() {
+			return fmt.Errorf("snapshots_directory %q is not a directory", c.SnapshotsDirectory)
+		}
+	}
+
+	if c.TemplatesDirectory != "" {
+		if info, err := os.Stat(c.TemplatesDirectory); err != nil || !info.IsDir() {
+			return fmt.Errorf("templates_directory %q is not a directory", c.TemplatesDirectory)
+		}
+	}
+
+	if c.Lint != nil {
+		if err := c.Lint.Verify(); err != nil {
+			return fmt.Errorf("invalid lint configuration: %w", err)
+		}
+	}
+
+	for i := range c.Protected {
+		if err := c.Protected[i].Verify(); err != nil {
+			return fmt.Errorf("invalid protected rule at index %d: %w", i, err)
+		}
 	}
 
 	c.Resources = strings.TrimSpace(c.Resources)
-	c.parseAllowedResources()
+	c.AllowedResources = parseAllowedResources(c.Resources)
+	if err := validateAllowedResources(c.AllowedResources); err != nil {
+		return err
+	}
+
+	return c.verifyInstances()
+}
+
+func (c *Config) verifyInstances() error {
+	if len(c.Instances) == 0 {
+		c.Instances = []InstanceConfig{{
+			Name:             "default",
+			PersesServer:     c.PersesServer,
+			ForwardUserToken: c.ForwardUserToken,
+		}}
+	}
+
+	names := set.New[string]()
+	for i := range c.Instances {
+		instance := &c.Instances[i]
+		instance.ReadOnly = instance.ReadOnly || c.ReadOnly
+		if strings.TrimSpace(instance.Resources) == "" {
+			instance.Resources = c.Resources
+		}
+		if err := instance.Verify(); err != nil {
+			return fmt.Errorf("invalid instance at index %d: %w", i, err)
+		}
+		if names.Contains(instance.Name) {
+			return fmt.Errorf("instance %q is defined more than once", instance.Name)
+		}
+		if instance.ForwardUserToken && !c.ForwardUserToken {
+			return fmt.Errorf("instance %q sets forward_user_token, but forward_user_token is not set", instance.Name)
+		}
+		names.Add(instance.Name)
+	}
+	if c.PrimaryInstance == "" {
+		c.PrimaryInstance = c.Instances[0].Name
+	} else if !names.Contains(c.PrimaryInstance) {
+		return fmt.Errorf("primary_instance %q doesn't match any instance", c.PrimaryInstance)
+	}
+	if c.ForwardUserToken {
+		if primary, _ := c.GetInstance(c.PrimaryInstance); !primary.ForwardUserToken {
+			return fmt.Errorf("forward_user_token is set, but the primary instance %q doesn't set forward_user_token", c.PrimaryInstance)
+		}
+	}
+	return nil
+}
+
+// GetInstance returns the configuration of the named instance, or of the primary instance when name is empty.
+func (c *Config) GetInstance(name string) (*InstanceConfig, error) {
+	if name == "" {
+		name = c.PrimaryInstance
+	}
+	for i := range c.Instances {
+		if c.Instances[i].Name == name {
+			return &c.Instances[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown Perses instance '%s'", name)
+}
 
-	return c.validateAllowedResources()
+// AllowsResource returns true when the tools of the given resource are available on this instance.
+func (c *InstanceConfig) AllowsResource(resource string) bool {
+	return len(c.AllowedResources) == 0 || slices.Contains(c.AllowedResources, resource)
 }
 
-func (c *Config) validateAllowedResources() error {
+func validateAllowedResources(allowedResources []string) error {
 	validSet := set.New(tools.ValidResources...)
 	var invalid []string
-	for _, rs := range c.AllowedResources {
+	for _, rs := range allowedResources {
 		if !validSet.Contains(tools.Resource(rs)) {
 			invalid = append(invalid, rs)
 		}
@@ -117,12 +311,14 @@ func (c *Config) validateAllowedResources() error {
 	return nil
 }
 
-func (c *Config) parseAllowedResources() {
-	for resource := range strings.SplitSeq(c.Resources, ",") {
+func parseAllowedResources(resources string) []string {
+	var allowedResources []string
+	for resource := range strings.SplitSeq(resources, ",") {
 		resource = strings.TrimSpace(resource)
 		if resource == "" {
 			continue
 		}
-		c.AllowedResources = append(c.AllowedResources, strings.ToLower(resource))
+		allowedResources = append(allowedResources, strings.ToLower(resource))
 	}
+	return allowedResources
 }
@@ -0,0 +1,88 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/oauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func testOAuthConfig() *oauth.Config {
+	return &oauth.Config{
+		Resource:             "https://mcp.example.com/mcp",
+		AuthorizationServers: []string{"https://auth.example.com"},
+		JWKSURL:              "https://auth.example.com/jwks.json",
+	}
+}
+
+func TestConfigVerifyForwardUserToken(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			title: "forward_user_token on the default instance",
+			cfg:   Config{Transport: HTTPTransport, ForwardUserToken: true},
+		},
+		{
+			title:   "forward_user_token with stdio",
+			cfg:     Config{Transport: STDIOTransport, ForwardUserToken: true},
+			wantErr: true,
+		},
+		{
+			title:   "forward_user_token with oauth",
+			cfg:     Config{Transport: HTTPTransport, ForwardUserToken: true, OAuth: testOAuthConfig()},
+			wantErr: true,
+		},
+		{
+			title: "oauth without forward_user_token",
+			cfg:   Config{Transport: HTTPTransport, OAuth: testOAuthConfig()},
+		},
+		{
+			title: "primary instance opts in",
+			cfg: Config{Transport: HTTPTransport, ForwardUserToken: true, Instances: []InstanceConfig{
+				{Name: "production", ForwardUserToken: true},
+				{Name: "staging"},
+			}},
+		},
+		{
+			title: "primary instance doesn't opt in",
+			cfg: Config{Transport: HTTPTransport, ForwardUserToken: true, PrimaryInstance: "staging", Instances: []InstanceConfig{
+				{Name: "production", ForwardUserToken: true},
+				{Name: "staging"},
+			}},
+			wantErr: true,
+		},
+		{
+			title: "instance opts in without forward_user_token",
+			cfg: Config{Transport: HTTPTransport, Instances: []InstanceConfig{
+				{Name: "production", ForwardUserToken: true},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.cfg.Verify()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fileCheckInterval is how often the files are checked for modifications, so that they are not checked on every TLS handshake.
+const fileCheckInterval = time.Second
+
+// fileWatcher caches a value loaded from files and loads it again when one of the files is modified.
+// If the new files cannot be loaded (e.g. while they are being replaced), the previous value is kept
+// until the files are modified again.
+type fileWatcher[T any] struct {
+	files []string
+	load  func() (*T, error)
+	// checkInterval overrides fileCheckInterval.
+	checkInterval time.Duration
+	mutex         sync.RWMutex
+	value         *T
+	modTimes      []time.Time
+	checkedAt     time.Time
+}
+
+func (w *fileWatcher[T]) get() (*T, error) {
+	w.mutex.RLock()
+	value, fresh := w.cached()
+	w.mutex.RUnlock()
+	if fresh {
+		return value, nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	// Another caller may have checked the files while the lock was released.
+	if value, fresh := w.cached(); fresh {
+		return value, nil
+	}
+	w.checkedAt = time.Now()
+	modTimes := make([]time.Time, len(w.files))
+	for i, file := range w.files {
+		info, err := os.Stat(file)
+		if err != nil {
+			if w.value != nil {
+				logrus.WithError(err).WithField("file", file).Warn("unable to check the file, keeping the previous version")
+				return w.value, nil
+			}
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	if w.value != nil && slices.EqualFunc(modTimes, w.modTimes, time.Time.Equal) {
+		return w.value, nil
+	}
+	value, err := w.load()
+	if err != nil {
+		if w.value != nil {
+			// The modification times are recorded anyway, so that the files are only loaded again once they change.
+			w.modTimes = modTimes
+			logrus.WithError(err).WithField("files", w.files).Warn("unable to reload the files, keeping the previous version")
+			return w.value, nil
+		}
+		return nil, err
+	}
+	if w.value != nil {
+		logrus.WithField("files", w.files).Info("files reloaded")
+	}
+	w.value = value
+	w.modTimes = modTimes
+	return w.value, nil
+}
+
+// cached returns the current value, and whether the files were checked recently enough for it to be used as is.
+func (w *fileWatcher[T]) cached() (*T, bool) {
+	interval := w.checkInterval
+	if interval == 0 {
+		interval = fileCheckInterval
+	}
+	return w.value, w.value != nil && time.Since(w.checkedAt) < interval
+}
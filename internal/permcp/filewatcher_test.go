@@ -0,0 +1,124 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWatcher returns a watcher of a single file whose content must be "valid-*", and the number of loads.
+func newTestWatcher(t *testing.T, checkInterval time.Duration) (*fileWatcher[string], string, *int) {
+	file := filepath.Join(t.TempDir(), "value")
+	loads := 0
+	watcher := &fileWatcher[string]{
+		files:         []string{file},
+		checkInterval: checkInterval,
+		load: func() (*string, error) {
+			loads++
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			value := string(data)
+			if len(value) < 6 || value[:6] != "valid-" {
+				return nil, fmt.Errorf("invalid content %q", value)
+			}
+			return &value, nil
+		},
+	}
+	return watcher, file, &loads
+}
+
+// writeFile writes the file with a modification time in the past, so that each write changes it.
+func writeFile(t *testing.T, file string, content string, age time.Duration) {
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	modTime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func TestFileWatcherReload(t *testing.T) {
+	watcher, file, loads := newTestWatcher(t, time.Nanosecond)
+	_, err := watcher.get()
+	assert.Error(t, err, "the file doesn't exist yet")
+
+	writeFile(t, file, "valid-1", 3*time.Hour)
+	value, err := watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-1", *value)
+
+	// The file is not modified, so it isn't loaded again.
+	loadsBefore := *loads
+	_, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, loadsBefore, *loads)
+
+	writeFile(t, file, "valid-2", 2*time.Hour)
+	value, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-2", *value)
+}
+
+func TestFileWatcherKeepsPreviousValue(t *testing.T) {
+	watcher, file, loads := newTestWatcher(t, time.Nanosecond)
+	writeFile(t, file, "valid-1", 3*time.Hour)
+	_, err := watcher.get()
+	require.NoError(t, err)
+
+	writeFile(t, file, "broken", 2*time.Hour)
+	value, err := watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-1", *value)
+
+	// The broken file is not loaded again until it changes.
+	loadsBefore := *loads
+	value, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-1", *value)
+	assert.Equal(t, loadsBefore, *loads)
+
+	writeFile(t, file, "valid-2", time.Hour)
+	value, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-2", *value)
+
+	require.NoError(t, os.Remove(file))
+	value, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-2", *value)
+}
+
+func TestFileWatcherThrottlesChecks(t *testing.T) {
+	watcher, file, _ := newTestWatcher(t, time.Hour)
+	writeFile(t, file, "valid-1", 3*time.Hour)
+	_, err := watcher.get()
+	require.NoError(t, err)
+
+	// The modification is only seen once the check interval is over.
+	writeFile(t, file, "valid-2", 2*time.Hour)
+	value, err := watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-1", *value)
+
+	watcher.checkedAt = time.Now().Add(-2 * time.Hour)
+	value, err = watcher.get()
+	require.NoError(t, err)
+	assert.Equal(t, "valid-2", *value)
+}
@@ -0,0 +1,88 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools/instance"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/sirupsen/logrus"
+)
+
+// forwardedTokenVerifier accepts any bearer token, since it is Perses that validates it on every call.
+// The UserID is derived from the token so that a session cannot be reused with another token.
+func forwardedTokenVerifier(_ context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	return &auth.TokenInfo{
+		UserID:     tokenFingerprint(token),
+		Expiration: time.Now().Add(time.Minute),
+	}, nil
+}
+
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func bearerToken(req *http.Request) string {
+	fields := strings.Fields(req.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		return ""
+	}
+	return fields[1]
+}
+
+// withUserToken returns a copy of the Perses server configuration authenticated with the token of the MCP caller.
+// Any authentication configured in perses_server is replaced by the token.
+func withUserToken(server PersesServerConfig, token string) PersesServerConfig {
+	server.NativeAuth = nil
+	server.OAuth = nil
+	server.BasicAuth = nil
+	server.K8sAuth = nil
+	server.RefreshToken = ""
+	server.RefreshTokenFile = ""
+	server.Authorization = &secret.Authorization{
+		Type:        "Bearer",
+		Credentials: token,
+	}
+	return server
+}
+
+// newUserSessionServer is called by the streamable HTTP handler for every new MCP session.
+// It returns a dedicated MCP server whose tools use the caller's Perses credentials.
+// Only the instances that set forward_user_token are available, so that the token is never sent to another backend.
+func (s *server) newUserSessionServer(req *http.Request) *mcp.Server {
+	instances, err := s.buildUserInstances(bearerToken(req))
+	if err != nil {
+		logrus.WithError(err).Error("unable to create the Perses clients for the MCP session")
+		return nil
+	}
+	mcpServer := newMCPServer()
+	s.registerTools(mcpServer, instances)
+	return mcpServer
+}
+
+// buildUserInstances creates the Perses clients of the instances that accept the token of the MCP caller.
+func (s *server) buildUserInstances(token string) (*instance.Registry, error) {
+	return s.buildInstances(func(cfg InstanceConfig) (PersesServerConfig, bool) {
+		return withUserToken(cfg.PersesServer, token), cfg.ForwardUserToken
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/perses/perses/pkg/client/config"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildUserInstances(t *testing.T) {
+	cfg := Config{Transport: HTTPTransport, ForwardUserToken: true, Instances: []InstanceConfig{
+		{Name: "production", ForwardUserToken: true},
+		{Name: "vendor"},
+	}}
+	require.NoError(t, cfg.Verify())
+	s := &server{cfg: cfg}
+
+	instances, err := s.buildUserInstances("user-token")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production"}, instances.Names())
+	assert.Equal(t, "production", instances.Primary().Name)
+}
+
+func TestWithUserToken(t *testing.T) {
+	server := PersesServerConfig{
+		RestConfigClient: config.RestConfigClient{
+			NativeAuth:    &modelAPI.Auth{Login: "admin", Password: "password"},
+			Authorization: &secret.Authorization{Type: "Bearer", Credentials: "service-token"},
+		},
+		RefreshToken: "refresh",
+	}
+	result := withUserToken(server, "user-token")
+	assert.Nil(t, result.NativeAuth)
+	assert.Empty(t, result.RefreshToken)
+	assert.Equal(t, &secret.Authorization{Type: "Bearer", Credentials: "user-token"}, result.Authorization)
+	// The configuration of the instance is left untouched.
+	assert.Equal(t, "service-token", server.Authorization.Credentials)
+}
+
+func TestBearerToken(t *testing.T) {
+	testSuite := []struct {
+		title  string
+		header string
+		token  string
+	}{
+		{title: "bearer token", header: "Bearer abc", token: "abc"},
+		{title: "case insensitive scheme", header: "bearer abc", token: "abc"},
+		{title: "basic auth", header: "Basic YWRtaW46cGFzc3dvcmQ="},
+		{title: "no header"},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			assert.Equal(t, test.token, bearerToken(req))
+		})
+	}
+}
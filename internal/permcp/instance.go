@@ -0,0 +1,140 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/sirupsen/logrus"
+
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/perses/mcp-server/pkg/tools/instance"
+)
+
+const instanceArgument = "instance"
+
+// buildInstances creates the Perses client of every configured instance.
+// serverConfig returns the configuration used to connect to the instance,
+// which allows to replace the configured credentials (e.g. by the token of the MCP caller).
+// The instances for which it returns false are left out.
+func (s *server) buildInstances(serverConfig func(cfg InstanceConfig) (PersesServerConfig, bool)) (*instance.Registry, error) {
+	instances := make([]*instance.Instance, 0, len(s.cfg.Instances))
+	for _, cfg := range s.cfg.Instances {
+		persesServer, ok := serverConfig(cfg)
+		if !ok {
+			continue
+		}
+		client, err := initializePersesClient(persesServer, s.cfg.Protected)
+		if err != nil {
+			return nil, fmt.Errorf("instance '%s': %w", cfg.Name, err)
+		}
+		instances = append(instances, &instance.Instance{
+			Name:      cfg.Name,
+			URL:       cfg.PersesServer.URL.String(),
+			ReadOnly:  cfg.ReadOnly,
+			Resources: cfg.AllowedResources,
+			Client:    client,
+		})
+	}
+	return instance.NewRegistry(instances, s.cfg.PrimaryInstance), nil
+}
+
+// addInstanceArgument adds the optional instance argument to the input schema of the tool.
+func addInstanceArgument(tool *mcp.Tool, allowedOn []string, primary string) {
+	schema, ok := tool.InputSchema.(*jsonschema.Schema)
+	if !ok || schema == nil {
+		// The tools without explicit schema don't take any other argument.
+		schema = &jsonschema.Schema{Type: "object"}
+		tool.InputSchema = schema
+	}
+	if schema.Properties == nil {
+		schema.Properties = make(map[string]*jsonschema.Schema)
+	}
+	enum := make([]any, 0, len(allowedOn))
+	for _, name := range allowedOn {
+		enum = append(enum, name)
+	}
+	schema.Properties[instanceArgument] = &jsonschema.Schema{
+		Type:        "string",
+		Description: fmt.Sprintf("Name of the Perses instance to use (see perses_list_instances). Defaults to '%s'.", primary),
+		Enum:        enum,
+	}
+}
+
+// instanceMiddleware routes each tool call to the Perses instance named in its instance argument,
+// after verifying that the tool is available on this instance.
+// toolInstances maps the name of each routed tool to the instances on which it can be called.
+func instanceMiddleware(instances *instance.Registry, toolInstances map[string][]string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			allowedOn, routed := toolInstances[params.Name]
+			if !routed {
+				return next(ctx, method, req)
+			}
+			target, err := getInstance(instances, params.Arguments)
+			if err != nil {
+				result := &mcp.CallToolResult{}
+				result.SetError(err)
+				return result, nil
+			}
+			if !slices.Contains(allowedOn, target.Name) {
+				result := &mcp.CallToolResult{}
+				result.SetError(fmt.Errorf("tool '%s' is not available on Perses instance '%s'", params.Name, target.Name))
+				return result, nil
+			}
+			logrus.WithFields(logrus.Fields{
+				"tool":     params.Name,
+				"instance": target.Name,
+			}).Debug("Routing tool call")
+			ctx = tools.WithClient(ctx, target.Client)
+			ctx = tools.WithResourceFilter(ctx, target.AllowsResource)
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func getInstance(instances *instance.Registry, arguments json.RawMessage) (*instance.Instance, error) {
+	var args map[string]any
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	var name string
+	if value, ok := args[instanceArgument]; ok && value != nil {
+		if name, ok = value.(string); !ok {
+			return nil, fmt.Errorf("the %s argument must be a string", instanceArgument)
+		}
+	}
+	return instances.Get(name)
+}
+
+// NewInstanceClient creates the Perses client of an instance.
+// It is used by the CLI subcommands, which talk to Perses without running the MCP server.
+func NewInstanceClient(cfg Config, instanceCfg InstanceConfig) (v1.ClientInterface, error) {
+	return initializePersesClient(instanceCfg.PersesServer, cfg.Protected)
+}
@@ -0,0 +1,424 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/protection"
+	"github.com/perses/perses/pkg/client/api/auth"
+	v1 "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/config"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	refreshTimeout = 30 * time.Second
+	// loginRetryInterval is how long a failed native login is reported before logging in again.
+	loginRetryInterval = 30 * time.Second
+)
+
+// PersesServerConfig is the configuration for connecting to the Perses backend server.
+type PersesServerConfig struct {
+	config.RestConfigClient `yaml:",inline"`
+	// RefreshToken is a Perses refresh token used to get a new access token
+	// when the bearer token configured in authorization expires.
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+	// RefreshTokenFile is a file containing the refresh token.
+	RefreshTokenFile string `yaml:"refresh_token_file,omitempty"`
+}
+
+func (c *PersesServerConfig) Validate() error {
+	if err := c.RestConfigClient.Validate(); err != nil {
+		return err
+	}
+	if len(c.RefreshToken) > 0 && len(c.RefreshTokenFile) > 0 {
+		return fmt.Errorf("at most one of refresh_token & refresh_token_file must be configured")
+	}
+	if (len(c.RefreshToken) > 0 || len(c.RefreshTokenFile) > 0) && c.Authorization == nil {
+		return fmt.Errorf("refresh_token can only be used with authorization")
+	}
+	return nil
+}
+
+// refreshTokenSource returns the function reading the refresh token. The file is read again when it changes.
+func (c *PersesServerConfig) refreshTokenSource() func() (string, error) {
+	if len(c.RefreshTokenFile) > 0 {
+		return watchSecretFile(c.RefreshTokenFile)
+	}
+	return func() (string, error) {
+		return c.RefreshToken, nil
+	}
+}
+
+// watchSecretFile returns the function reading the secret stored in the given file, loaded again when the file changes.
+func watchSecretFile(file string) func() (string, error) {
+	watcher := &fileWatcher[string]{
+		files: []string{file},
+		load: func() (*string, error) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			secret := strings.TrimSpace(string(data))
+			return &secret, nil
+		},
+	}
+	return func() (string, error) {
+		value, err := watcher.get()
+		if err != nil {
+			return "", err
+		}
+		return *value, nil
+	}
+}
+
+// withExpiry sets the expiry of a token returned by Perses, which only sends the access token, so that it is refreshed before it expires.
+// The expiry is taken from expires_in when present, or from the exp claim of the access token (a JWT).
+func withExpiry(token *oauth2.Token) *oauth2.Token {
+	if !token.Expiry.IsZero() {
+		return token
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+		return token
+	}
+	parts := strings.Split(token.AccessToken, ".")
+	if len(parts) != 3 {
+		return token
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return token
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+		token.Expiry = time.Unix(claims.Exp, 0)
+	}
+	return token
+}
+
+// initializePersesClient creates the client used to talk to Perses.
+// The authorization and the native authentication are managed by this package rather than by the Perses client,
+// so that the credentials are refreshed when they change or expire.
+func initializePersesClient(server PersesServerConfig, protected []protection.Rule) (v1.ClientInterface, error) {
+	restConfig := server.RestConfigClient
+	authorization := restConfig.Authorization
+	nativeAuth := restConfig.NativeAuth
+	restConfig.Authorization = nil
+	restConfig.NativeAuth = nil
+
+	restClient, err := config.NewRESTClient(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Perses REST client: %w", err)
+	}
+
+	base := restClient.Client.Transport
+	switch {
+	case nativeAuth != nil:
+		restClient.Client.Transport = newReloginTransport(restConfig.URL, base, *nativeAuth)
+	case authorization != nil:
+		refreshToken := server.refreshTokenSource()
+		if _, refreshErr := refreshToken(); refreshErr != nil {
+			return nil, fmt.Errorf("error reading the Perses refresh token: %w", refreshErr)
+		}
+		restClient.Client.Transport = newCredentialsTransport(restConfig.URL, base, authorization, refreshToken)
+	}
+
+	return protection.NewClient(v1.NewWithClient(restClient), protected), nil
+}
+
+// retryOnUnauthorized sends the request and, if Perses answers 401, calls renew and sends the request a second time
+// when renew reports that new credentials are available.
+func retryOnUnauthorized(req *http.Request, send func(*http.Request) (*http.Response, error), renew func() bool) (*http.Response, error) {
+	// The body is consumed by the first attempt, so it must be possible to get a new one to retry.
+	canRetry := req.Body == nil || req.GetBody != nil
+	resp, err := send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !canRetry || !renew() {
+		return resp, err
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	return send(retry)
+}
+
+// reloginTransport authenticates with the native login and password.
+// The access token is refreshed before it expires, and the MCP server logs in again when Perses rejects the token
+// (e.g. after a restart of Perses). After a failed login, the error is returned without logging in again
+// for loginRetryInterval, so that a wrong password doesn't send a login request for every call.
+type reloginTransport struct {
+	base       http.RoundTripper
+	authClient auth.Interface
+	nativeAuth modelAPI.Auth
+	mutex      sync.Mutex
+	token      *oauth2.Token
+	// loginErr is the error of the last login, if it failed at loginFailedAt.
+	loginErr      error
+	loginFailedAt time.Time
+}
+
+func newReloginTransport(url *common.URL, base http.RoundTripper, nativeAuth modelAPI.Auth) *reloginTransport {
+	return &reloginTransport{
+		base:       base,
+		nativeAuth: nativeAuth,
+		authClient: auth.New(&perseshttp.RESTClient{
+			BaseURL: url,
+			Client:  &http.Client{Transport: base, Timeout: refreshTimeout},
+		}),
+	}
+}
+
+func (t *reloginTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.getToken()
+	if err != nil {
+		return nil, err
+	}
+	send := func(r *http.Request) (*http.Response, error) {
+		authorized := r.Clone(r.Context())
+		token.SetAuthHeader(authorized)
+		return t.base.RoundTrip(authorized)
+	}
+	return retryOnUnauthorized(req, send, func() bool {
+		renewed, renewErr := t.renew(token)
+		if renewErr != nil {
+			logrus.WithError(renewErr).Warn("unable to renew the Perses credentials")
+			return false
+		}
+		token = renewed
+		return true
+	})
+}
+
+func (t *reloginTransport) getToken() (*oauth2.Token, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.token != nil && t.token.Valid() {
+		return t.token, nil
+	}
+	if t.token != nil && t.token.RefreshToken != "" {
+		token, err := t.authClient.Refresh(t.token.RefreshToken)
+		if err == nil {
+			if token.RefreshToken == "" {
+				token.RefreshToken = t.token.RefreshToken
+			}
+			t.token = withExpiry(token)
+			return t.token, nil
+		}
+		logrus.WithError(err).Info("unable to refresh the Perses access token, logging in again")
+	}
+	return t.login()
+}
+
+// renew returns a new access token after Perses rejected the given one.
+func (t *reloginTransport) renew(rejected *oauth2.Token) (*oauth2.Token, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// Another request may already have logged in again.
+	if t.token != nil && t.token.AccessToken != rejected.AccessToken {
+		return t.token, nil
+	}
+	logrus.Info("Perses rejected the token, logging in again")
+	return t.login()
+}
+
+// login must be called with the mutex held.
+func (t *reloginTransport) login() (*oauth2.Token, error) {
+	if t.loginErr != nil && time.Since(t.loginFailedAt) < loginRetryInterval {
+		return nil, t.loginErr
+	}
+	token, err := t.authClient.Login(t.nativeAuth.Login, t.nativeAuth.Password)
+	if err != nil {
+		t.token = nil
+		t.loginErr = fmt.Errorf("unable to log in to Perses: %w", err)
+		t.loginFailedAt = time.Now()
+		return nil, t.loginErr
+	}
+	t.loginErr = nil
+	t.token = withExpiry(token)
+	return t.token, nil
+}
+
+// credentialsTransport sets the configured authorization on every request.
+// The credentials file is read again when it changes, and when a refresh token is available,
+// it is used to get a new access token once the current one is expired or rejected.
+type credentialsTransport struct {
+	base        http.RoundTripper
+	authType    string
+	credentials func() (string, error)
+	// refreshTokens reads the refresh token of the configuration.
+	refreshTokens func() (string, error)
+	authClient    auth.Interface
+	mutex         sync.Mutex
+	// lastCredentials are the last credentials read from the configuration.
+	lastCredentials string
+	// lastRefreshToken is the last refresh token read from the configuration.
+	lastRefreshToken string
+	// refreshToken is the refresh token to use: the configured one, or the one returned by the last refresh.
+	refreshToken string
+	// token is the access token obtained with the refresh token.
+	token *oauth2.Token
+}
+
+func newCredentialsTransport(url *common.URL, base http.RoundTripper, authorization *secret.Authorization, refreshTokens func() (string, error)) *credentialsTransport {
+	authType := authorization.Type
+	if authType == "" {
+		authType = "Bearer"
+	}
+	credentials := func() (string, error) {
+		return authorization.Credentials, nil
+	}
+	if len(authorization.CredentialsFile) > 0 {
+		credentials = watchSecretFile(authorization.CredentialsFile)
+	}
+	return &credentialsTransport{
+		base:          base,
+		authType:      authType,
+		credentials:   credentials,
+		refreshTokens: refreshTokens,
+		authClient: auth.New(&perseshttp.RESTClient{
+			BaseURL: url,
+			Client:  &http.Client{Transport: base, Timeout: refreshTimeout},
+		}),
+	}
+}
+
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	accessToken, err := t.getAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	send := func(r *http.Request) (*http.Response, error) {
+		authorized := r.Clone(r.Context())
+		authorized.Header.Set("Authorization", fmt.Sprintf("%s %s", t.authType, accessToken))
+		return t.base.RoundTrip(authorized)
+	}
+	return retryOnUnauthorized(req, send, func() bool {
+		renewed, renewErr := t.renew(accessToken)
+		if renewErr != nil {
+			logrus.WithError(renewErr).Warn("unable to renew the Perses credentials")
+			return false
+		}
+		accessToken = renewed
+		return true
+	})
+}
+
+func (t *credentialsTransport) getAccessToken() (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	credentials, err := t.credentials()
+	if err != nil {
+		return "", fmt.Errorf("unable to read the Perses credentials: %w", err)
+	}
+	if credentials != t.lastCredentials {
+		// New credentials have been provided, they take precedence over the refreshed token.
+		t.lastCredentials = credentials
+		t.token = nil
+	}
+	if t.token != nil {
+		if t.token.Valid() {
+			return t.token.AccessToken, nil
+		}
+		if err := t.refresh(); err != nil {
+			return "", err
+		}
+		return t.token.AccessToken, nil
+	}
+	if credentials == "" && t.currentRefreshToken() != "" {
+		if err := t.refresh(); err != nil {
+			return "", err
+		}
+		return t.token.AccessToken, nil
+	}
+	return credentials, nil
+}
+
+// renew returns a new access token after Perses rejected the given one.
+func (t *credentialsTransport) renew(rejected string) (string, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if credentials, err := t.credentials(); err == nil && credentials != t.lastCredentials {
+		t.lastCredentials = credentials
+		t.token = nil
+	}
+	current := t.lastCredentials
+	if t.token != nil {
+		current = t.token.AccessToken
+	}
+	// The credentials file has changed, or another request already renewed the token.
+	if current != rejected && current != "" {
+		return current, nil
+	}
+	if t.currentRefreshToken() == "" {
+		return "", fmt.Errorf("the access token has been rejected and no refresh token is configured")
+	}
+	if err := t.refresh(); err != nil {
+		return "", err
+	}
+	return t.token.AccessToken, nil
+}
+
+// currentRefreshToken returns the refresh token to use. A new refresh token in the configuration (e.g. a rotated refresh_token_file)
+// replaces the one returned by the previous refresh. It must be called with the mutex held.
+func (t *credentialsTransport) currentRefreshToken() string {
+	configured, err := t.refreshTokens()
+	if err != nil {
+		logrus.WithError(err).Warn("unable to read the Perses refresh token")
+	} else if configured != t.lastRefreshToken {
+		t.lastRefreshToken = configured
+		t.refreshToken = configured
+	}
+	return t.refreshToken
+}
+
+// refresh must be called with the mutex held.
+func (t *credentialsTransport) refresh() error {
+	refreshToken := t.currentRefreshToken()
+	if refreshToken == "" {
+		return fmt.Errorf("the access token is expired and no refresh token is configured")
+	}
+	token, err := t.authClient.Refresh(refreshToken)
+	if err != nil {
+		return fmt.Errorf("unable to refresh the Perses access token: %w", err)
+	}
+	t.token = withExpiry(token)
+	if token.RefreshToken != "" {
+		t.refreshToken = token.RefreshToken
+	}
+	logrus.Info("Perses access token refreshed")
+	return nil
+}
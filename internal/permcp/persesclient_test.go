@@ -0,0 +1,279 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// fakeJWT returns an unsigned JWT expiring at the given time, enough for the MCP server that never verifies the Perses tokens.
+func fakeJWT(id int, exp time.Time) string {
+	encode := func(v any) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	return encode(map[string]any{"alg": "none"}) + "." + encode(map[string]any{"sub": "admin", "jti": id, "exp": exp.Unix()}) + ".sig"
+}
+
+// fakePerses implements the authentication endpoints of Perses and a projects endpoint accepting the tokens it issued.
+type fakePerses struct {
+	*httptest.Server
+	mutex          sync.Mutex
+	password       string
+	refreshTokens  map[string]bool
+	accessTokens   map[string]bool
+	issued         int
+	logins         int
+	refreshes      int
+	accessTokenTTL time.Duration
+}
+
+func newFakePerses(t *testing.T) *fakePerses {
+	p := &fakePerses{
+		password:       "password",
+		refreshTokens:  map[string]bool{},
+		accessTokens:   map[string]bool{},
+		accessTokenTTL: time.Hour,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/auth/providers/native/login", func(w http.ResponseWriter, r *http.Request) {
+		var body modelAPI.Auth
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.logins++
+		if body.Password != p.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"wrong password"}`))
+			return
+		}
+		p.writeToken(w, fmt.Sprintf("refresh-%d", p.logins))
+	})
+	mux.HandleFunc("POST /api/auth/refresh", func(w http.ResponseWriter, r *http.Request) {
+		var body modelAPI.RefreshRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		p.refreshes++
+		if !p.refreshTokens[body.RefreshToken] {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid refresh token"}`))
+			return
+		}
+		p.writeToken(w, body.RefreshToken)
+	})
+	mux.HandleFunc("GET /api/v1/projects", func(w http.ResponseWriter, r *http.Request) {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if !p.accessTokens[bearerToken(r)] {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"unauthorized"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// writeToken must be called with the mutex held.
+func (p *fakePerses) writeToken(w http.ResponseWriter, refreshToken string) {
+	p.issued++
+	accessToken := fakeJWT(p.issued, time.Now().Add(p.accessTokenTTL))
+	p.accessTokens[accessToken] = true
+	p.refreshTokens[refreshToken] = true
+	// Like Perses, only the tokens are returned, without their expiry.
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": accessToken, "refresh_token": refreshToken, "token_type": "Bearer"})
+}
+
+// restart forgets the access tokens, like a restart of Perses with a new signing key would.
+func (p *fakePerses) restart() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.accessTokens = map[string]bool{}
+}
+
+func (p *fakePerses) counts() (int, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.logins, p.refreshes
+}
+
+func (p *fakePerses) url() *common.URL {
+	return common.MustParseURL(p.URL)
+}
+
+func listProjects(t *testing.T, rt http.RoundTripper, url string) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, url+"/api/v1/projects", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func TestReloginTransport(t *testing.T) {
+	perses := newFakePerses(t)
+	rt := newReloginTransport(perses.url(), http.DefaultTransport, modelAPI.Auth{Login: "admin", Password: "password"})
+
+	status, err := listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	status, err = listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	logins, _ := perses.counts()
+	assert.Equal(t, 1, logins, "the token is reused")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rt.token.Expiry, time.Minute, "the expiry is read from the access token")
+
+	perses.restart()
+	status, err = listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	logins, _ = perses.counts()
+	assert.Equal(t, 2, logins, "the transport logs in again when the token is rejected")
+}
+
+func TestReloginTransportRefreshesExpiredToken(t *testing.T) {
+	perses := newFakePerses(t)
+	// The tokens expire within the expiry delta of oauth2, so they are refreshed on the next call.
+	perses.accessTokenTTL = time.Second
+	rt := newReloginTransport(perses.url(), http.DefaultTransport, modelAPI.Auth{Login: "admin", Password: "password"})
+
+	for range 2 {
+		status, err := listProjects(t, rt, perses.URL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	}
+	logins, refreshes := perses.counts()
+	assert.Equal(t, 1, logins)
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestReloginTransportStopsAfterFailedLogin(t *testing.T) {
+	perses := newFakePerses(t)
+	rt := newReloginTransport(perses.url(), http.DefaultTransport, modelAPI.Auth{Login: "admin", Password: "wrong"})
+
+	for range 5 {
+		_, err := listProjects(t, rt, perses.URL)
+		assert.ErrorContains(t, err, "unable to log in to Perses")
+	}
+	logins, _ := perses.counts()
+	assert.Equal(t, 1, logins, "no login is attempted until loginRetryInterval is over")
+
+	rt.loginFailedAt = time.Now().Add(-loginRetryInterval)
+	rt.nativeAuth.Password = "password"
+	status, err := listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestReloginTransportFailedRenewal(t *testing.T) {
+	perses := newFakePerses(t)
+	rt := newReloginTransport(perses.url(), http.DefaultTransport, modelAPI.Auth{Login: "admin", Password: "password"})
+	_, err := listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+
+	// The password is changed in Perses, then the tokens are revoked.
+	perses.mutex.Lock()
+	perses.password = "new-password"
+	perses.mutex.Unlock()
+	perses.restart()
+	for range 5 {
+		status, err := listProjects(t, rt, perses.URL)
+		if err == nil {
+			assert.Equal(t, http.StatusUnauthorized, status, "the rejection of Perses is returned when the login fails")
+		}
+	}
+	logins, _ := perses.counts()
+	assert.Equal(t, 2, logins, "a single login is attempted after the token is rejected")
+}
+
+func TestCredentialsTransportRefreshToken(t *testing.T) {
+	perses := newFakePerses(t)
+	configured := "unknown"
+	rt := newCredentialsTransport(perses.url(), http.DefaultTransport,
+		&secret.Authorization{Type: "Bearer", Credentials: "expired"},
+		func() (string, error) { return configured, nil })
+
+	status, err := listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, status, "the configured refresh token is rejected by Perses")
+
+	// A new refresh token is written in refresh_token_file.
+	perses.mutex.Lock()
+	perses.refreshTokens["rotated"] = true
+	perses.mutex.Unlock()
+	configured = "rotated"
+	status, err = listProjects(t, rt, perses.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, rt.token.Expiry.IsZero(), "the expiry is read from the access token")
+}
+
+func TestWithExpiry(t *testing.T) {
+	exp := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	testSuite := []struct {
+		title  string
+		token  *oauth2.Token
+		expiry time.Time
+	}{
+		{
+			title:  "expiry from the access token",
+			token:  &oauth2.Token{AccessToken: fakeJWT(1, exp)},
+			expiry: exp,
+		},
+		{
+			title:  "expiry from expires_in",
+			token:  &oauth2.Token{AccessToken: "opaque", ExpiresIn: 600},
+			expiry: time.Now().Add(10 * time.Minute),
+		},
+		{
+			title:  "expiry already set",
+			token:  &oauth2.Token{AccessToken: fakeJWT(1, exp), Expiry: exp.Add(time.Hour)},
+			expiry: exp.Add(time.Hour),
+		},
+		{
+			title: "opaque token without expiry",
+			token: &oauth2.Token{AccessToken: "opaque"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			expiry := withExpiry(test.token).Expiry
+			if test.expiry.IsZero() {
+				assert.True(t, expiry.IsZero())
+				return
+			}
+			assert.WithinDuration(t, test.expiry, expiry, time.Second)
+		})
+	}
+}
@@ -15,24 +15,26 @@ package permcp
 
 import (
 	"context"
-	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/perses/common/async"
-	"github.com/perses/common/set"
-	v1 "github.com/perses/perses/pkg/client/api/v1"
-	"github.com/perses/perses/pkg/client/config"
 	"github.com/sirupsen/logrus"
 
+	"github.com/perses/mcp-server/pkg/lint"
+	"github.com/perses/mcp-server/pkg/templates"
 	"github.com/perses/mcp-server/pkg/tools"
 	"github.com/perses/mcp-server/pkg/tools/dashboard"
 	"github.com/perses/mcp-server/pkg/tools/datasource"
+	"github.com/perses/mcp-server/pkg/tools/ephemeraldashboard"
 	"github.com/perses/mcp-server/pkg/tools/globaldatasource"
 	"github.com/perses/mcp-server/pkg/tools/globalrole"
 	"github.com/perses/mcp-server/pkg/tools/globalrolebinding"
 	"github.com/perses/mcp-server/pkg/tools/globalvariable"
+	"github.com/perses/mcp-server/pkg/tools/instance"
+	"github.com/perses/mcp-server/pkg/tools/manifest"
 	"github.com/perses/mcp-server/pkg/tools/plugin"
 	"github.com/perses/mcp-server/pkg/tools/project"
 	"github.com/perses/mcp-server/pkg/tools/resource"
@@ -42,14 +44,30 @@ import (
 )
 
 func New(cfg Config) (async.SimpleTask, error) {
-	persesClient, err := initializePersesClient(cfg)
+	s := &server{
+		cfg:       cfg,
+		mcpServer: newMCPServer(),
+	}
+	instances, err := s.buildInstances(func(cfg InstanceConfig) (PersesServerConfig, bool) {
+		return cfg.PersesServer, true
+	})
 	if err != nil {
 		return nil, err
 	}
+	for _, i := range instances.List() {
+		logrus.WithFields(logrus.Fields{
+			"instance":  i.Name,
+			"url":       i.URL,
+			"read_only": i.ReadOnly,
+			"primary":   i.Primary,
+		}).Info("Perses client initialized")
+	}
+	s.instances = instances
+	return s, nil
+}
 
-	logrus.WithField("url", cfg.PersesServer.URL).Info("Perses client initialized")
-
-	mcpServer := mcp.NewServer(&mcp.Implementation{
+func newMCPServer() *mcp.Server {
+	return mcp.NewServer(&mcp.Implementation{
 		Name:  "perses-mcp-server",
 		Title: "Perses MCP Server"},
 		&mcp.ServerOptions{
@@ -57,40 +75,32 @@ func New(cfg Config) (async.SimpleTask, error) {
 			HasResources: false,
 			HasPrompts:   false,
 		})
-
-	return &server{
-		cfg:          cfg,
-		persesClient: persesClient,
-		mcpServer:    mcpServer,
-	}, nil
-}
-
-func initializePersesClient(cfg Config) (v1.ClientInterface, error) {
-	restClient, err := config.NewRESTClient(cfg.PersesServer)
-	if err != nil {
-		return nil, fmt.Errorf("error creating Perses REST client: %w", err)
-	}
-
-	return v1.NewWithClient(restClient), nil
 }
 
 type server struct {
 	async.SimpleTask
 	// cfg contains the server configuration settings
 	cfg Config
-	// persesClient is the client interface for interacting with the Perses API
-	persesClient v1.ClientInterface
-	// mcpServer is the Model Context Protocol server instance
+	// instances holds the Perses instances and the clients for interacting with their API
+	instances *instance.Registry
+	// mcpServer is the Model Context Protocol server instance.
+	// It is not used when the caller's token is forwarded, as each session then gets its own server.
 	mcpServer *mcp.Server
 }
 
 func (s *server) Execute(ctx context.Context, cancelFunc context.CancelFunc) error {
 	logrus.WithFields(logrus.Fields{
-		"read_only": s.cfg.ReadOnly,
-		"transport": s.cfg.Transport,
+		"instances":          len(s.cfg.Instances),
+		"protected":          len(s.cfg.Protected),
+		"transport":          s.cfg.Transport,
+		"forward_user_token": s.cfg.ForwardUserToken,
+		"oauth":              s.cfg.OAuth != nil,
+		"tls":                s.cfg.TLSConfig != nil,
 	}).Info("Starting Perses MCP Server")
 
-	s.registerTools()
+	if !s.cfg.ForwardUserToken {
+		s.registerTools(s.mcpServer, s.instances)
+	}
 	// start server
 	serverCtx, serverCancelFunc := context.WithCancel(ctx)
 	go func() {
@@ -125,19 +135,24 @@ func (s *server) Execute(ctx context.Context, cancelFunc context.CancelFunc) err
 	return nil
 }
 
-func (s *server) registerTools() {
+func (s *server) registerTools(mcpServer *mcp.Server, instances *instance.Registry) {
+	// The tools are bound to the primary instance. The calls targeting another instance
+	// are routed by instanceMiddleware.
+	persesClient := instances.Primary().Client
 	resources := []resource.Resource{
-		project.New(s.persesClient),
-		dashboard.New(s.persesClient),
-		datasource.New(s.persesClient),
-		globaldatasource.New(s.persesClient),
-		role.New(s.persesClient),
-		globalrole.New(s.persesClient),
-		rolebinding.New(s.persesClient),
-		globalrolebinding.New(s.persesClient),
-		variable.New(s.persesClient),
-		globalvariable.New(s.persesClient),
-		plugin.New(s.persesClient),
+		project.New(persesClient, s.cfg.SnapshotsDirectory),
+		dashboard.New(persesClient, lint.New(s.cfg.Lint), templates.New(s.cfg.TemplatesDirectory), s.cfg.SnapshotsDirectory),
+		ephemeraldashboard.New(persesClient),
+		datasource.New(persesClient),
+		globaldatasource.New(persesClient),
+		role.New(persesClient),
+		globalrole.New(persesClient),
+		rolebinding.New(persesClient),
+		globalrolebinding.New(persesClient),
+		variable.New(persesClient),
+		globalvariable.New(persesClient),
+		plugin.New(persesClient),
+		manifest.New(persesClient, s.cfg.ManifestsDirectory),
 	}
 
 	var allTools []*tools.Tool
@@ -145,17 +160,27 @@ func (s *server) registerTools() {
 		allTools = append(allTools, r.GetTools()...)
 	}
 
-	// Build allowed resources set for filtering
-	allowedResources := set.New(s.cfg.AllowedResources...)
-	filterByResource := len(s.cfg.AllowedResources) > 0
-
-	registeredCount := 0
+	multiInstance := len(instances.List()) > 1
+	requiredScopes := make(map[string][]string)
+	toolInstances := make(map[string][]string)
+	var registered []*tools.Tool
 	skippedReadOnly := 0
 	skippedResource := 0
 
 	for _, tool := range allTools {
-		// Skip tools not in allowed resources (if filtering is enabled)
-		if filterByResource && !allowedResources.Contains(string(tool.ResourceType)) {
+		var allowedOn []string
+		resourceAllowed := false
+		for _, i := range instances.List() {
+			if i.AllowsResource(tool.ResourceType) {
+				resourceAllowed = true
+			}
+			if i.Allows(tool) {
+				allowedOn = append(allowedOn, i.Name)
+			}
+		}
+
+		// Skip tools not in allowed resources of any instance (if filtering is enabled)
+		if !resourceAllowed {
 			logrus.WithFields(logrus.Fields{
 				"tool":         tool.MCPTool.Name,
 				"resourceType": tool.ResourceType,
@@ -164,19 +189,45 @@ func (s *server) registerTools() {
 			continue
 		}
 
-		// Skip write tools in read-only mode
-		if s.cfg.ReadOnly && tool.IsWriteTool {
+		// Skip write tools when every instance is read-only
+		if len(allowedOn) == 0 {
 			logrus.WithField("tool", tool.MCPTool.Name).Debug("Skipping write tool in read-only mode")
 			skippedReadOnly++
 			continue
 		}
 
-		tool.RegisterWith(s.mcpServer)
-		registeredCount++
+		if multiInstance {
+			addInstanceArgument(tool.MCPTool, allowedOn, instances.Primary().Name)
+		}
+		toolInstances[tool.MCPTool.Name] = allowedOn
+		registered = append(registered, tool)
+	}
+
+	// The instance tools are not bound to any instance, so only the read-only mode applies to them:
+	// a write tool like perses_promote is registered when at least one instance can be written.
+	writable := slices.ContainsFunc(instances.List(), func(i *instance.Instance) bool { return !i.ReadOnly })
+	for _, tool := range instance.New(instances).GetTools() {
+		if tool.IsWriteTool && !writable {
+			logrus.WithField("tool", tool.MCPTool.Name).Debug("Skipping write tool in read-only mode")
+			skippedReadOnly++
+			continue
+		}
+		registered = append(registered, tool)
+	}
+	for _, tool := range registered {
+		tool.RegisterWith(mcpServer)
+		if s.cfg.OAuth != nil {
+			requiredScopes[tool.MCPTool.Name] = s.cfg.OAuth.RequiredScopes(tool.MCPTool.Name, tool.IsWriteTool)
+		}
+	}
+
+	mcpServer.AddReceivingMiddleware(instanceMiddleware(instances, toolInstances))
+	if s.cfg.OAuth != nil {
+		mcpServer.AddReceivingMiddleware(scopeMiddleware(requiredScopes))
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"registered":       registeredCount,
+		"registered":       len(registered),
 		"skipped_readonly": skippedReadOnly,
 		"skipped_resource": skippedResource,
 		"total":            len(allTools),
@@ -189,9 +240,10 @@ func (s *server) runStdioTransport(ctx context.Context) error {
 }
 
 func (s *server) runHTTPTransport() error {
-	handler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
-		return s.mcpServer
-	}, nil)
+	handler, err := s.buildHTTPHandler()
+	if err != nil {
+		return err
+	}
 
 	httpServer := &http.Server{
 		Addr:              s.cfg.ListenAddress,
@@ -199,7 +251,17 @@ func (s *server) runHTTPTransport() error {
 		ReadHeaderTimeout: 10 * time.Second,
 		Handler:           handler,
 	}
-	return httpServer.ListenAndServe()
+	if s.cfg.TLSConfig == nil {
+		return httpServer.ListenAndServe()
+	}
+
+	tlsConfig, err := buildServerTLSConfig(*s.cfg.TLSConfig)
+	if err != nil {
+		return err
+	}
+	httpServer.TLSConfig = tlsConfig
+	// The certificate is provided by the TLS configuration, so no file is given here.
+	return httpServer.ListenAndServeTLS("", "")
 }
 
 func (s *server) String() string {
@@ -0,0 +1,88 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools/instance"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registeredTools returns the names of the tools registered for the given read-only modes of two instances.
+func registeredTools(t *testing.T, stagingReadOnly bool, productionReadOnly bool) []string {
+	client := apiClient.NewWithClient(&perseshttp.RESTClient{BaseURL: common.MustParseURL("http://localhost:8080")})
+	instances := instance.NewRegistry([]*instance.Instance{
+		{Name: "staging", ReadOnly: stagingReadOnly, Client: client},
+		{Name: "production", ReadOnly: productionReadOnly, Client: client},
+	}, "staging")
+	s := &server{mcpServer: newMCPServer()}
+	s.registerTools(s.mcpServer, instances)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	var names []string
+	for tool, err := range session.Tools(ctx, nil) {
+		require.NoError(t, err)
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+func TestRegisterToolsReadOnly(t *testing.T) {
+	testSuite := []struct {
+		title              string
+		stagingReadOnly    bool
+		productionReadOnly bool
+		promote            bool
+	}{
+		{
+			title:   "every instance writable",
+			promote: true,
+		},
+		{
+			title:           "one instance writable",
+			stagingReadOnly: true,
+			promote:         true,
+		},
+		{
+			title:              "every instance read-only",
+			stagingReadOnly:    true,
+			productionReadOnly: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			names := registeredTools(t, test.stagingReadOnly, test.productionReadOnly)
+			assert.Contains(t, names, "perses_list_instances")
+			assert.Contains(t, names, "perses_list_projects")
+			assert.Equal(t, test.promote, slices.Contains(names, "perses_promote"))
+			assert.Equal(t, test.promote, slices.Contains(names, "perses_create_project"))
+		})
+	}
+}
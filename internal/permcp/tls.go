@@ -0,0 +1,114 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"TLS10": tls.VersionTLS10,
+	"TLS11": tls.VersionTLS11,
+	"TLS12": tls.VersionTLS12,
+	"TLS13": tls.VersionTLS13,
+}
+
+// TLSConfig configures TLS for the HTTP listener.
+// The certificate, the key and the client CA are reloaded automatically when the files change.
+type TLSConfig struct {
+	// CertFile is the path to the PEM encoded certificate (chain) served by the listener.
+	CertFile string `yaml:"cert_file"`
+	// KeyFile is the path to the PEM encoded private key of the certificate.
+	KeyFile string `yaml:"key_file"`
+	// ClientCAFile is the path to the PEM encoded CA used to verify client certificates.
+	// When set, clients must present a valid certificate (mTLS).
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+	// MinVersion is the minimum TLS version accepted (TLS10, TLS11, TLS12 or TLS13). Defaults to TLS12.
+	MinVersion string `yaml:"min_version,omitempty"`
+}
+
+func (c *TLSConfig) Verify() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("cert_file and key_file must be configured")
+	}
+	if c.MinVersion == "" {
+		c.MinVersion = "TLS12"
+	}
+	c.MinVersion = strings.ToUpper(strings.TrimSpace(c.MinVersion))
+	if _, ok := tlsVersions[c.MinVersion]; !ok {
+		return fmt.Errorf("unsupported min_version %q. valid values are: TLS10, TLS11, TLS12, TLS13", c.MinVersion)
+	}
+	return nil
+}
+
+// buildServerTLSConfig returns the TLS configuration of the HTTP listener.
+// The files are loaded once to fail fast on an invalid configuration.
+func buildServerTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	certs := &fileWatcher[tls.Certificate]{
+		files: []string{cfg.CertFile, cfg.KeyFile},
+		load: func() (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+			return &cert, err
+		},
+	}
+	if _, err := certs.get(); err != nil {
+		return nil, fmt.Errorf("unable to load the TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tlsVersions[cfg.MinVersion],
+		// http.Server only adds the protocols to its own copy of the configuration, which GetConfigForClient doesn't see.
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return certs.get()
+		},
+	}
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	clientCAs := &fileWatcher[x509.CertPool]{
+		files: []string{cfg.ClientCAFile},
+		load: func() (*x509.CertPool, error) {
+			data, err := os.ReadFile(cfg.ClientCAFile)
+			if err != nil {
+				return nil, err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no certificate found in %s", cfg.ClientCAFile)
+			}
+			return pool, nil
+		},
+	}
+	if _, err := clientCAs.get(); err != nil {
+		return nil, fmt.Errorf("unable to load the TLS client CA: %w", err)
+	}
+	tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		pool, err := clientCAs.get()
+		if err != nil {
+			return nil, err
+		}
+		// The clone keeps NextProtos, so that HTTP/2 is still negotiated with the clients.
+		clientConfig := tlsConfig.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = pool
+		clientConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		return clientConfig, nil
+	}
+	return tlsConfig, nil
+}
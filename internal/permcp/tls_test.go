@@ -0,0 +1,145 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package permcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert returns a certificate signed by parent, or a self-signed CA when parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) writeFiles(t *testing.T, dir string, name string) (string, string) {
+	certFile, keyFile := filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestServerTLSConfigMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := ca.writeFiles(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "server", ca).writeFiles(t, dir, "server")
+	cfg := TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}
+	require.NoError(t, cfg.Verify())
+	tlsConfig, err := buildServerTLSConfig(cfg)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	srv.EnableHTTP2 = true
+	srv.TLS = tlsConfig
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{
+			ForceAttemptHTTP2: true,
+			TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certs},
+		}}
+	}
+
+	resp, err := newClient(newTestCert(t, "client", ca).tlsCertificate()).Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "HTTP/2.0", resp.Proto, "h2 must still be negotiated with the per-client configuration")
+
+	_, err = newClient().Get(srv.URL)
+	assert.Error(t, err, "a client without certificate must be rejected")
+
+	_, err = newClient(newTestCert(t, "intruder", nil).tlsCertificate()).Get(srv.URL)
+	assert.Error(t, err, "a client certificate of another CA must be rejected")
+}
+
+func TestTLSConfigVerify(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		cfg     TLSConfig
+		wantErr bool
+	}{
+		{title: "default min version", cfg: TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}},
+		{title: "missing key", cfg: TLSConfig{CertFile: "cert.pem"}, wantErr: true},
+		{title: "lowercase min version", cfg: TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "tls13"}},
+		{title: "unknown min version", cfg: TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", MinVersion: "SSL3"}, wantErr: true},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.cfg.Verify()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+)
+
+var cueIdentifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// cueKeywords are the identifiers that must be quoted to be used as labels.
+var cueKeywords = []string{"null", "true", "false", "if", "for", "in", "let", "import", "package", "div", "mod", "quo", "rem"}
+
+type cueWriter struct {
+	body   strings.Builder
+	indent int
+}
+
+// line writes a line at the current indentation. A line ending with an opening bracket indents the next ones,
+// a line starting with a closing bracket is written one level back.
+func (w *cueWriter) line(format string, args ...any) {
+	text := fmt.Sprintf(format, args...)
+	if strings.HasPrefix(text, "}") || strings.HasPrefix(text, "]") {
+		w.indent--
+	}
+	w.body.WriteString(strings.Repeat("\t", w.indent) + text + "\n")
+	if strings.HasSuffix(text, "{") || strings.HasSuffix(text, "[") {
+		w.indent++
+	}
+}
+
+// field writes a field with a value decoded from JSON.
+func (w *cueWriter) field(name string, value any) {
+	w.line("%s: %s", cueLabel(name), cueValue(value, w.indent))
+}
+
+// cueProgram returns a CUE file building the dashboard with the builders of the CUE SDK.
+func (c *converter) cueProgram() string {
+	spec := c.dashboard.Spec
+	w := &cueWriter{}
+	w.line("// Dashboard-as-Code of the dashboard %s.", dashboardReference(c.dashboard))
+	w.line(`// Build it with "percli dac build".`)
+	w.line("package dac")
+	w.line("")
+	w.line("import (")
+	w.indent++
+	w.line(`dashboardBuilder "github.com/perses/perses/cue/dac-utils/dashboard"`)
+	w.line(`panelGroupsBuilder "github.com/perses/perses/cue/dac-utils/panelgroups"`)
+	if hasVariable(spec.Variables, variable.KindList) {
+		w.line(`listVarBuilder "github.com/perses/perses/cue/dac-utils/variable/list"`)
+	}
+	if hasVariable(spec.Variables, variable.KindText) {
+		w.line(`textVarBuilder "github.com/perses/perses/cue/dac-utils/variable/text"`)
+	}
+	w.indent--
+	w.line(")")
+	w.line("")
+	w.line("dashboardBuilder & {")
+	w.field("#name", c.dashboard.Metadata.Name)
+	if c.dashboard.Metadata.Project != "" {
+		w.field("#project", c.dashboard.Metadata.Project)
+	}
+	if spec.Display != nil {
+		w.field("#display", toJSON(spec.Display))
+	}
+	if spec.Duration != "" {
+		w.field("#duration", string(spec.Duration))
+	}
+	if spec.RefreshInterval != "" {
+		w.field("#refreshInterval", string(spec.RefreshInterval))
+	}
+	if len(spec.Datasources) > 0 {
+		w.field("#datasources", toJSON(spec.Datasources))
+	}
+	if len(spec.Variables) > 0 {
+		w.line("#variables: [")
+		for _, v := range spec.Variables {
+			c.cueVariable(w, v)
+		}
+		w.line("]")
+	}
+	w.line("#panelGroups: panelGroupsBuilder & {")
+	w.line("#input: [")
+	for _, group := range c.groups {
+		c.cuePanelGroup(w, group)
+	}
+	w.line("]")
+	w.line("}")
+	if len(spec.Links) > 0 {
+		// the dashboard builder has no parameter for the links, they are set on its output.
+		w.field("spec", map[string]any{"links": toJSON(spec.Links)})
+	}
+	w.line("}")
+	return w.body.String()
+}
+
+func hasVariable(variables []dashboard.Variable, kind variable.Kind) bool {
+	for _, v := range variables {
+		if v.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *converter) cueVariable(w *cueWriter, v dashboard.Variable) {
+	switch spec := v.Spec.(type) {
+	case *dashboard.ListVariableSpec:
+		w.line("{listVarBuilder & {")
+		w.field("#name", spec.Name)
+		if spec.Display != nil {
+			w.field("#display", toJSON(spec.Display))
+		}
+		if spec.AllowAllValue {
+			w.field("#allowAllValue", true)
+		}
+		if spec.AllowMultiple {
+			w.field("#allowMultiple", true)
+		}
+		if spec.CustomAllValue != "" {
+			w.field("#customAllValue", spec.CustomAllValue)
+		}
+		if spec.CapturingRegexp != "" {
+			w.field("#capturingRegexp", spec.CapturingRegexp)
+		}
+		if spec.Sort != nil {
+			w.field("#sort", string(*spec.Sort))
+		}
+		w.field("#pluginKind", spec.Plugin.Kind)
+		// the builder has no parameter for the spec of the plugin and the default value, they are set on its output.
+		extra := map[string]any{"plugin": map[string]any{"spec": toJSON(spec.Plugin.Spec)}}
+		if spec.Plugin.Metadata != nil {
+			extra["plugin"].(map[string]any)["metadata"] = toJSON(spec.Plugin.Metadata)
+		}
+		if spec.DefaultValue != nil {
+			extra["defaultValue"] = toJSON(spec.DefaultValue)
+		}
+		w.line("variable: spec: %s", cueValue(extra, w.indent))
+		w.line("}}.variable,")
+	case *dashboard.TextVariableSpec:
+		w.line("{textVarBuilder & {")
+		w.field("#name", spec.Name)
+		if spec.Display != nil {
+			w.field("#display", toJSON(spec.Display))
+		}
+		w.field("#value", spec.Value)
+		if spec.Constant {
+			w.field("#constant", true)
+		}
+		w.line("}}.variable,")
+	default:
+		c.warn("variable of kind %q is not converted: the SDKs only build list and text variables", v.Kind)
+	}
+}
+
+func (c *converter) cuePanelGroup(w *cueWriter, group panelGroup) {
+	w.line("{")
+	w.field("#title", group.title)
+	cols := gridColumns / group.width
+	if gridColumns%group.width != 0 {
+		c.warn("the panels of panel group %q are %d columns wide in the CUE code, as its builder takes a number of panels per line",
+			group.title, gridColumns/cols)
+	}
+	w.field("#cols", float64(cols))
+	if group.height != 8 {
+		w.field("#height", float64(group.height))
+	}
+	if group.repeatVariable != "" {
+		w.field("#repeatVariable", group.repeatVariable)
+	}
+	if group.collapse != nil {
+		// the builder has no parameter for the collapse of the group, it is set on its output.
+		w.line("layout: spec: display: collapse: open: %t", group.collapse.Open)
+	}
+	w.line("#panels: [")
+	for _, p := range group.panels {
+		w.line("%s,", cueValue(toJSON(p), w.indent))
+	}
+	w.line("]")
+	w.line("},")
+}
+
+// toJSON returns the JSON equivalent of a value, made of maps, slices and basic values.
+func toJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// cueValue returns the CUE literal of a value decoded from JSON, with one field or item per line at the given indentation.
+func cueValue(value any, indent int) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return cueString(v)
+	case []any:
+		if len(v) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for _, item := range v {
+			b.WriteString(strings.Repeat("\t", indent+1) + cueValue(item, indent+1) + ",\n")
+		}
+		b.WriteString(strings.Repeat("\t", indent) + "]")
+		return b.String()
+	case map[string]any:
+		if len(v) == 0 {
+			return "{}"
+		}
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, key := range sortedKeys(v) {
+			b.WriteString(strings.Repeat("\t", indent+1) + cueLabel(key) + ": " + cueValue(v[key], indent+1) + "\n")
+		}
+		b.WriteString(strings.Repeat("\t", indent) + "}")
+		return b.String()
+	}
+	return cueValue(toJSON(value), indent)
+}
+
+// cueLabel returns the label of a field: the definitions (#name) and the identifiers as is, the other ones quoted.
+func cueLabel(name string) string {
+	if cueIdentifier.MatchString(strings.TrimPrefix(name, "#")) && !slices.Contains(cueKeywords, name) {
+		return name
+	}
+	return cueString(name)
+}
+
+// cueString returns the CUE literal of a string: its JSON encoding, or a raw string when it makes it easier to read,
+// e.g. for a PromQL query with label matchers.
+func cueString(value string) string {
+	if strings.Contains(value, `"`) && !strings.ContainsAny(value, "\n\r") && !strings.Contains(value, `"#`) && !strings.Contains(value, `\#`) {
+		return `#"` + value + `"#`
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(b.String(), "\n")
+}
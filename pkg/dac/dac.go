@@ -0,0 +1,217 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dac converts Perses dashboards into Dashboard-as-Code programs written with the Go or the CUE SDK of Perses.
+package dac
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+type Language string
+
+const (
+	// LanguageGo is a Go program building the dashboard with the builders of github.com/perses/perses/go-sdk.
+	LanguageGo Language = "go"
+	// LanguageCUE is a CUE file building the dashboard with the builders of github.com/perses/perses/cue/dac-utils.
+	LanguageCUE Language = "cue"
+)
+
+// Languages lists the supported languages.
+var Languages = []Language{LanguageGo, LanguageCUE}
+
+const gridColumns = 24
+
+// Program is the code of a dashboard.
+type Program struct {
+	Language Language `json:"language"`
+	Code     string   `json:"code"`
+	// Warnings lists what the code doesn't reproduce exactly, e.g. the positions of the panels.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Generate returns the program building the dashboard in the given language.
+// The SDKs lay out the panels of a group in lines of panels of the same size and name them after their position,
+// so the program builds an equivalent dashboard rather than an identical one: the differences are listed in the warnings.
+func Generate(dashboardObj *v1.Dashboard, language Language) (*Program, error) {
+	// the dashboard is copied through JSON, so that the plugin specs are plain maps, slices and basic values.
+	data, err := json.Marshal(dashboardObj)
+	if err != nil {
+		return nil, err
+	}
+	var copied v1.Dashboard
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("invalid dashboard: %w", err)
+	}
+
+	c := &converter{dashboard: &copied}
+	c.groups = c.panelGroups()
+	program := &Program{Language: language}
+	switch language {
+	case LanguageGo:
+		program.Code, err = c.goProgram()
+	case LanguageCUE:
+		program.Code = c.cueProgram()
+	default:
+		return nil, fmt.Errorf("unknown language %q. valid languages are: go, cue", language)
+	}
+	if err != nil {
+		return nil, err
+	}
+	program.Warnings = c.warnings
+	return program, nil
+}
+
+type converter struct {
+	dashboard *v1.Dashboard
+	groups    []panelGroup
+	warnings  []string
+}
+
+func (c *converter) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// panelGroup is a grid layout as the SDKs describe it: panels of the same size, laid out from left to right.
+type panelGroup struct {
+	title          string
+	width          int
+	height         int
+	collapse       *dashboard.GridLayoutCollapse
+	repeatVariable string
+	panels         []*v1.Panel
+}
+
+// panelGroups returns a panel group per grid layout, with the panels ordered by position.
+// The panels that aren't part of a layout are gathered in a last group.
+func (c *converter) panelGroups() []panelGroup {
+	var groups []panelGroup
+	var renamed []string
+	placed := make(map[string]bool)
+	for _, layout := range c.dashboard.Spec.Layouts {
+		spec, ok := layout.Spec.(*dashboard.GridLayoutSpec)
+		if layout.Kind != dashboard.KindGridLayout || !ok {
+			c.warn("layout of kind %q is not converted: the SDKs only build grid layouts", layout.Kind)
+			continue
+		}
+		group := panelGroup{
+			title:          fmt.Sprintf("Group %d", len(groups)+1),
+			repeatVariable: spec.RepeatVariable,
+		}
+		if spec.Display != nil {
+			group.title = spec.Display.Title
+			group.collapse = spec.Display.Collapse
+		}
+		items := slices.Clone(spec.Items)
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Y != items[j].Y {
+				return items[i].Y < items[j].Y
+			}
+			return items[i].X < items[j].X
+		})
+		var kept []dashboard.GridItem
+		for _, item := range items {
+			if item.Content == nil {
+				continue
+			}
+			id := strings.TrimPrefix(item.Content.Ref, "#/spec/panels/")
+			panel, ok := c.dashboard.Spec.Panels[id]
+			if !ok {
+				c.warn("panel group %q references the panel %q, which doesn't exist: it is ignored", group.title, id)
+				continue
+			}
+			if newID := fmt.Sprintf("%d_%d", len(groups), len(group.panels)); newID != id {
+				renamed = append(renamed, fmt.Sprintf("'%s' becomes '%s'", id, newID))
+			}
+			placed[id] = true
+			group.panels = append(group.panels, panel)
+			kept = append(kept, item)
+		}
+		if len(group.panels) == 0 {
+			group.width, group.height = gridColumns/2, 8
+			groups = append(groups, group)
+			continue
+		}
+		var widths, heights []int
+		for _, item := range kept {
+			widths = append(widths, item.Width)
+			heights = append(heights, item.Height)
+		}
+		group.width = min(max(mostCommon(widths), 1), gridColumns)
+		group.height = max(mostCommon(heights), 1)
+		for i, item := range kept {
+			x := (i * group.width) % gridColumns
+			y := (i * group.width) / gridColumns * group.height
+			if item.Width != group.width || item.Height != group.height || item.X != x || item.Y != y {
+				c.warn("the layout of panel group %q is approximated: the SDKs place its panels from left to right, all of size %dx%d",
+					group.title, group.width, group.height)
+				break
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	var orphans []string
+	for id := range c.dashboard.Spec.Panels {
+		if !placed[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) > 0 {
+		sort.Strings(orphans)
+		group := panelGroup{title: "Other panels", width: gridColumns / 2, height: 8}
+		for _, id := range orphans {
+			if newID := fmt.Sprintf("%d_%d", len(groups), len(group.panels)); newID != id {
+				renamed = append(renamed, fmt.Sprintf("'%s' becomes '%s'", id, newID))
+			}
+			group.panels = append(group.panels, c.dashboard.Spec.Panels[id])
+		}
+		groups = append(groups, group)
+		c.warn("the panels %s aren't part of any layout: they are gathered in the panel group %q, as the SDKs need a group for every panel",
+			strings.Join(orphans, ", "), group.title)
+	}
+	if len(renamed) > 0 {
+		c.warn("the SDKs name the panels after their position, so the references to the panels by ID must be updated: %s", strings.Join(renamed, ", "))
+	}
+	return groups
+}
+
+// mostCommon returns the value found the most times, the first one in case of a tie.
+func mostCommon(values []int) int {
+	counts := make(map[int]int)
+	result := values[0]
+	for _, value := range values {
+		counts[value]++
+		if counts[value] > counts[result] {
+			result = value
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of the map in alphabetical order, for the code to be stable.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dac
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the generated programs")
+
+// readDashboard decodes the dashboard of the tests.
+func readDashboard(t *testing.T) *v1.Dashboard {
+	data, err := os.ReadFile(filepath.Join("testdata", "dashboard.json"))
+	require.NoError(t, err)
+	var dashboardObj v1.Dashboard
+	require.NoError(t, json.Unmarshal(data, &dashboardObj))
+	return &dashboardObj
+}
+
+func TestGenerate(t *testing.T) {
+	for _, language := range Languages {
+		t.Run(string(language), func(t *testing.T) {
+			program, err := Generate(readDashboard(t), language)
+			require.NoError(t, err)
+			assert.Equal(t, language, program.Language)
+			assert.Equal(t, []string{
+				"the SDKs name the panels after their position, so the references to the panels by ID must be updated: " +
+					"'request_rate' becomes '0_0', 'error_ratio' becomes '0_1', 'notes' becomes '1_0'",
+			}, program.Warnings)
+
+			golden := filepath.Join("testdata", "dashboard."+string(language)+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(program.Code), 0o600))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), program.Code)
+		})
+	}
+}
+
+func TestGenerateUnknownLanguage(t *testing.T) {
+	_, err := Generate(readDashboard(t), "jsonnet")
+	assert.EqualError(t, err, `unknown language "jsonnet". valid languages are: go, cue`)
+}
+
+// TestGoProgramCompiles vets the generated Go program against the Go SDK of the version of Perses required by the module.
+func TestGoProgramCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the generated program is skipped in short mode")
+	}
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not available")
+	}
+	program, err := Generate(readDashboard(t), LanguageGo)
+	require.NoError(t, err)
+
+	// the program is written in the module, so that it is built with the dependencies of the module.
+	dir, err := os.MkdirTemp("testdata", "program")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(program.Code), 0o600))
+	output, err := exec.Command(goBinary, "vet", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
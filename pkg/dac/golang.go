@@ -0,0 +1,423 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dac
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+)
+
+// goImports are the packages the Go programs can use, by name, in the order of their import.
+var goImports = []struct {
+	name string
+	spec string
+}{
+	{"sdk", `"github.com/perses/perses/go-sdk"`},
+	{"dashboard", `"github.com/perses/perses/go-sdk/dashboard"`},
+	{"datasource", `"github.com/perses/perses/go-sdk/datasource"`},
+	{"link", `"github.com/perses/perses/go-sdk/link"`},
+	{"panel", `"github.com/perses/perses/go-sdk/panel"`},
+	{"panelgroup", `panelgroup "github.com/perses/perses/go-sdk/panel-group"`},
+	{"query", `"github.com/perses/perses/go-sdk/query"`},
+	{"listvariable", `listvariable "github.com/perses/perses/go-sdk/variable/list-variable"`},
+	{"textvariable", `textvariable "github.com/perses/perses/go-sdk/variable/text-variable"`},
+	{"v1", `v1 "github.com/perses/perses/pkg/model/api/v1"`},
+	{"common", `"github.com/perses/perses/pkg/model/api/v1/common"`},
+	{"variable", `"github.com/perses/perses/pkg/model/api/v1/variable"`},
+}
+
+// goHelpers are the options the Go programs define for what the SDK has no builder for, in the order of their definition.
+var goHelpers = []struct {
+	name string
+	code string
+}{
+	{"display", `
+// display sets the title and the description of the dashboard, for the values that dashboard.Name and dashboard.Description
+// would take for the name of the dashboard or ignore.
+func display(name string, description string) dashboard.Option {
+	return func(builder *dashboard.Builder) error {
+		builder.Dashboard.Spec.Display = &common.Display{Name: name, Description: description}
+		return nil
+	}
+}
+`},
+	{"links", `
+// links sets the links of the dashboard.
+func links(links ...v1.Link) dashboard.Option {
+	return func(builder *dashboard.Builder) error {
+		builder.Dashboard.Spec.Links = links
+		return nil
+	}
+}
+`},
+	{"listPlugin", `
+// listPlugin sets the plugin providing the values of a list variable.
+func listPlugin(plugin common.Plugin) listvariable.Option {
+	return func(builder *listvariable.Builder) error {
+		builder.ListVariableSpec.Plugin = plugin
+		return nil
+	}
+}
+`},
+}
+
+var sortConstants = map[variable.Sort]string{
+	variable.SortNone:                            "SortNone",
+	variable.SortAlphabeticalAsc:                 "SortAlphabeticalAsc",
+	variable.SortAlphabeticalDesc:                "SortAlphabeticalDesc",
+	variable.SortNumericalAsc:                    "SortNumericalAsc",
+	variable.SortNumericalDesc:                   "SortNumericalDesc",
+	variable.SortAlphabeticalCaseInsensitiveAsc:  "SortAlphabeticalCaseInsensitiveAsc",
+	variable.SortAlphabeticalCaseInsensitiveDesc: "SortAlphabeticalCaseInsensitiveDesc",
+}
+
+type goWriter struct {
+	body    strings.Builder
+	imports map[string]bool
+	helpers map[string]bool
+}
+
+func (w *goWriter) use(names ...string) {
+	for _, name := range names {
+		w.imports[name] = true
+	}
+}
+
+func (w *goWriter) printf(format string, args ...any) {
+	fmt.Fprintf(&w.body, format, args...)
+}
+
+// goProgram returns a main package printing the dashboard, built with the Go SDK.
+func (c *converter) goProgram() (string, error) {
+	w := &goWriter{imports: make(map[string]bool), helpers: make(map[string]bool)}
+	spec := c.dashboard.Spec
+	w.use("sdk", "dashboard")
+	w.printf("func main() {\nflag.Parse()\nexec := sdk.NewExec()\nexec.BuildDashboard(dashboard.New(%s,\n", goString(c.dashboard.Metadata.Name))
+	if c.dashboard.Metadata.Project != "" {
+		w.printf("dashboard.ProjectName(%s),\n", goString(c.dashboard.Metadata.Project))
+	}
+	if spec.Display != nil {
+		c.goDisplay(w, spec.Display)
+	}
+	if spec.Duration != "" {
+		w.printf("dashboard.DurationAsString(%s),\n", goString(string(spec.Duration)))
+	}
+	if spec.RefreshInterval != "" {
+		w.printf("dashboard.RefreshIntervalAsString(%s),\n", goString(string(spec.RefreshInterval)))
+	}
+	for _, name := range sortedKeys(spec.Datasources) {
+		c.goDatasource(w, name, spec.Datasources[name])
+	}
+	for _, v := range spec.Variables {
+		c.goVariable(w, v)
+	}
+	for _, group := range c.groups {
+		c.goPanelGroup(w, group)
+	}
+	if len(spec.Links) > 0 {
+		w.helpers["links"] = true
+		w.use("v1")
+		w.printf("links(\n")
+		for _, l := range spec.Links {
+			w.printf("%s,\n", goLink(l))
+		}
+		w.printf("),\n")
+	}
+	w.printf("))\n}\n")
+	return w.source(c.dashboard)
+}
+
+// goDisplay relies on dashboard.Name and dashboard.Description when they would set the display of the dashboard.
+func (c *converter) goDisplay(w *goWriter, display *common.Display) {
+	nameIsDisplay := display.Name == "" || common.ValidateID(display.Name) != nil
+	descriptionIsDisplay := display.Description == "" || common.ValidateDescription(display.Description) != nil
+	if !nameIsDisplay || !descriptionIsDisplay {
+		w.helpers["display"] = true
+		w.use("common")
+		w.printf("display(%s, %s),\n", goString(display.Name), goString(display.Description))
+		return
+	}
+	if display.Name != "" {
+		w.printf("dashboard.Name(%s),\n", goString(display.Name))
+	}
+	if display.Description != "" {
+		w.printf("dashboard.Description(%s),\n", goString(display.Description))
+	}
+}
+
+func (c *converter) goDatasource(w *goWriter, name string, spec *v1.DatasourceSpec) {
+	w.use("datasource", "common")
+	w.printf("dashboard.AddDatasource(%s,\n", goString(name))
+	if spec.Default {
+		w.printf("datasource.Default(true),\n")
+	}
+	w.printf("datasource.Plugin(%s),\n", goPlugin(spec.Plugin))
+	w.printf("),\n")
+	if spec.Display != nil {
+		c.warn("the display of the datasource %q is not converted: the SDKs have no builder for it", name)
+	}
+}
+
+func (c *converter) goVariable(w *goWriter, v dashboard.Variable) {
+	switch spec := v.Spec.(type) {
+	case *dashboard.ListVariableSpec:
+		w.use("listvariable", "common")
+		w.helpers["listPlugin"] = true
+		w.printf("dashboard.AddVariable(%s, listvariable.List(\n", goString(spec.Name))
+		goVariableDisplay(w, "listvariable", spec.Display)
+		if spec.DefaultValue != nil {
+			if spec.DefaultValue.SliceValues != nil {
+				values := make([]string, 0, len(spec.DefaultValue.SliceValues))
+				for _, value := range spec.DefaultValue.SliceValues {
+					values = append(values, goString(value))
+				}
+				w.printf("listvariable.DefaultValues(%s),\n", strings.Join(values, ", "))
+			} else {
+				w.printf("listvariable.DefaultValue(%s),\n", goString(spec.DefaultValue.SingleValue))
+			}
+		}
+		if spec.AllowAllValue {
+			w.printf("listvariable.AllowAllValue(true),\n")
+		}
+		if spec.AllowMultiple {
+			w.printf("listvariable.AllowMultiple(true),\n")
+		}
+		if spec.CustomAllValue != "" {
+			w.printf("listvariable.CustomAllValue(%s),\n", goString(spec.CustomAllValue))
+		}
+		if spec.CapturingRegexp != "" {
+			w.printf("listvariable.CapturingRegexp(%s),\n", goString(spec.CapturingRegexp))
+		}
+		if spec.Sort != nil {
+			w.use("variable")
+			if constant, ok := sortConstants[*spec.Sort]; ok {
+				w.printf("listvariable.SortingBy(variable.%s),\n", constant)
+			} else {
+				w.printf("listvariable.SortingBy(variable.Sort(%s)),\n", goString(string(*spec.Sort)))
+			}
+		}
+		w.printf("listPlugin(%s),\n", goPlugin(spec.Plugin))
+		w.printf(")),\n")
+	case *dashboard.TextVariableSpec:
+		w.use("textvariable")
+		w.printf("dashboard.AddVariable(%s, textvariable.Text(%s,\n", goString(spec.Name), goString(spec.Value))
+		goVariableDisplay(w, "textvariable", spec.Display)
+		if spec.Constant {
+			w.printf("textvariable.Constant(true),\n")
+		}
+		w.printf(")),\n")
+	default:
+		c.warn("variable of kind %q is not converted: the SDKs only build list and text variables", v.Kind)
+	}
+}
+
+func goVariableDisplay(w *goWriter, pkg string, display *variable.Display) {
+	if display == nil {
+		return
+	}
+	if display.Name != "" {
+		w.printf("%s.DisplayName(%s),\n", pkg, goString(display.Name))
+	}
+	if display.Description != "" {
+		w.printf("%s.Description(%s),\n", pkg, goString(display.Description))
+	}
+	if display.Hidden {
+		w.printf("%s.Hidden(true),\n", pkg)
+	}
+}
+
+func (c *converter) goPanelGroup(w *goWriter, group panelGroup) {
+	w.use("panelgroup")
+	w.printf("dashboard.AddPanelGroup(%s,\n", goString(group.title))
+	switch {
+	case group.width == gridColumns/2:
+	case gridColumns%group.width == 0:
+		w.printf("panelgroup.PanelsPerLine(%d),\n", gridColumns/group.width)
+	default:
+		w.printf("panelgroup.PanelWidth(%d),\n", group.width)
+	}
+	if group.height != 8 {
+		w.printf("panelgroup.PanelHeight(%d),\n", group.height)
+	}
+	if group.collapse != nil {
+		// the SDK only makes the group collapsible when it isn't collapsed.
+		w.printf("panelgroup.Collapsed(false),\n")
+		if !group.collapse.Open {
+			c.warn("the panel group %q is open in the Go code: the SDK builds the collapsible groups open", group.title)
+		}
+	}
+	if group.repeatVariable != "" {
+		w.printf("panelgroup.RepeatVariable(%s),\n", goString(group.repeatVariable))
+	}
+	for _, p := range group.panels {
+		c.goPanel(w, p)
+	}
+	w.printf("),\n")
+}
+
+func (c *converter) goPanel(w *goWriter, p *v1.Panel) {
+	w.use("panel", "common")
+	title := ""
+	if p.Spec.Display != nil {
+		title = p.Spec.Display.Name
+	}
+	w.printf("panelgroup.AddPanel(%s,\n", goString(title))
+	if p.Spec.Display != nil && p.Spec.Display.Description != "" {
+		w.printf("panel.Description(%s),\n", goString(p.Spec.Display.Description))
+	}
+	w.printf("panel.Plugin(%s),\n", goPlugin(p.Spec.Plugin))
+	if len(p.Spec.Queries) > 0 {
+		w.use("query")
+		w.printf("panel.AddQuery(\n")
+		for _, q := range p.Spec.Queries {
+			w.printf("query.Option{\nKind: %s,\nPlugin: %s,\n},\n", goString(q.Kind), goPlugin(q.Spec.Plugin))
+		}
+		w.printf("),\n")
+	}
+	for _, l := range p.Spec.Links {
+		w.use("link")
+		w.printf("panel.AddLink(%s", goString(l.URL))
+		if l.Name != "" {
+			w.printf(", link.Name(%s)", goString(l.Name))
+		}
+		if l.Tooltip != "" {
+			w.printf(", link.Tooltip(%s)", goString(l.Tooltip))
+		}
+		if l.RenderVariables {
+			w.printf(", link.RenderVariable(true)")
+		}
+		if l.TargetBlank {
+			w.printf(", link.TargetBlank(true)")
+		}
+		w.printf("),\n")
+	}
+	w.printf("),\n")
+}
+
+// source returns the formatted file, with the imports and the helpers used by the body.
+func (w *goWriter) source(dashboardObj *v1.Dashboard) (string, error) {
+	var file strings.Builder
+	fmt.Fprintf(&file, "// Dashboard-as-Code of the dashboard %s.\n", dashboardReference(dashboardObj))
+	file.WriteString("// Run it with \"go run main.go\" to print the dashboard, or build it with \"percli dac build\".\n")
+	file.WriteString("package main\n\nimport (\n\"flag\"\n\n")
+	for _, imp := range goImports {
+		if w.imports[imp.name] {
+			file.WriteString(imp.spec + "\n")
+		}
+	}
+	file.WriteString(")\n\n")
+	file.WriteString(w.body.String())
+	for _, helper := range goHelpers {
+		if w.helpers[helper.name] {
+			file.WriteString(helper.code)
+		}
+	}
+	code, err := format.Source([]byte(file.String()))
+	if err != nil {
+		return "", fmt.Errorf("unable to format the Go program: %w", err)
+	}
+	return string(code), nil
+}
+
+func goPlugin(plugin common.Plugin) string {
+	fields := []string{"Kind: " + goString(plugin.Kind)}
+	if plugin.Metadata != nil {
+		var metadata []string
+		if plugin.Metadata.Version != "" {
+			metadata = append(metadata, "Version: "+goString(plugin.Metadata.Version))
+		}
+		if plugin.Metadata.Registry != "" {
+			metadata = append(metadata, "Registry: "+goString(plugin.Metadata.Registry))
+		}
+		fields = append(fields, fmt.Sprintf("Metadata: &common.PluginMetadata{%s}", strings.Join(metadata, ", ")))
+	}
+	fields = append(fields, "Spec: "+goValue(plugin.Spec))
+	return "common.Plugin{\n" + strings.Join(fields, ",\n") + ",\n}"
+}
+
+func goLink(l v1.Link) string {
+	fields := []string{"URL: " + goString(l.URL)}
+	if l.Name != "" {
+		fields = append(fields, "Name: "+goString(l.Name))
+	}
+	if l.Tooltip != "" {
+		fields = append(fields, "Tooltip: "+goString(l.Tooltip))
+	}
+	if l.RenderVariables {
+		fields = append(fields, "RenderVariables: true")
+	}
+	if l.TargetBlank {
+		fields = append(fields, "TargetBlank: true")
+	}
+	return "v1.Link{" + strings.Join(fields, ", ") + "}"
+}
+
+// goValue returns the Go literal of a value decoded from JSON.
+func goValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return goString(v)
+	case []any:
+		if len(v) == 0 {
+			return "[]any{}"
+		}
+		var b strings.Builder
+		b.WriteString("[]any{\n")
+		for _, item := range v {
+			b.WriteString(goValue(item) + ",\n")
+		}
+		b.WriteString("}")
+		return b.String()
+	case map[string]any:
+		if len(v) == 0 {
+			return "map[string]any{}"
+		}
+		var b strings.Builder
+		b.WriteString("map[string]any{\n")
+		for _, key := range sortedKeys(v) {
+			b.WriteString(goString(key) + ": " + goValue(v[key]) + ",\n")
+		}
+		b.WriteString("}")
+		return b.String()
+	}
+	return fmt.Sprintf("%#v", value)
+}
+
+// goString returns the Go literal of a string, as a raw string when it makes it easier to read, e.g. for a PromQL query with label matchers.
+func goString(value string) string {
+	if strings.Contains(value, `"`) && !strings.ContainsAny(value, "`\r") && strconv.CanBackquote(strings.ReplaceAll(value, "\n", "")) {
+		return "`" + value + "`"
+	}
+	return strconv.Quote(value)
+}
+
+func dashboardReference(dashboardObj *v1.Dashboard) string {
+	if dashboardObj.Metadata.Project == "" {
+		return fmt.Sprintf("'%s'", dashboardObj.Metadata.Name)
+	}
+	return fmt.Sprintf("'%s' of the project '%s'", dashboardObj.Metadata.Name, dashboardObj.Metadata.Project)
+}
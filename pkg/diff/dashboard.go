@@ -0,0 +1,250 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type Status string
+
+const (
+	StatusAdded    Status = "added"
+	StatusRemoved  Status = "removed"
+	StatusModified Status = "modified"
+)
+
+// DashboardDiff is the structural difference between two dashboards. The paths of the changes are relative to the element that changed.
+type DashboardDiff struct {
+	Identical bool `json:"identical"`
+	// Display holds the changes of the name and the description of the dashboard.
+	Display []Change `json:"display,omitempty"`
+	// Panels holds the panels added, removed or modified, by panel ID (their key in spec.panels).
+	Panels map[string]*PanelDiff `json:"panels,omitempty"`
+	// Variables holds the variables added, removed or modified, by variable name.
+	Variables map[string]*VariableDiff `json:"variables,omitempty"`
+	// Layouts holds the changes of the panel groups, apart from the positions of the panels reported with each panel.
+	Layouts []Change `json:"layouts,omitempty"`
+	// Other holds the changes of the other fields of the spec, e.g. the duration or the datasources.
+	Other []Change `json:"other,omitempty"`
+}
+
+// PanelDiff describes how a panel changed.
+type PanelDiff struct {
+	Status Status `json:"status"`
+	// Title is the display name of the panel, the new one when it changed.
+	Title   string        `json:"title,omitempty"`
+	Display []Change      `json:"display,omitempty"`
+	Plugin  []Change      `json:"plugin,omitempty"`
+	Queries []QueryDiff   `json:"queries,omitempty"`
+	Layout  *PositionDiff `json:"layout,omitempty"`
+	// Other holds the changes of the other fields of the panel, e.g. its links.
+	Other []Change `json:"other,omitempty"`
+}
+
+// QueryDiff describes how a query of a panel changed, identified by its index.
+type QueryDiff struct {
+	Index     int       `json:"index"`
+	Operation Operation `json:"operation"`
+	// Old and New are the expressions of the query (e.g. the PromQL query), when they differ.
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+	// Datasource holds the datasource selectors of the query, when they differ.
+	Datasource *DatasourceDiff `json:"datasource,omitempty"`
+	// Changes holds the changes of the other fields of the query, e.g. its legend.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// DatasourceDiff is the change of the datasource selector of a query. A nil selector selects the default datasource.
+type DatasourceDiff struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// PositionDiff is the move of a panel in the layouts of the dashboard. A nil position means the panel isn't in any layout.
+type PositionDiff struct {
+	Old *Position `json:"old"`
+	New *Position `json:"new"`
+}
+
+// Position is the place of a panel in a grid layout.
+type Position struct {
+	// Group is the title of the panel group, or its index (e.g. "#0") when it has no title.
+	Group  string `json:"group"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// index is the index of the panel group, so that renaming a group doesn't move its panels.
+	index int
+}
+
+// VariableDiff describes how a variable changed.
+type VariableDiff struct {
+	Status  Status   `json:"status"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Dashboards returns the structural changes turning the dashboard before into the dashboard after, two generic JSON representations of dashboards.
+// Only the spec is compared, so that two different dashboards can be compared.
+func Dashboards(before, after map[string]any) *DashboardDiff {
+	beforeSpec := mapAt(before, "spec")
+	afterSpec := mapAt(after, "spec")
+	result := &DashboardDiff{
+		Display:   Compare(mapAt(beforeSpec, "display"), mapAt(afterSpec, "display")),
+		Panels:    map[string]*PanelDiff{},
+		Variables: map[string]*VariableDiff{},
+		Layouts:   Compare(withoutLayoutItems(sliceAt(beforeSpec, "layouts")), withoutLayoutItems(sliceAt(afterSpec, "layouts"))),
+		Other:     Compare(withoutKeys(beforeSpec, nil, "display", "panels", "variables", "layouts"), withoutKeys(afterSpec, nil, "display", "panels", "variables", "layouts")),
+	}
+
+	beforePanels, afterPanels := mapAt(beforeSpec, "panels"), mapAt(afterSpec, "panels")
+	beforePositions, afterPositions := panelPositions(sliceAt(beforeSpec, "layouts")), panelPositions(sliceAt(afterSpec, "layouts"))
+	for _, key := range sortedKeys(beforePanels, afterPanels) {
+		beforePanel, inBefore := beforePanels[key].(map[string]any)
+		afterPanel, inAfter := afterPanels[key].(map[string]any)
+		switch {
+		case !inAfter:
+			result.Panels[key] = &PanelDiff{Status: StatusRemoved, Title: panelTitle(beforePanel)}
+		case !inBefore:
+			result.Panels[key] = &PanelDiff{Status: StatusAdded, Title: panelTitle(afterPanel)}
+		default:
+			if panel := comparePanels(beforePanel, afterPanel, beforePositions[key], afterPositions[key]); panel != nil {
+				result.Panels[key] = panel
+			}
+		}
+	}
+
+	beforeVariables, afterVariables := indexVariables(sliceAt(beforeSpec, "variables")), indexVariables(sliceAt(afterSpec, "variables"))
+	for _, name := range sortedKeys(beforeVariables, afterVariables) {
+		beforeVariable, inBefore := beforeVariables[name]
+		afterVariable, inAfter := afterVariables[name]
+		switch {
+		case !inAfter:
+			result.Variables[name] = &VariableDiff{Status: StatusRemoved}
+		case !inBefore:
+			result.Variables[name] = &VariableDiff{Status: StatusAdded}
+		default:
+			if changes := Compare(beforeVariable, afterVariable); len(changes) > 0 {
+				result.Variables[name] = &VariableDiff{Status: StatusModified, Changes: changes}
+			}
+		}
+	}
+
+	result.Identical = len(result.Display) == 0 && len(result.Panels) == 0 && len(result.Variables) == 0 && len(result.Layouts) == 0 && len(result.Other) == 0
+	return result
+}
+
+// comparePanels returns the changes of a panel present in both dashboards, or nil when it didn't change.
+func comparePanels(before, after map[string]any, beforePosition, afterPosition *Position) *PanelDiff {
+	beforeSpec, afterSpec := mapAt(before, "spec"), mapAt(after, "spec")
+	panel := &PanelDiff{
+		Status:  StatusModified,
+		Title:   panelTitle(after),
+		Display: Compare(mapAt(beforeSpec, "display"), mapAt(afterSpec, "display")),
+		Plugin:  Compare(mapAt(beforeSpec, "plugin"), mapAt(afterSpec, "plugin")),
+		Queries: compareQueries(sliceAt(beforeSpec, "queries"), sliceAt(afterSpec, "queries")),
+		Other:   Compare(withoutKeys(before, []string{"spec"}, "display", "plugin", "queries"), withoutKeys(after, []string{"spec"}, "display", "plugin", "queries")),
+	}
+	if !samePosition(beforePosition, afterPosition) {
+		panel.Layout = &PositionDiff{Old: beforePosition, New: afterPosition}
+	}
+	if len(panel.Display) == 0 && len(panel.Plugin) == 0 && len(panel.Queries) == 0 && len(panel.Other) == 0 && panel.Layout == nil {
+		return nil
+	}
+	return panel
+}
+
+func compareQueries(before, after []any) []QueryDiff {
+	var result []QueryDiff
+	for i := 0; i < len(before) || i < len(after); i++ {
+		switch {
+		case i >= len(after):
+			result = append(result, QueryDiff{Index: i, Operation: Removed, Old: queryExpression(before[i])})
+		case i >= len(before):
+			result = append(result, QueryDiff{Index: i, Operation: Added, New: queryExpression(after[i])})
+		default:
+			query := QueryDiff{Index: i, Operation: Changed}
+			if oldExpression, newExpression := queryExpression(before[i]), queryExpression(after[i]); !reflect.DeepEqual(oldExpression, newExpression) {
+				query.Old, query.New = oldExpression, newExpression
+			}
+			if oldDatasource, newDatasource := queryDatasource(before[i]), queryDatasource(after[i]); !reflect.DeepEqual(oldDatasource, newDatasource) {
+				query.Datasource = &DatasourceDiff{Old: oldDatasource, New: newDatasource}
+			}
+			pluginSpecPath := []string{"spec", "plugin", "spec"}
+			query.Changes = Compare(withoutKeys(asMap(before[i]), pluginSpecPath, "query", "datasource"), withoutKeys(asMap(after[i]), pluginSpecPath, "query", "datasource"))
+			if query.Old != nil || query.New != nil || query.Datasource != nil || len(query.Changes) > 0 {
+				result = append(result, query)
+			}
+		}
+	}
+	return result
+}
+
+// panelPositions returns the position of the panels in the grid layouts, by panel ID.
+func panelPositions(layouts []any) map[string]*Position {
+	positions := map[string]*Position{}
+	for i, layout := range layouts {
+		spec := mapAt(asMap(layout), "spec")
+		group := fmt.Sprintf("#%d", i)
+		if title, ok := mapAt(spec, "display")["title"].(string); ok && title != "" {
+			group = title
+		}
+		for _, item := range sliceAt(spec, "items") {
+			ref, _ := mapAt(asMap(item), "content")["$ref"].(string)
+			key, ok := strings.CutPrefix(ref, "#/spec/panels/")
+			if !ok {
+				continue
+			}
+			positions[key] = &Position{
+				Group:  group,
+				index:  i,
+				X:      intAt(asMap(item), "x"),
+				Y:      intAt(asMap(item), "y"),
+				Width:  intAt(asMap(item), "width"),
+				Height: intAt(asMap(item), "height"),
+			}
+		}
+	}
+	return positions
+}
+
+func samePosition(before, after *Position) bool {
+	if before == nil || after == nil {
+		return before == after
+	}
+	return before.index == after.index && before.X == after.X && before.Y == after.Y && before.Width == after.Width && before.Height == after.Height
+}
+
+// withoutLayoutItems returns the layouts without the items, whose changes are reported as moves of the panels.
+func withoutLayoutItems(layouts []any) []any {
+	result := make([]any, 0, len(layouts))
+	for _, layout := range layouts {
+		result = append(result, withoutKeys(asMap(layout), []string{"spec"}, "items"))
+	}
+	return result
+}
+
+// panelTitle returns the display name of the panel.
+func panelTitle(panel map[string]any) string {
+	name, _ := mapAt(mapAt(panel, "spec"), "display")["name"].(string)
+	return name
+}
+
+func intAt(obj map[string]any, key string) int {
+	value, _ := obj[key].(float64)
+	return int(value)
+}
@@ -0,0 +1,118 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff compares the generic JSON representation of two Perses objects.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Operation string
+
+const (
+	Added   Operation = "added"
+	Removed Operation = "removed"
+	Changed Operation = "changed"
+)
+
+// Change is a difference between two values at the given path (e.g. "spec.panels.cpu.spec.display.name").
+type Change struct {
+	Path      string    `json:"path"`
+	Operation Operation `json:"operation"`
+	Old       any       `json:"old,omitempty"`
+	New       any       `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Operation {
+	case Added:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// Compare returns the changes turning before into after. Both values must be generic JSON values
+// (map[string]any, []any, string, float64, bool or nil). The changes are sorted by path.
+func Compare(before, after any) []Change {
+	var changes []Change
+	compare("", before, after, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func compare(path string, before, after any, changes *[]Change) {
+	switch beforeValue := before.(type) {
+	case map[string]any:
+		if afterValue, ok := after.(map[string]any); ok {
+			compareMaps(path, beforeValue, afterValue, changes)
+			return
+		}
+	case []any:
+		if afterValue, ok := after.([]any); ok {
+			compareSlices(path, beforeValue, afterValue, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Operation: Changed, Old: before, New: after})
+	}
+}
+
+func compareMaps(path string, before, after map[string]any, changes *[]Change) {
+	for key, beforeValue := range before {
+		afterValue, ok := after[key]
+		if !ok {
+			*changes = append(*changes, Change{Path: join(path, key), Operation: Removed, Old: beforeValue})
+			continue
+		}
+		compare(join(path, key), beforeValue, afterValue, changes)
+	}
+	for key, afterValue := range after {
+		if _, ok := before[key]; !ok {
+			*changes = append(*changes, Change{Path: join(path, key), Operation: Added, New: afterValue})
+		}
+	}
+}
+
+func compareSlices(path string, before, after []any, changes *[]Change) {
+	for i := 0; i < len(before) || i < len(after); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(after):
+			*changes = append(*changes, Change{Path: itemPath, Operation: Removed, Old: before[i]})
+		case i >= len(before):
+			*changes = append(*changes, Change{Path: itemPath, Operation: Added, New: after[i]})
+		default:
+			compare(itemPath, before[i], after[i], changes)
+		}
+	}
+}
+
+func join(path string, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("%q", key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,253 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, data string) map[string]any {
+	var result map[string]any
+	require.NoError(t, json.Unmarshal([]byte(data), &result))
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		before  string
+		after   string
+		changes []string
+	}{
+		{
+			title:  "identical",
+			before: `{"a":1,"b":[1,{"c":"d"}]}`,
+			after:  `{"a":1,"b":[1,{"c":"d"}]}`,
+		},
+		{
+			title:   "value changed, added and removed",
+			before:  `{"a":1,"b":{"c":"d"}}`,
+			after:   `{"a":2,"e":true}`,
+			changes: []string{"~ a: 1 -> 2", "- b: map[c:d]", "+ e: true"},
+		},
+		{
+			title:   "slice items",
+			before:  `{"a":[1,2,3]}`,
+			after:   `{"a":[1,4]}`,
+			changes: []string{"~ a[1]: 2 -> 4", "- a[2]: 3"},
+		},
+		{
+			title:   "key with a dot",
+			before:  `{"a":{"b.c":1}}`,
+			after:   `{"a":{"b.c":2}}`,
+			changes: []string{`~ a."b.c": 1 -> 2`},
+		},
+		{
+			title:   "type changed",
+			before:  `{"a":{"b":1}}`,
+			after:   `{"a":[1]}`,
+			changes: []string{"~ a: map[b:1] -> [1]"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var changes []string
+			for _, change := range Compare(parse(t, test.before), parse(t, test.after)) {
+				changes = append(changes, change.String())
+			}
+			assert.Equal(t, test.changes, changes)
+		})
+	}
+}
+
+// dashboard returns a dashboard with a panel 'cpu' in a grid layout, after applying the replacements to its definition.
+func dashboard(t *testing.T, replacements ...string) map[string]any {
+	def := `{
+		"kind": "Dashboard",
+		"metadata": {"name": "overview", "project": "shop"},
+		"spec": {
+			"display": {"name": "Overview"},
+			"duration": "1h",
+			"variables": [{"kind": "TextVariable", "spec": {"name": "job", "value": "api"}}],
+			"panels": {
+				"cpu": {"kind": "Panel", "spec": {
+					"display": {"name": "CPU"},
+					"plugin": {"kind": "TimeSeriesChart", "spec": {}},
+					"queries": [{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "rate(cpu[5m])", "seriesNameFormat": "{{pod}}"}}}}]
+				}}
+			},
+			"layouts": [{"kind": "Grid", "spec": {"display": {"title": "Resources"}, "items": [{"x": 0, "y": 0, "width": 12, "height": 6, "content": {"$ref": "#/spec/panels/cpu"}}]}}]
+		}
+	}`
+	require.Zero(t, len(replacements)%2)
+	for i := 0; i < len(replacements); i += 2 {
+		require.Contains(t, def, replacements[i])
+		def = strings.Replace(def, replacements[i], replacements[i+1], 1)
+	}
+	return parse(t, def)
+}
+
+func TestDashboards(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		result := Dashboards(dashboard(t), dashboard(t, `"name": "overview"`, `"name": "other"`))
+		assert.True(t, result.Identical, "the metadata is not compared")
+	})
+
+	t.Run("panel changes", func(t *testing.T) {
+		after := dashboard(t,
+			`"display": {"name": "CPU"}`, `"display": {"name": "CPU usage"}`,
+			`"query": "rate(cpu[5m])"`, `"query": "rate(cpu[1m])", "datasource": {"kind": "PrometheusDatasource", "name": "thanos"}`,
+			`"seriesNameFormat": "{{pod}}"`, `"seriesNameFormat": "{{node}}"`,
+			`"x": 0`, `"x": 12`,
+		)
+		result := Dashboards(dashboard(t), after)
+		assert.False(t, result.Identical)
+		require.Contains(t, result.Panels, "cpu")
+		panel := result.Panels["cpu"]
+		assert.Equal(t, StatusModified, panel.Status)
+		assert.Equal(t, "CPU usage", panel.Title)
+		assert.Equal(t, []Change{{Path: "name", Operation: Changed, Old: "CPU", New: "CPU usage"}}, panel.Display)
+		assert.Equal(t, []QueryDiff{{
+			Index:      0,
+			Operation:  Changed,
+			Old:        "rate(cpu[5m])",
+			New:        "rate(cpu[1m])",
+			Datasource: &DatasourceDiff{Old: nil, New: map[string]any{"kind": "PrometheusDatasource", "name": "thanos"}},
+			Changes:    []Change{{Path: "spec.plugin.spec.seriesNameFormat", Operation: Changed, Old: "{{pod}}", New: "{{node}}"}},
+		}}, panel.Queries)
+		assert.Equal(t, &PositionDiff{
+			Old: &Position{Group: "Resources", X: 0, Y: 0, Width: 12, Height: 6},
+			New: &Position{Group: "Resources", X: 12, Y: 0, Width: 12, Height: 6},
+		}, panel.Layout)
+		assert.Empty(t, result.Layouts, "the moves are reported with the panels")
+	})
+
+	t.Run("panels and variables added and removed", func(t *testing.T) {
+		after := dashboard(t,
+			`"cpu": {`, `"memory": {`,
+			`"name": "job"`, `"name": "service"`,
+		)
+		result := Dashboards(dashboard(t), after)
+		assert.Equal(t, map[string]*PanelDiff{
+			"cpu":    {Status: StatusRemoved, Title: "CPU"},
+			"memory": {Status: StatusAdded, Title: "CPU"},
+		}, result.Panels)
+		assert.Equal(t, map[string]*VariableDiff{
+			"job":     {Status: StatusRemoved},
+			"service": {Status: StatusAdded},
+		}, result.Variables)
+	})
+
+	t.Run("dashboard fields", func(t *testing.T) {
+		after := dashboard(t,
+			`"display": {"name": "Overview"}`, `"display": {"name": "Service overview"}`,
+			`"duration": "1h"`, `"duration": "6h"`,
+			`"title": "Resources"`, `"title": "Usage"`,
+		)
+		result := Dashboards(dashboard(t), after)
+		assert.Equal(t, []Change{{Path: "name", Operation: Changed, Old: "Overview", New: "Service overview"}}, result.Display)
+		assert.Equal(t, []Change{{Path: "duration", Operation: Changed, Old: "1h", New: "6h"}}, result.Other)
+		assert.Equal(t, []Change{{Path: "[0].spec.display.title", Operation: Changed, Old: "Resources", New: "Usage"}}, result.Layouts)
+		assert.Empty(t, result.Panels, "renaming a group doesn't move its panels")
+	})
+}
+
+func TestSemantic(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		kind    string
+		before  map[string]any
+		after   map[string]any
+		changes []string
+	}{
+		{
+			title:  "identical dashboards",
+			kind:   "Dashboard",
+			before: dashboard(t),
+			after:  dashboard(t),
+		},
+		{
+			title:  "query and datasource changed",
+			kind:   "Dashboard",
+			before: dashboard(t),
+			after:  dashboard(t, `"query": "rate(cpu[5m])"`, `"query": "rate(cpu[1m])", "datasource": {"kind": "PrometheusDatasource", "name": "thanos"}`),
+			changes: []string{
+				"datasource_reference_changed cpu: panel 'CPU' (cpu): query 0 uses another datasource",
+				"query_changed cpu: panel 'CPU' (cpu): query 0 changed",
+			},
+		},
+		{
+			title:   "panel display changed",
+			kind:    "Dashboard",
+			before:  dashboard(t),
+			after:   dashboard(t, `"display": {"name": "CPU"}`, `"display": {"name": "CPU usage"}`),
+			changes: []string{"panel_changed cpu: panel 'CPU usage' (cpu): spec.display.name changed"},
+		},
+		{
+			title:  "panel moved and variable changed",
+			kind:   "Dashboard",
+			before: dashboard(t),
+			after:  dashboard(t, `"x": 0`, `"x": 12`, `"value": "api"`, `"value": "web"`),
+			changes: []string{
+				"variable_changed job: variable 'job': spec.value changed",
+				"layout_changed : the layout of the panels changed",
+			},
+		},
+		{
+			title:  "panel added and removed",
+			kind:   "Dashboard",
+			before: dashboard(t),
+			after:  dashboard(t, `"cpu": {`, `"memory": {`),
+			changes: []string{
+				"panel_removed cpu: panel 'CPU' (cpu) removed",
+				"panel_added memory: panel 'CPU' (memory) added",
+			},
+		},
+		{
+			title:  "metadata and spec fields",
+			kind:   "Dashboard",
+			before: dashboard(t),
+			after:  dashboard(t, `"project": "shop"`, `"project": "lab"`, `"duration": "1h"`, `"duration": "6h"`, `"name": "Overview"`, `"name": "Service"`),
+			changes: []string{
+				"field_changed metadata.project: metadata.project changed",
+				"field_changed spec.display.name: spec.display.name changed",
+				"field_changed spec.duration: spec.duration changed",
+			},
+		},
+		{
+			title:  "datasource URL",
+			kind:   "Datasource",
+			before: parse(t, `{"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://a:9090"}}}}`),
+			after:  parse(t, `{"spec":{"default":false,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://b:9090"}}}}`),
+			changes: []string{
+				"datasource_url_changed : the URL of the datasource changed",
+				"field_changed spec.default: spec.default changed",
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var changes []string
+			for _, change := range Semantic(test.kind, test.before, test.after) {
+				changes = append(changes, string(change.Type)+" "+change.Target+": "+change.Description)
+			}
+			assert.Equal(t, test.changes, changes)
+		})
+	}
+}
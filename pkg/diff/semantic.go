@@ -0,0 +1,310 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type ChangeType string
+
+const (
+	PanelAdded           ChangeType = "panel_added"
+	PanelRemoved         ChangeType = "panel_removed"
+	PanelChanged         ChangeType = "panel_changed"
+	QueryAdded           ChangeType = "query_added"
+	QueryRemoved         ChangeType = "query_removed"
+	QueryChanged         ChangeType = "query_changed"
+	DatasourceRefChanged ChangeType = "datasource_reference_changed"
+	VariableAdded        ChangeType = "variable_added"
+	VariableRemoved      ChangeType = "variable_removed"
+	VariableChanged      ChangeType = "variable_changed"
+	LayoutChanged        ChangeType = "layout_changed"
+	DatasourceURLChanged ChangeType = "datasource_url_changed"
+	FieldChanged         ChangeType = "field_changed"
+)
+
+// SemanticChange describes a change in terms of the Perses model, e.g. a panel added or a query changed.
+type SemanticChange struct {
+	Type ChangeType `json:"type"`
+	// Target identifies the element that changed, e.g. the key of a panel or the name of a variable.
+	Target      string `json:"target,omitempty"`
+	Description string `json:"description"`
+	Old         any    `json:"old,omitempty"`
+	New         any    `json:"new,omitempty"`
+}
+
+// datasourceURLPaths are the paths where the datasources define the URL of their backend.
+var datasourceURLPaths = []string{"spec.plugin.spec.directUrl", "spec.plugin.spec.proxy.spec.url"}
+
+// Semantic returns the changes turning before into after, two generic JSON representations of an object of the given kind.
+// The changes of the dashboards are grouped by panel, query and variable, and the URL of the datasources is reported on its own.
+// The other changes are reported as field changes.
+func Semantic(kind string, before, after map[string]any) []SemanticChange {
+	switch kind {
+	case "Dashboard", "EphemeralDashboard":
+		return dashboardChanges(before, after)
+	case "Datasource", "GlobalDatasource":
+		return datasourceChanges(before, after)
+	default:
+		return fieldChanges(Compare(before, after))
+	}
+}
+
+// dashboardChanges describes the structural diff of the dashboards (see Dashboards) as semantic changes,
+// followed by the changes of the other fields of the dashboards, e.g. their metadata.
+func dashboardChanges(before, after map[string]any) []SemanticChange {
+	dashboardDiff := Dashboards(before, after)
+	var changes []SemanticChange
+	moved := false
+	for _, key := range sortedKeys(dashboardDiff.Panels) {
+		panel := dashboardDiff.Panels[key]
+		changes = append(changes, panelChanges(key, panel)...)
+		moved = moved || panel.Layout != nil
+	}
+	for _, name := range sortedKeys(dashboardDiff.Variables) {
+		changes = append(changes, variableChanges(name, dashboardDiff.Variables[name])...)
+	}
+	if moved || len(dashboardDiff.Layouts) > 0 {
+		changes = append(changes, SemanticChange{Type: LayoutChanged, Description: "the layout of the panels changed"})
+	}
+	fields := Compare(withoutKeys(before, nil, "spec"), withoutKeys(after, nil, "spec"))
+	fields = append(fields, prefixed("spec.display", dashboardDiff.Display)...)
+	fields = append(fields, prefixed("spec", dashboardDiff.Other)...)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return append(changes, fieldChanges(fields)...)
+}
+
+func panelChanges(key string, panel *PanelDiff) []SemanticChange {
+	name := panelName(key, panel.Title)
+	switch panel.Status {
+	case StatusRemoved:
+		return []SemanticChange{{Type: PanelRemoved, Target: key, Description: fmt.Sprintf("panel %s removed", name)}}
+	case StatusAdded:
+		return []SemanticChange{{Type: PanelAdded, Target: key, Description: fmt.Sprintf("panel %s added", name)}}
+	}
+	var changes []SemanticChange
+	for _, query := range panel.Queries {
+		changes = append(changes, queryChanges(key, name, query)...)
+	}
+	fields := prefixed("spec.display", panel.Display)
+	fields = append(fields, prefixed("spec.plugin", panel.Plugin)...)
+	fields = append(fields, panel.Other...)
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	for _, change := range fields {
+		changes = append(changes, SemanticChange{
+			Type:        PanelChanged,
+			Target:      key,
+			Description: fmt.Sprintf("panel %s: %s %s", name, change.Path, change.Operation),
+			Old:         change.Old,
+			New:         change.New,
+		})
+	}
+	return changes
+}
+
+func queryChanges(key string, name string, query QueryDiff) []SemanticChange {
+	switch query.Operation {
+	case Removed:
+		return []SemanticChange{{Type: QueryRemoved, Target: key, Description: fmt.Sprintf("panel %s: query %d removed", name, query.Index), Old: query.Old}}
+	case Added:
+		return []SemanticChange{{Type: QueryAdded, Target: key, Description: fmt.Sprintf("panel %s: query %d added", name, query.Index), New: query.New}}
+	}
+	var changes []SemanticChange
+	if query.Datasource != nil {
+		changes = append(changes, SemanticChange{
+			Type:        DatasourceRefChanged,
+			Target:      key,
+			Description: fmt.Sprintf("panel %s: query %d uses another datasource", name, query.Index),
+			Old:         query.Datasource.Old,
+			New:         query.Datasource.New,
+		})
+	}
+	if query.Old != nil || query.New != nil || len(query.Changes) > 0 {
+		changes = append(changes, SemanticChange{
+			Type:        QueryChanged,
+			Target:      key,
+			Description: fmt.Sprintf("panel %s: query %d changed", name, query.Index),
+			Old:         query.Old,
+			New:         query.New,
+		})
+	}
+	return changes
+}
+
+func variableChanges(name string, variable *VariableDiff) []SemanticChange {
+	switch variable.Status {
+	case StatusRemoved:
+		return []SemanticChange{{Type: VariableRemoved, Target: name, Description: fmt.Sprintf("variable '%s' removed", name)}}
+	case StatusAdded:
+		return []SemanticChange{{Type: VariableAdded, Target: name, Description: fmt.Sprintf("variable '%s' added", name)}}
+	}
+	changes := make([]SemanticChange, 0, len(variable.Changes))
+	for _, change := range variable.Changes {
+		changes = append(changes, SemanticChange{
+			Type:        VariableChanged,
+			Target:      name,
+			Description: fmt.Sprintf("variable '%s': %s %s", name, change.Path, change.Operation),
+			Old:         change.Old,
+			New:         change.New,
+		})
+	}
+	return changes
+}
+
+// prefixed returns the changes with their path prefixed, to make them relative to an enclosing object.
+func prefixed(prefix string, changes []Change) []Change {
+	result := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		switch {
+		case change.Path == "":
+			change.Path = prefix
+		case strings.HasPrefix(change.Path, "["):
+			change.Path = prefix + change.Path
+		default:
+			change.Path = prefix + "." + change.Path
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+func datasourceChanges(before, after map[string]any) []SemanticChange {
+	var changes []SemanticChange
+	beforeURL := datasourceURL(before)
+	afterURL := datasourceURL(after)
+	if beforeURL != afterURL {
+		changes = append(changes, SemanticChange{Type: DatasourceURLChanged, Description: "the URL of the datasource changed", Old: beforeURL, New: afterURL})
+	}
+	for _, change := range Compare(before, after) {
+		if !slices.Contains(datasourceURLPaths, change.Path) {
+			changes = append(changes, fieldChanges([]Change{change})...)
+		}
+	}
+	return changes
+}
+
+func fieldChanges(changes []Change) []SemanticChange {
+	result := make([]SemanticChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, SemanticChange{
+			Type:        FieldChanged,
+			Target:      change.Path,
+			Description: fmt.Sprintf("%s %s", change.Path, change.Operation),
+			Old:         change.Old,
+			New:         change.New,
+		})
+	}
+	return result
+}
+
+func datasourceURL(datasource map[string]any) string {
+	for _, path := range datasourceURLPaths {
+		var value any = datasource
+		for _, key := range strings.Split(path, ".") {
+			value = asMap(value)[key]
+		}
+		if url, ok := value.(string); ok && url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// panelName returns the display name of the panel along with its key.
+func panelName(key string, title string) string {
+	if title != "" && title != key {
+		return fmt.Sprintf("'%s' (%s)", title, key)
+	}
+	return fmt.Sprintf("'%s'", key)
+}
+
+// queryExpression returns the query expression (e.g. the PromQL query) when the query plugin has one,
+// or the whole plugin spec otherwise.
+func queryExpression(query any) any {
+	pluginSpec := mapAt(mapAt(mapAt(asMap(query), "spec"), "plugin"), "spec")
+	if expression, ok := pluginSpec["query"]; ok {
+		return expression
+	}
+	return pluginSpec
+}
+
+// queryDatasource returns the datasource selector of the query, nil when it uses the default datasource.
+func queryDatasource(query any) any {
+	return mapAt(mapAt(mapAt(asMap(query), "spec"), "plugin"), "spec")["datasource"]
+}
+
+func indexVariables(variables []any) map[string]any {
+	result := make(map[string]any, len(variables))
+	for i, variable := range variables {
+		name, ok := mapAt(asMap(variable), "spec")["name"].(string)
+		if !ok {
+			name = fmt.Sprintf("#%d", i)
+		}
+		result[name] = variable
+	}
+	return result
+}
+
+// withoutKeys returns a shallow copy of obj without the given keys in the map found at path.
+func withoutKeys(obj map[string]any, path []string, keys ...string) map[string]any {
+	result := make(map[string]any, len(obj))
+	for key, value := range obj {
+		result[key] = value
+	}
+	if len(path) > 0 {
+		if child, ok := obj[path[0]].(map[string]any); ok {
+			result[path[0]] = withoutKeys(child, path[1:], keys...)
+		}
+		return result
+	}
+	for _, key := range keys {
+		delete(result, key)
+	}
+	return result
+}
+
+func asMap(value any) map[string]any {
+	if m, ok := value.(map[string]any); ok {
+		return m
+	}
+	return nil
+}
+
+func mapAt(obj map[string]any, key string) map[string]any {
+	return asMap(obj[key])
+}
+
+func sliceAt(obj map[string]any, key string) []any {
+	if s, ok := obj[key].([]any); ok {
+		return s
+	}
+	return nil
+}
+
+func sortedKeys[T any](maps ...map[string]T) []string {
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,322 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package generate builds Perses dashboards from a high-level description of a service.
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+)
+
+type Pattern string
+
+const (
+	// PatternRED charts the rate, the errors and the duration of the requests served by the service.
+	PatternRED Pattern = "red"
+	// PatternUSE charts the utilization and the saturation of the resources used by the process of the service.
+	PatternUSE Pattern = "use"
+	// PatternGoRuntime charts the metrics of the Go runtime.
+	PatternGoRuntime Pattern = "go_runtime"
+)
+
+// Patterns lists the supported patterns.
+var Patterns = []Pattern{PatternRED, PatternUSE, PatternGoRuntime}
+
+const (
+	defaultServiceLabel    = "job"
+	defaultRequestsMetric  = "http_requests_total"
+	defaultDurationMetric  = "http_request_duration_seconds"
+	defaultStatusLabel     = "code"
+	defaultDuration        = "1h"
+	defaultRefreshInterval = "30s"
+
+	panelWidth  = 12
+	panelHeight = 8
+	gridColumns = 24
+)
+
+// Spec describes the dashboard to generate.
+type Spec struct {
+	// Service is the name of the service, matched against ServiceLabel in every query.
+	Service string
+	// Project is the project of the dashboard.
+	Project string
+	// Name is the name of the dashboard. It defaults to the name of the service.
+	Name string
+	// DisplayName is the title of the dashboard. It defaults to the name of the service.
+	DisplayName string
+	// Patterns are the standard patterns to chart. Each pattern is a row of panels.
+	Patterns []Pattern
+	// MetricFamilies are additional metrics to chart, one panel per metric in a row of their own.
+	MetricFamilies []string
+	// Datasource is the name of the Prometheus datasource. The default Prometheus datasource is used when empty.
+	Datasource string
+	// ServiceLabel is the label identifying the service in the metrics. It defaults to "job".
+	ServiceLabel string
+	// Variables are the labels the dashboard can be filtered by, e.g. "namespace" or "instance".
+	Variables []string
+	// RequestsMetric is the counter of the requests used by the RED pattern. It defaults to "http_requests_total".
+	RequestsMetric string
+	// DurationMetric is the histogram of the request durations used by the RED pattern, without the _bucket suffix.
+	// It defaults to "http_request_duration_seconds".
+	DurationMetric string
+	// StatusLabel is the label of the requests counter holding the status code. It defaults to "code".
+	StatusLabel string
+}
+
+var (
+	validName   = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	invalidName = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+	validLabel  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	validMetric = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+)
+
+func (s *Spec) verify() error {
+	if strings.TrimSpace(s.Service) == "" {
+		return fmt.Errorf("service is required")
+	}
+	if s.Name == "" {
+		s.Name = strings.Trim(invalidName.ReplaceAllString(s.Service, "-"), "-")
+	}
+	if !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid dashboard name %q", s.Name)
+	}
+	if s.DisplayName == "" {
+		s.DisplayName = s.Service
+	}
+	if len(s.Patterns) == 0 && len(s.MetricFamilies) == 0 {
+		return fmt.Errorf("at least one pattern or metric family is required")
+	}
+	for _, pattern := range s.Patterns {
+		if !slices.Contains(Patterns, pattern) {
+			return fmt.Errorf("unknown pattern %q. valid patterns are: %s", pattern, strings.Join(patternNames(), ", "))
+		}
+	}
+	if s.ServiceLabel == "" {
+		s.ServiceLabel = defaultServiceLabel
+	}
+	if s.RequestsMetric == "" {
+		s.RequestsMetric = defaultRequestsMetric
+	}
+	if s.DurationMetric == "" {
+		s.DurationMetric = defaultDurationMetric
+	}
+	s.DurationMetric = strings.TrimSuffix(s.DurationMetric, "_bucket")
+	if s.StatusLabel == "" {
+		s.StatusLabel = defaultStatusLabel
+	}
+	for _, label := range append([]string{s.ServiceLabel, s.StatusLabel}, s.Variables...) {
+		if !validLabel.MatchString(label) {
+			return fmt.Errorf("invalid label name %q", label)
+		}
+	}
+	for _, metric := range append([]string{s.RequestsMetric, s.DurationMetric}, s.MetricFamilies...) {
+		if !validMetric.MatchString(metric) {
+			return fmt.Errorf("invalid metric name %q", metric)
+		}
+	}
+	return nil
+}
+
+func patternNames() []string {
+	names := make([]string, 0, len(Patterns))
+	for _, pattern := range Patterns {
+		names = append(names, string(pattern))
+	}
+	return names
+}
+
+// Dashboard returns the dashboard described by the spec: a variable per label of Variables, then a row of panels
+// per pattern and a row for the metric families. Every panel has a title, a description, a unit and a legend.
+func Dashboard(spec Spec) (*v1.Dashboard, error) {
+	if err := spec.verify(); err != nil {
+		return nil, err
+	}
+	builder := &builder{spec: spec, dashboard: &v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata: v1.Metadata{Name: spec.Name},
+			ProjectMetadataWrapper: v1.ProjectMetadataWrapper{
+				Project: spec.Project,
+			},
+		},
+		Spec: v1.DashboardSpec{
+			Display:         &common.Display{Name: spec.DisplayName, Description: fmt.Sprintf("Generated dashboard of the service %s", spec.Service)},
+			Panels:          make(map[string]*v1.Panel),
+			Layouts:         []dashboard.Layout{},
+			Duration:        defaultDuration,
+			RefreshInterval: defaultRefreshInterval,
+		},
+	}}
+
+	for _, label := range spec.Variables {
+		builder.addVariable(label)
+	}
+	for _, pattern := range spec.Patterns {
+		switch pattern {
+		case PatternRED:
+			builder.addRow("Requests (RED)", builder.redPanels())
+		case PatternUSE:
+			builder.addRow("Resources (USE)", builder.usePanels())
+		case PatternGoRuntime:
+			builder.addRow("Go runtime", builder.goRuntimePanels())
+		}
+	}
+	if len(spec.MetricFamilies) > 0 {
+		builder.addRow("Metrics", builder.metricFamilyPanels())
+	}
+	return builder.validate()
+}
+
+type panelDefinition struct {
+	id          string
+	title       string
+	description string
+	unit        string
+	queries     []queryDefinition
+}
+
+type queryDefinition struct {
+	expression string
+	legend     string
+}
+
+type builder struct {
+	spec      Spec
+	dashboard *v1.Dashboard
+}
+
+// selector returns the label matchers selecting the series of the service, filtered by the variables.
+func (b *builder) selector(extra ...string) string {
+	matchers := []string{fmt.Sprintf("%s=%q", b.spec.ServiceLabel, b.spec.Service)}
+	for _, label := range b.spec.Variables {
+		matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, label, label))
+	}
+	return "{" + strings.Join(append(matchers, extra...), ", ") + "}"
+}
+
+func (b *builder) datasourceSelector() map[string]any {
+	selector := map[string]any{"kind": "PrometheusDatasource"}
+	if b.spec.Datasource != "" {
+		selector["name"] = b.spec.Datasource
+	}
+	return selector
+}
+
+func (b *builder) addVariable(label string) {
+	// the matchers of the previous variables are applied, so that the values depend on the ones selected above.
+	matchers := []string{fmt.Sprintf("%s=%q", b.spec.ServiceLabel, b.spec.Service)}
+	for _, previous := range b.dashboard.Spec.Variables {
+		name := previous.Spec.GetName()
+		matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, name, name))
+	}
+	b.dashboard.Spec.Variables = append(b.dashboard.Spec.Variables, dashboard.Variable{
+		Kind: variable.KindList,
+		Spec: &dashboard.ListVariableSpec{
+			Name: label,
+			ListSpec: variable.ListSpec{
+				Display:       &variable.Display{Name: label},
+				AllowAllValue: true,
+				AllowMultiple: true,
+				Plugin: common.Plugin{
+					Kind: "PrometheusLabelValuesVariable",
+					Spec: map[string]any{
+						"datasource": b.datasourceSelector(),
+						"labelName":  label,
+						"matchers":   []any{"{" + strings.Join(matchers, ", ") + "}"},
+					},
+				},
+			},
+		},
+	})
+}
+
+// addRow adds a collapsible row with the panels, laid out two per line.
+func (b *builder) addRow(title string, panels []panelDefinition) {
+	items := make([]dashboard.GridItem, 0, len(panels))
+	for i, definition := range panels {
+		b.dashboard.Spec.Panels[definition.id] = b.panel(definition)
+		items = append(items, dashboard.GridItem{
+			X:       (i * panelWidth) % gridColumns,
+			Y:       (i * panelWidth) / gridColumns * panelHeight,
+			Width:   panelWidth,
+			Height:  panelHeight,
+			Content: &common.JSONRef{Ref: fmt.Sprintf("#/spec/panels/%s", definition.id)},
+		})
+	}
+	b.dashboard.Spec.Layouts = append(b.dashboard.Spec.Layouts, dashboard.Layout{
+		Kind: dashboard.KindGridLayout,
+		Spec: dashboard.GridLayoutSpec{
+			Display: &dashboard.GridLayoutDisplay{
+				Title:    title,
+				Collapse: &dashboard.GridLayoutCollapse{Open: true},
+			},
+			Items: items,
+		},
+	})
+}
+
+func (b *builder) panel(definition panelDefinition) *v1.Panel {
+	queries := make([]v1.Query, 0, len(definition.queries))
+	for _, query := range definition.queries {
+		queries = append(queries, v1.Query{
+			Kind: "TimeSeriesQuery",
+			Spec: v1.QuerySpec{
+				Plugin: common.Plugin{
+					Kind: "PrometheusTimeSeriesQuery",
+					Spec: map[string]any{
+						"datasource":       b.datasourceSelector(),
+						"query":            query.expression,
+						"seriesNameFormat": query.legend,
+					},
+				},
+			},
+		})
+	}
+	return &v1.Panel{
+		Kind: "Panel",
+		Spec: v1.PanelSpec{
+			Display: &v1.PanelDisplay{Name: definition.title, Description: definition.description},
+			Plugin: common.Plugin{
+				Kind: "TimeSeriesChart",
+				Spec: map[string]any{
+					"legend": map[string]any{"position": "bottom", "mode": "list"},
+					"yAxis":  map[string]any{"format": map[string]any{"unit": definition.unit}},
+				},
+			},
+			Queries: queries,
+		},
+	}
+}
+
+// validate checks the dashboard like Perses does when it is created, by decoding its JSON.
+func (b *builder) validate() (*v1.Dashboard, error) {
+	data, err := json.Marshal(b.dashboard)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal the generated dashboard: %w", err)
+	}
+	var result v1.Dashboard
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("the generated dashboard is invalid: %w", err)
+	}
+	return &result, nil
+}
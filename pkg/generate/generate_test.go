@@ -0,0 +1,236 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/promql"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard(t *testing.T) {
+	testSuite := []struct {
+		title  string
+		spec   Spec
+		rows   []string
+		panels []string
+	}{
+		{
+			title:  "RED",
+			spec:   Spec{Service: "checkout", Patterns: []Pattern{PatternRED}},
+			rows:   []string{"Requests (RED)"},
+			panels: []string{"error_ratio", "request_duration", "request_rate"},
+		},
+		{
+			title:  "USE",
+			spec:   Spec{Service: "checkout", Patterns: []Pattern{PatternUSE}},
+			rows:   []string{"Resources (USE)"},
+			panels: []string{"cpu_usage", "file_descriptors", "memory_usage"},
+		},
+		{
+			title:  "Go runtime",
+			spec:   Spec{Service: "checkout", Patterns: []Pattern{PatternGoRuntime}},
+			rows:   []string{"Go runtime"},
+			panels: []string{"gc_duration", "goroutines", "heap_memory", "os_threads"},
+		},
+		{
+			title: "metric families",
+			spec: Spec{Service: "checkout", MetricFamilies: []string{
+				"orders_total", "payment_duration_seconds_bucket", "queue_size", "cache:hit_ratio", "orders_total",
+			}},
+			rows:   []string{"Metrics"},
+			panels: []string{"metric_cache_hit_ratio", "metric_orders_total", "metric_payment_duration_seconds_bucket", "metric_queue_size"},
+		},
+		{
+			title: "every pattern with variables and a datasource",
+			spec: Spec{
+				Service:        "checkout",
+				Patterns:       []Pattern{PatternRED, PatternUSE, PatternGoRuntime},
+				MetricFamilies: []string{"orders_total"},
+				Variables:      []string{"namespace", "instance"},
+				Datasource:     "thanos",
+				RequestsMetric: "grpc_server_handled_total",
+				DurationMetric: "grpc_server_handling_seconds_bucket",
+				StatusLabel:    "grpc_code",
+			},
+			rows: []string{"Requests (RED)", "Resources (USE)", "Go runtime", "Metrics"},
+			panels: []string{
+				"cpu_usage", "error_ratio", "file_descriptors", "gc_duration", "goroutines", "heap_memory",
+				"memory_usage", "metric_orders_total", "os_threads", "request_duration", "request_rate",
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			dashboardObj, err := Dashboard(test.spec)
+			require.NoError(t, err)
+
+			// the dashboard is valid for Perses.
+			data, err := json.Marshal(dashboardObj)
+			require.NoError(t, err)
+			var decoded v1.Dashboard
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, "checkout", decoded.Metadata.Name)
+
+			var rows []string
+			referenced := map[string]int{}
+			for _, layout := range decoded.Spec.Layouts {
+				spec, ok := layout.Spec.(*dashboard.GridLayoutSpec)
+				require.True(t, ok)
+				rows = append(rows, spec.Display.Title)
+				for _, item := range spec.Items {
+					referenced[strings.TrimPrefix(item.Content.Ref, "#/spec/panels/")]++
+				}
+			}
+			assert.Equal(t, test.rows, rows)
+
+			ids := make([]string, 0, len(decoded.Spec.Panels))
+			for id, panel := range decoded.Spec.Panels {
+				ids = append(ids, id)
+				assert.Equal(t, 1, referenced[id], "panel %s is laid out once", id)
+				assert.NotEmpty(t, panel.Spec.Display.Name)
+				assert.NotEmpty(t, panel.Spec.Display.Description)
+				assert.NotEmpty(t, panel.Spec.Queries)
+			}
+			sort.Strings(ids)
+			assert.Equal(t, test.panels, ids)
+			assert.Len(t, referenced, len(ids), "every item of the layout is a panel")
+
+			queries := promql.Queries(&decoded)
+			assert.Len(t, queries, countQueries(&decoded))
+			for _, query := range queries {
+				_, err := promql.ParseExpr(query.Expression)
+				assert.NoError(t, err, "%s: %s", query.Location, query.Expression)
+				assert.Equal(t, test.spec.Datasource, query.Datasource)
+			}
+			require.Len(t, decoded.Spec.Variables, len(test.spec.Variables))
+			for _, variable := range decoded.Spec.Variables {
+				spec, ok := variable.Spec.(*dashboard.ListVariableSpec)
+				require.True(t, ok)
+				pluginSpec := spec.Plugin.Spec.(map[string]any)
+				for _, matcher := range pluginSpec["matchers"].([]any) {
+					_, err := promql.ParseExpr(matcher.(string))
+					assert.NoError(t, err, "variable %s: %s", spec.Name, matcher)
+				}
+			}
+		})
+	}
+}
+
+// countQueries returns the number of queries of the panels of the dashboard.
+func countQueries(dashboardObj *v1.Dashboard) int {
+	count := 0
+	for _, panel := range dashboardObj.Spec.Panels {
+		count += len(panel.Spec.Queries)
+	}
+	return count
+}
+
+func TestDashboardQueries(t *testing.T) {
+	dashboardObj, err := Dashboard(Spec{
+		Service:        "checkout",
+		Patterns:       []Pattern{PatternRED},
+		MetricFamilies: []string{"orders_total", "payment_duration_seconds_bucket", "queue_size"},
+		Variables:      []string{"namespace"},
+	})
+	require.NoError(t, err)
+	expressions := map[string]string{}
+	for _, query := range promql.Queries(dashboardObj) {
+		expressions[query.Location] = query.Expression
+	}
+	assert.Equal(t, map[string]string{
+		"panel 'error_ratio' query 0":                            `sum(rate(http_requests_total{job="checkout", namespace=~"$namespace", code=~"5.."}[$__rate_interval])) / sum(rate(http_requests_total{job="checkout", namespace=~"$namespace"}[$__rate_interval]))`,
+		"panel 'request_duration' query 0":                       `histogram_quantile(0.5, sum by (le) (rate(http_request_duration_seconds_bucket{job="checkout", namespace=~"$namespace"}[$__rate_interval])))`,
+		"panel 'request_duration' query 1":                       `histogram_quantile(0.9, sum by (le) (rate(http_request_duration_seconds_bucket{job="checkout", namespace=~"$namespace"}[$__rate_interval])))`,
+		"panel 'request_duration' query 2":                       `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{job="checkout", namespace=~"$namespace"}[$__rate_interval])))`,
+		"panel 'request_rate' query 0":                           `sum by (code) (rate(http_requests_total{job="checkout", namespace=~"$namespace"}[$__rate_interval]))`,
+		"panel 'metric_orders_total' query 0":                    `sum by (instance) (rate(orders_total{job="checkout", namespace=~"$namespace"}[$__rate_interval]))`,
+		"panel 'metric_payment_duration_seconds_bucket' query 0": `histogram_quantile(0.99, sum by (le) (rate(payment_duration_seconds_bucket{job="checkout", namespace=~"$namespace"}[$__rate_interval])))`,
+		"panel 'metric_queue_size' query 0":                      `sum by (instance) (queue_size{job="checkout", namespace=~"$namespace"})`,
+	}, expressions)
+}
+
+func TestVerify(t *testing.T) {
+	testSuite := []struct {
+		title string
+		spec  Spec
+		err   string
+	}{
+		{
+			title: "missing service",
+			spec:  Spec{Service: " ", Patterns: []Pattern{PatternRED}},
+			err:   "service is required",
+		},
+		{
+			title: "invalid dashboard name",
+			spec:  Spec{Service: "checkout", Name: "check out", Patterns: []Pattern{PatternRED}},
+			err:   `invalid dashboard name "check out"`,
+		},
+		{
+			title: "nothing to chart",
+			spec:  Spec{Service: "checkout"},
+			err:   "at least one pattern or metric family is required",
+		},
+		{
+			title: "unknown pattern",
+			spec:  Spec{Service: "checkout", Patterns: []Pattern{"golden_signals"}},
+			err:   `unknown pattern "golden_signals". valid patterns are: red, use, go_runtime`,
+		},
+		{
+			title: "invalid service label",
+			spec:  Spec{Service: "checkout", Patterns: []Pattern{PatternRED}, ServiceLabel: "service-name"},
+			err:   `invalid label name "service-name"`,
+		},
+		{
+			title: "invalid variable",
+			spec:  Spec{Service: "checkout", Patterns: []Pattern{PatternRED}, Variables: []string{"0zone"}},
+			err:   `invalid label name "0zone"`,
+		},
+		{
+			title: "invalid metric family",
+			spec:  Spec{Service: "checkout", MetricFamilies: []string{`up{job="api"}`}},
+			err:   `invalid metric name "up{job=\"api\"}"`,
+		},
+		{
+			title: "invalid requests metric",
+			spec:  Spec{Service: "checkout", Patterns: []Pattern{PatternRED}, RequestsMetric: "http-requests"},
+			err:   `invalid metric name "http-requests"`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.spec.verify()
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+}
+
+func TestVerifyDefaults(t *testing.T) {
+	spec := Spec{Service: "checkout api/v2", Patterns: []Pattern{PatternRED}, DurationMetric: "rpc_duration_seconds_bucket"}
+	require.NoError(t, spec.verify())
+	assert.Equal(t, "checkout-api-v2", spec.Name)
+	assert.Equal(t, "checkout api/v2", spec.DisplayName)
+	assert.Equal(t, "job", spec.ServiceLabel)
+	assert.Equal(t, "code", spec.StatusLabel)
+	assert.Equal(t, "http_requests_total", spec.RequestsMetric)
+	assert.Equal(t, "rpc_duration_seconds", spec.DurationMetric, "the _bucket suffix is removed")
+}
@@ -0,0 +1,197 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const rateInterval = "[$__rate_interval]"
+
+func (b *builder) redPanels() []panelDefinition {
+	requests := b.spec.RequestsMetric
+	status := b.spec.StatusLabel
+	quantile := func(q string, legend string) queryDefinition {
+		return queryDefinition{
+			expression: fmt.Sprintf("histogram_quantile(%s, sum by (le) (rate(%s_bucket%s%s)))", q, b.spec.DurationMetric, b.selector(), rateInterval),
+			legend:     legend,
+		}
+	}
+	return []panelDefinition{
+		{
+			id:          "request_rate",
+			title:       "Request rate",
+			description: fmt.Sprintf("Requests per second served by %s, by status code", b.spec.Service),
+			unit:        "requests/sec",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (%s) (rate(%s%s%s))", status, requests, b.selector(), rateInterval),
+				legend:     fmt.Sprintf("{{%s}}", status),
+			}},
+		},
+		{
+			id:          "error_ratio",
+			title:       "Error ratio",
+			description: fmt.Sprintf("Share of the requests served by %s that failed with a 5xx status code", b.spec.Service),
+			unit:        "percent-decimal",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum(rate(%s%s%s)) / sum(rate(%s%s%s))",
+					requests, b.selector(fmt.Sprintf(`%s=~"5.."`, status)), rateInterval, requests, b.selector(), rateInterval),
+				legend: "errors",
+			}},
+		},
+		{
+			id:          "request_duration",
+			title:       "Request duration",
+			description: fmt.Sprintf("Median, 90th and 99th percentiles of the duration of the requests served by %s", b.spec.Service),
+			unit:        "seconds",
+			queries:     []queryDefinition{quantile("0.5", "p50"), quantile("0.9", "p90"), quantile("0.99", "p99")},
+		},
+	}
+}
+
+func (b *builder) usePanels() []panelDefinition {
+	return []panelDefinition{
+		{
+			id:          "cpu_usage",
+			title:       "CPU usage",
+			description: fmt.Sprintf("CPU time used per second by each instance of %s (1 is a full core)", b.spec.Service),
+			unit:        "decimal",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (rate(process_cpu_seconds_total%s%s))", b.selector(), rateInterval),
+				legend:     "{{instance}}",
+			}},
+		},
+		{
+			id:          "memory_usage",
+			title:       "Memory usage",
+			description: fmt.Sprintf("Resident memory of each instance of %s", b.spec.Service),
+			unit:        "bytes",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (process_resident_memory_bytes%s)", b.selector()),
+				legend:     "{{instance}}",
+			}},
+		},
+		{
+			id:          "file_descriptors",
+			title:       "File descriptors saturation",
+			description: fmt.Sprintf("Share of the maximum number of open file descriptors used by each instance of %s", b.spec.Service),
+			unit:        "percent-decimal",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (process_open_fds%s) / sum by (instance) (process_max_fds%s)", b.selector(), b.selector()),
+				legend:     "{{instance}}",
+			}},
+		},
+	}
+}
+
+func (b *builder) goRuntimePanels() []panelDefinition {
+	return []panelDefinition{
+		{
+			id:          "goroutines",
+			title:       "Goroutines",
+			description: fmt.Sprintf("Number of goroutines of each instance of %s", b.spec.Service),
+			unit:        "decimal",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (go_goroutines%s)", b.selector()),
+				legend:     "{{instance}}",
+			}},
+		},
+		{
+			id:          "heap_memory",
+			title:       "Heap memory",
+			description: fmt.Sprintf("Memory allocated on the heap by each instance of %s", b.spec.Service),
+			unit:        "bytes",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (go_memstats_heap_alloc_bytes%s)", b.selector()),
+				legend:     "{{instance}}",
+			}},
+		},
+		{
+			id:          "gc_duration",
+			title:       "GC pause duration",
+			description: fmt.Sprintf("Average duration of the garbage collection pauses of each instance of %s", b.spec.Service),
+			unit:        "seconds",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (rate(go_gc_duration_seconds_sum%s%s)) / sum by (instance) (rate(go_gc_duration_seconds_count%s%s))",
+					b.selector(), rateInterval, b.selector(), rateInterval),
+				legend: "{{instance}}",
+			}},
+		},
+		{
+			id:          "os_threads",
+			title:       "OS threads",
+			description: fmt.Sprintf("Number of OS threads created by each instance of %s", b.spec.Service),
+			unit:        "decimal",
+			queries: []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (go_threads%s)", b.selector()),
+				legend:     "{{instance}}",
+			}},
+		},
+	}
+}
+
+// metricFamilyPanels returns a panel per metric family. The query depends on the suffix of the metric:
+// the counters (_total, _count, _sum) are charted as a rate, the histogram buckets as their 99th percentile,
+// and the other metrics as gauges.
+func (b *builder) metricFamilyPanels() []panelDefinition {
+	panels := make([]panelDefinition, 0, len(b.spec.MetricFamilies))
+	for _, metric := range b.spec.MetricFamilies {
+		id := "metric_" + strings.ReplaceAll(metric, ":", "_")
+		if slices.ContainsFunc(panels, func(p panelDefinition) bool { return p.id == id }) {
+			continue
+		}
+		definition := panelDefinition{id: id, title: metric, unit: metricUnit(metric)}
+		switch {
+		case strings.HasSuffix(metric, "_total") || strings.HasSuffix(metric, "_count") || strings.HasSuffix(metric, "_sum"):
+			definition.description = fmt.Sprintf("Per-second rate of %s for each instance of %s", metric, b.spec.Service)
+			definition.unit = "counts/sec"
+			if strings.Contains(metric, "_bytes") {
+				definition.unit = "bytes/sec"
+			}
+			definition.queries = []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (rate(%s%s%s))", metric, b.selector(), rateInterval),
+				legend:     "{{instance}}",
+			}}
+		case strings.HasSuffix(metric, "_bucket"):
+			definition.description = fmt.Sprintf("99th percentile of %s for %s", strings.TrimSuffix(metric, "_bucket"), b.spec.Service)
+			definition.queries = []queryDefinition{{
+				expression: fmt.Sprintf("histogram_quantile(0.99, sum by (le) (rate(%s%s%s)))", metric, b.selector(), rateInterval),
+				legend:     "p99",
+			}}
+		default:
+			definition.description = fmt.Sprintf("Value of %s for each instance of %s", metric, b.spec.Service)
+			definition.queries = []queryDefinition{{
+				expression: fmt.Sprintf("sum by (instance) (%s%s)", metric, b.selector()),
+				legend:     "{{instance}}",
+			}}
+		}
+		panels = append(panels, definition)
+	}
+	return panels
+}
+
+// metricUnit guesses the unit of a metric from the base unit in its name, following the Prometheus naming conventions.
+func metricUnit(metric string) string {
+	switch {
+	case strings.Contains(metric, "_seconds"):
+		return "seconds"
+	case strings.Contains(metric, "_bytes"):
+		return "bytes"
+	case strings.HasSuffix(metric, "_ratio"):
+		return "percent-decimal"
+	}
+	return "decimal"
+}
@@ -0,0 +1,294 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// ApplyOrder lists the kinds that can be applied, in dependency order: the projects first,
+// then the datasources and the variables that the dashboards use, and the roles before their bindings.
+// The objects are pruned in the reverse order.
+var ApplyOrder = []v1.Kind{
+	v1.KindProject,
+	v1.KindGlobalDatasource,
+	v1.KindGlobalVariable,
+	v1.KindGlobalRole,
+	v1.KindGlobalRoleBinding,
+	v1.KindDatasource,
+	v1.KindVariable,
+	v1.KindDashboard,
+	v1.KindRole,
+	v1.KindRoleBinding,
+}
+
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+	ActionDelete    Action = "delete"
+)
+
+// Result reports what has been done (or would be done in dry-run) for an object.
+type Result struct {
+	Kind    v1.Kind `json:"kind"`
+	Project string  `json:"project,omitempty"`
+	Name    string  `json:"name"`
+	Action  Action  `json:"action"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type ApplyOptions struct {
+	// Prune deletes the live objects that are not in the applied set. Only the kinds present in the set are pruned,
+	// within the projects present in the set. Projects themselves are never pruned, and the global objects only with PruneGlobal.
+	Prune bool
+	// PruneGlobal lets Prune delete the global objects of the kinds present in the set (e.g. every GlobalDatasource that is not in the set).
+	// The global objects are shared by every project, so they are usually not all defined in the same set.
+	PruneGlobal bool
+	// DryRun computes the results without modifying anything.
+	DryRun bool
+}
+
+// scope identifies the objects of one kind in one project (or the global objects of one kind).
+type scope struct {
+	kind    v1.Kind
+	project string
+}
+
+// Apply creates or updates every object, in dependency order, and returns the result for each of them.
+// An error is returned only when the set itself is invalid; the failure of an object is reported in its result.
+func Apply(client apiClient.ClientInterface, list []modelAPI.Entity, options ApplyOptions) ([]Result, error) {
+	sorted, err := sortForApply(list)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(sorted))
+	for _, obj := range sorted {
+		results = append(results, applyObject(client, obj, options.DryRun))
+	}
+	if options.Prune {
+		results = append(results, prune(client, sorted, options.PruneGlobal, options.DryRun)...)
+	}
+	return results, nil
+}
+
+// sortForApply verifies the set of objects and sorts it in dependency order.
+func sortForApply(list []modelAPI.Entity) ([]modelAPI.Entity, error) {
+	seen := make(map[string]bool)
+	for _, obj := range list {
+		kind := objects.Kind(obj)
+		if !slices.Contains(ApplyOrder, kind) {
+			return nil, fmt.Errorf("kind %s cannot be applied", kind)
+		}
+		if !v1.IsGlobal(kind) && objects.Project(obj) == "" {
+			return nil, fmt.Errorf("%s must define metadata.project", objects.String(obj))
+		}
+		key := objects.String(obj)
+		if seen[key] {
+			return nil, fmt.Errorf("%s is defined more than once", key)
+		}
+		seen[key] = true
+	}
+	sorted := slices.Clone(list)
+	slices.SortStableFunc(sorted, func(a, b modelAPI.Entity) int {
+		return slices.Index(ApplyOrder, objects.Kind(a)) - slices.Index(ApplyOrder, objects.Kind(b))
+	})
+	return sorted, nil
+}
+
+func applyObject(client apiClient.ClientInterface, obj modelAPI.Entity, dryRun bool) Result {
+	result := Result{Kind: objects.Kind(obj), Project: objects.Project(obj), Name: objects.Name(obj)}
+	accessor, err := objects.ForObject(client, obj)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	existing, err := accessor.Get(result.Name)
+	switch {
+	case objects.IsNotFound(err):
+		result.Action = ActionCreate
+	case err != nil:
+		result.Error = err.Error()
+		return result
+	default:
+		unchanged, compareErr := isUnchanged(existing, obj)
+		if compareErr != nil {
+			result.Error = compareErr.Error()
+			return result
+		}
+		result.Action = ActionUpdate
+		if unchanged {
+			result.Action = ActionUnchanged
+		}
+	}
+	if dryRun || result.Action == ActionUnchanged {
+		return result
+	}
+	if result.Action == ActionCreate {
+		_, err = accessor.Create(obj)
+	} else {
+		_, err = accessor.Update(obj)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// isUnchanged returns true when the live object is the same as the definition obj. The metadata managed by Perses is ignored,
+// and the definition goes through a round-trip in the model of Perses, so that it gets the defaults set by the server (e.g. the duration
+// of a dashboard). Any other difference counts, including a field removed from the definition but still set on the live object.
+func isUnchanged(live modelAPI.Entity, obj modelAPI.Entity) (bool, error) {
+	liveMap, err := objects.Normalize(live)
+	if err != nil {
+		return false, err
+	}
+	withDefaults, err := objects.Copy(obj)
+	if err != nil {
+		return false, err
+	}
+	declared, err := objects.Normalize(withDefaults)
+	if err != nil {
+		return false, err
+	}
+	return equivalent(liveMap, declared), nil
+}
+
+// equivalent returns true when a and b hold the same values. Null, empty and missing values are equivalent,
+// as the objects returned by Perses don't always keep the empty fields of their definition.
+func equivalent(a any, b any) bool {
+	if isEmpty(a) && isEmpty(b) {
+		return true
+	}
+	switch aValue := a.(type) {
+	case map[string]any:
+		bMap, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range aValue {
+			if !equivalent(value, bMap[key]) {
+				return false
+			}
+		}
+		for key, value := range bMap {
+			if _, ok := aValue[key]; !ok && !isEmpty(value) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bSlice, ok := b.([]any)
+		if !ok || len(aValue) != len(bSlice) {
+			return false
+		}
+		for i := range aValue {
+			if !equivalent(aValue[i], bSlice[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// extraObjects are the live objects of a scope that are not part of the applied set.
+type extraObjects struct {
+	scope
+	accessor objects.Accessor
+	names    []string
+	err      error
+}
+
+// findExtraObjects lists the live objects that are not part of the set, within the kinds and projects present in the set.
+// The projects themselves are ignored, and the global kinds unless global is set. The result is sorted in reverse dependency order.
+func findExtraObjects(client apiClient.ClientInterface, list []modelAPI.Entity, global bool) []extraObjects {
+	keep := make(map[scope][]string)
+	var scopes []scope
+	for _, obj := range list {
+		kind := objects.Kind(obj)
+		if kind == v1.KindProject || (v1.IsGlobal(kind) && !global) {
+			continue
+		}
+		s := scope{kind: kind, project: objects.Project(obj)}
+		if _, ok := keep[s]; !ok {
+			scopes = append(scopes, s)
+		}
+		keep[s] = append(keep[s], objects.Name(obj))
+	}
+	// The dependent objects come first, so that they are deleted before what they depend on.
+	slices.SortStableFunc(scopes, func(a, b scope) int {
+		return slices.Index(ApplyOrder, b.kind) - slices.Index(ApplyOrder, a.kind)
+	})
+
+	result := make([]extraObjects, 0, len(scopes))
+	for _, s := range scopes {
+		extra := extraObjects{scope: s}
+		extra.accessor, extra.err = objects.For(client, s.kind, s.project)
+		if extra.err == nil {
+			var live []modelAPI.Entity
+			live, extra.err = extra.accessor.List()
+			for _, obj := range live {
+				if name := objects.Name(obj); !slices.Contains(keep[s], name) {
+					extra.names = append(extra.names, name)
+				}
+			}
+		}
+		result = append(result, extra)
+	}
+	return result
+}
+
+func prune(client apiClient.ClientInterface, list []modelAPI.Entity, global bool, dryRun bool) []Result {
+	var results []Result
+	for _, extra := range findExtraObjects(client, list, global) {
+		if extra.err != nil {
+			results = append(results, Result{Kind: extra.kind, Project: extra.project, Action: ActionDelete, Error: extra.err.Error()})
+			continue
+		}
+		for _, name := range extra.names {
+			result := Result{Kind: extra.kind, Project: extra.project, Name: name, Action: ActionDelete}
+			if !dryRun {
+				if err := extra.accessor.Delete(name); err != nil {
+					result.Error = err.Error()
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
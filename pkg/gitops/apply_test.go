@@ -0,0 +1,303 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/persestest"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, def string) modelAPI.Entity {
+	return persestest.Decode(t, def)
+}
+
+func decodeAll(t *testing.T, defs ...string) []modelAPI.Entity {
+	list := make([]modelAPI.Entity, 0, len(defs))
+	for _, def := range defs {
+		list = append(list, decode(t, def))
+	}
+	return list
+}
+
+const (
+	project = `{"kind":"Project","metadata":{"name":"shop"}}`
+	// prometheus is the definition of a datasource, and livePrometheus the same datasource with the metadata managed by Perses.
+	prometheus     = `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://prometheus:9090"}}}}`
+	livePrometheus = `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop","version":3,"createdAt":"2026-01-01T00:00:00Z"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://prometheus:9090"}}}}`
+	thanos         = `{"kind":"Datasource","metadata":{"name":"thanos","project":"shop"},"spec":{"default":false,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://thanos:9090"}}}}`
+	loki           = `{"kind":"Datasource","metadata":{"name":"loki","project":"shop"},"spec":{"default":false,"plugin":{"kind":"LokiDatasource","spec":{"directUrl":"http://loki:3100"}}}}`
+	sharedGlobal   = `{"kind":"GlobalDatasource","metadata":{"name":"shared"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://shared:9090"}}}}`
+	otherGlobal    = `{"kind":"GlobalDatasource","metadata":{"name":"other"},"spec":{"default":false,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://other:9090"}}}}`
+)
+
+func TestSortForApply(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		defs    []string
+		kinds   []v1.Kind
+		wantErr bool
+	}{
+		{
+			title: "dependencies come first",
+			defs:  []string{prometheus, sharedGlobal, project},
+			kinds: []v1.Kind{"Project", "GlobalDatasource", "Datasource"},
+		},
+		{
+			title:   "missing project",
+			defs:    []string{strings.Replace(prometheus, `,"project":"shop"`, "", 1)},
+			wantErr: true,
+		},
+		{
+			title:   "object defined twice",
+			defs:    []string{prometheus, prometheus},
+			wantErr: true,
+		},
+		{
+			title:   "kind that can't be applied",
+			defs:    []string{`{"kind":"User","metadata":{"name":"alice"}}`},
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			sorted, err := sortForApply(decodeAll(t, test.defs...))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			kinds := make([]v1.Kind, 0, len(sorted))
+			for _, obj := range sorted {
+				kinds = append(kinds, objects.Kind(obj))
+			}
+			assert.Equal(t, test.kinds, kinds)
+		})
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	testSuite := []struct {
+		title      string
+		a          string
+		b          string
+		equivalent bool
+	}{
+		{
+			title:      "same values",
+			a:          `{"a":1,"b":{"c":"d"},"e":[1,2]}`,
+			b:          `{"a":1,"b":{"c":"d"},"e":[1,2]}`,
+			equivalent: true,
+		},
+		{
+			title: "changed value",
+			a:     `{"a":1,"b":{"c":"d"}}`,
+			b:     `{"a":1,"b":{"c":"e"}}`,
+		},
+		{
+			title: "key added",
+			a:     `{"a":1}`,
+			b:     `{"a":1,"b":"c"}`,
+		},
+		{
+			title: "key removed",
+			a:     `{"a":1,"b":{"c":"d","e":"f"}}`,
+			b:     `{"a":1,"b":{"c":"d"}}`,
+		},
+		{
+			title: "key removed from an element of a list",
+			a:     `{"e":[{"f":1,"g":"x"}]}`,
+			b:     `{"e":[{"f":1}]}`,
+		},
+		{
+			title: "element added to a list",
+			a:     `{"e":[1]}`,
+			b:     `{"e":[1,2]}`,
+		},
+		{
+			title: "element removed from a list",
+			a:     `{"e":[1,2]}`,
+			b:     `{"e":[1]}`,
+		},
+		{
+			title:      "null and empty values match missing values",
+			a:          `{"a":1,"f":[]}`,
+			b:          `{"a":1,"b":null,"c":{},"d":[]}`,
+			equivalent: true,
+		},
+		{
+			title: "type changed",
+			a:     `{"a":"1"}`,
+			b:     `{"a":1}`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var a, b any
+			require.NoError(t, json.Unmarshal([]byte(test.a), &a))
+			require.NoError(t, json.Unmarshal([]byte(test.b), &b))
+			assert.Equal(t, test.equivalent, equivalent(a, b))
+			assert.Equal(t, test.equivalent, equivalent(b, a))
+		})
+	}
+}
+
+func TestIsUnchanged(t *testing.T) {
+	testSuite := []struct {
+		title     string
+		live      string
+		def       string
+		unchanged bool
+	}{
+		{
+			title:     "metadata managed by Perses",
+			live:      livePrometheus,
+			def:       prometheus,
+			unchanged: true,
+		},
+		{
+			title:     "default set by Perses",
+			live:      `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop","version":2},"spec":{"duration":"1h","panels":{},"layouts":[]}}`,
+			def:       `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"panels":{},"layouts":[]}}`,
+			unchanged: true,
+		},
+		{
+			title: "field removed from the definition",
+			live:  `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"duration":"1h","refreshInterval":"30s","panels":{},"layouts":[]}}`,
+			def:   `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"panels":{},"layouts":[]}}`,
+		},
+		{
+			title: "header removed from the proxy",
+			live:  `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"proxy":{"kind":"HTTPProxy","spec":{"url":"http://prometheus:9090","headers":{"X-Scope-OrgID":"shop"}}}}}}}`,
+			def:   `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"proxy":{"kind":"HTTPProxy","spec":{"url":"http://prometheus:9090"}}}}}}`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			unchanged, err := isUnchanged(decode(t, test.live), decode(t, test.def))
+			require.NoError(t, err)
+			assert.Equal(t, test.unchanged, unchanged)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	changedThanos := strings.Replace(thanos, "http://thanos:9090", "http://thanos-query:9090", 1)
+	testSuite := []struct {
+		title   string
+		live    []string
+		defs    []string
+		options ApplyOptions
+		results []Result
+		writes  []string
+	}{
+		{
+			title: "create, update and leave unchanged",
+			live:  []string{project, livePrometheus, thanos},
+			defs:  []string{prometheus, changedThanos, loki},
+			results: []Result{
+				{Kind: "Datasource", Project: "shop", Name: "prometheus", Action: ActionUnchanged},
+				{Kind: "Datasource", Project: "shop", Name: "thanos", Action: ActionUpdate},
+				{Kind: "Datasource", Project: "shop", Name: "loki", Action: ActionCreate},
+			},
+			writes: []string{"PUT projects/shop/datasources/thanos", "POST projects/shop/datasources/loki"},
+		},
+		{
+			title: "dry run",
+			live:  []string{project, thanos},
+			defs:  []string{prometheus, changedThanos},
+			options: ApplyOptions{
+				DryRun: true,
+			},
+			results: []Result{
+				{Kind: "Datasource", Project: "shop", Name: "prometheus", Action: ActionCreate},
+				{Kind: "Datasource", Project: "shop", Name: "thanos", Action: ActionUpdate},
+			},
+		},
+		{
+			title:   "prune the objects of the project",
+			live:    []string{project, livePrometheus, thanos, sharedGlobal, otherGlobal},
+			defs:    []string{prometheus, sharedGlobal},
+			options: ApplyOptions{Prune: true},
+			results: []Result{
+				{Kind: "GlobalDatasource", Name: "shared", Action: ActionUnchanged},
+				{Kind: "Datasource", Project: "shop", Name: "prometheus", Action: ActionUnchanged},
+				{Kind: "Datasource", Project: "shop", Name: "thanos", Action: ActionDelete},
+			},
+			writes: []string{"DELETE projects/shop/datasources/thanos"},
+		},
+		{
+			title:   "prune the global objects on request",
+			live:    []string{project, livePrometheus, sharedGlobal, otherGlobal},
+			defs:    []string{prometheus, sharedGlobal},
+			options: ApplyOptions{Prune: true, PruneGlobal: true},
+			results: []Result{
+				{Kind: "GlobalDatasource", Name: "shared", Action: ActionUnchanged},
+				{Kind: "Datasource", Project: "shop", Name: "prometheus", Action: ActionUnchanged},
+				{Kind: "GlobalDatasource", Name: "other", Action: ActionDelete},
+			},
+			writes: []string{"DELETE globaldatasources/other"},
+		},
+		{
+			title:   "prune in dry run",
+			live:    []string{project, livePrometheus, thanos},
+			defs:    []string{prometheus},
+			options: ApplyOptions{Prune: true, DryRun: true},
+			results: []Result{
+				{Kind: "Datasource", Project: "shop", Name: "prometheus", Action: ActionUnchanged},
+				{Kind: "Datasource", Project: "shop", Name: "thanos", Action: ActionDelete},
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			store, client := persestest.New(t, test.live...)
+			results, err := Apply(client, decodeAll(t, test.defs...), test.options)
+			require.NoError(t, err)
+			assert.Equal(t, test.results, results)
+			assert.Equal(t, test.writes, store.Writes())
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changedThanos := strings.Replace(thanos, "http://thanos:9090", "http://thanos-query:9090", 1)
+	liveTempo := `{"kind":"Datasource","metadata":{"name":"tempo","project":"shop"},"spec":{"default":false,"plugin":{"kind":"TempoDatasource","spec":{"directUrl":"http://tempo:3200","timeout":"30s"}}}}`
+	tempo := `{"kind":"Datasource","metadata":{"name":"tempo","project":"shop"},"spec":{"default":false,"plugin":{"kind":"TempoDatasource","spec":{"directUrl":"http://tempo:3200"}}}}`
+	_, client := persestest.New(t, project, livePrometheus, thanos, loki, liveTempo)
+	diffs, err := Diff(client, decodeAll(t, prometheus, changedThanos, tempo))
+	require.NoError(t, err)
+
+	statuses := make(map[string]Status, len(diffs))
+	for _, diff := range diffs {
+		statuses[diff.Name] = diff.Status
+		if diff.Name == "tempo" {
+			require.Len(t, diff.Changes, 1)
+			assert.Equal(t, "spec.plugin.spec.timeout", diff.Changes[0].Target)
+		}
+	}
+	assert.Equal(t, map[string]Status{
+		"prometheus": StatusUnchanged,
+		"thanos":     StatusChanged,
+		"tempo":      StatusChanged,
+		"loki":       StatusOnlyLive,
+	}, statuses)
+}
@@ -0,0 +1,115 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"github.com/perses/mcp-server/pkg/diff"
+	"github.com/perses/mcp-server/pkg/objects"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Status string
+
+const (
+	// StatusOnlyLocal is the status of an object that doesn't exist on the server.
+	StatusOnlyLocal Status = "only_local"
+	// StatusOnlyLive is the status of an object that exists on the server but not in the set.
+	StatusOnlyLive  Status = "only_live"
+	StatusChanged   Status = "changed"
+	StatusUnchanged Status = "unchanged"
+)
+
+// ObjectDiff describes the drift between the definition of an object and the live object.
+type ObjectDiff struct {
+	Kind    v1.Kind               `json:"kind"`
+	Project string                `json:"project,omitempty"`
+	Name    string                `json:"name"`
+	Status  Status                `json:"status,omitempty"`
+	Changes []diff.SemanticChange `json:"changes,omitempty"`
+	Error   string                `json:"error,omitempty"`
+}
+
+// Diff compares every object of the set with the live object, ignoring the metadata managed by Perses.
+// An object is unchanged when the live object is the same as its definition (see isUnchanged).
+// The live objects of the same kinds and projects, and of the same global kinds, that are not in the set are reported as well.
+func Diff(client apiClient.ClientInterface, list []modelAPI.Entity) ([]ObjectDiff, error) {
+	sorted, err := sortForApply(list)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ObjectDiff, 0, len(sorted))
+	for _, obj := range sorted {
+		result = append(result, diffObject(client, obj))
+	}
+	for _, extra := range findExtraObjects(client, sorted, true) {
+		if extra.err != nil {
+			result = append(result, ObjectDiff{Kind: extra.kind, Project: extra.project, Error: extra.err.Error()})
+			continue
+		}
+		for _, name := range extra.names {
+			result = append(result, ObjectDiff{Kind: extra.kind, Project: extra.project, Name: name, Status: StatusOnlyLive})
+		}
+	}
+	return result, nil
+}
+
+func diffObject(client apiClient.ClientInterface, obj modelAPI.Entity) ObjectDiff {
+	result := ObjectDiff{Kind: objects.Kind(obj), Project: objects.Project(obj), Name: objects.Name(obj)}
+	accessor, err := objects.ForObject(client, obj)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	live, err := accessor.Get(result.Name)
+	if objects.IsNotFound(err) {
+		result.Status = StatusOnlyLocal
+		return result
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	unchanged, err := isUnchanged(live, obj)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if unchanged {
+		result.Status = StatusUnchanged
+		return result
+	}
+	changes, err := Compare(live, obj)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Changes = changes
+	result.Status = StatusChanged
+	return result
+}
+
+// Compare returns the semantic changes turning before into after, ignoring the metadata managed by Perses.
+func Compare(before modelAPI.Entity, after modelAPI.Entity) ([]diff.SemanticChange, error) {
+	beforeMap, err := objects.Normalize(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMap, err := objects.Normalize(after)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Semantic(after.GetKind(), beforeMap, afterMap), nil
+}
@@ -0,0 +1,133 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gitops converts Perses objects from and to a directory of YAML or JSON files,
+// as read by `percli apply -d`.
+package gitops
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	YAMLFormat Format = "yaml"
+	JSONFormat Format = "json"
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		return YAMLFormat, nil
+	case "json":
+		return JSONFormat, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q. valid values are: yaml, json", format)
+	}
+}
+
+// ProjectKinds lists the kinds of the objects exported along with a project.
+var ProjectKinds = []v1.Kind{v1.KindDatasource, v1.KindVariable, v1.KindDashboard, v1.KindRole, v1.KindRoleBinding}
+
+// File is an exported object.
+type File struct {
+	// Path is the path of the file, relative to the export directory.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ExportProject returns the project and the objects of the given kinds it contains.
+func ExportProject(client apiClient.ClientInterface, project string, kinds []v1.Kind) ([]modelAPI.Entity, error) {
+	projectObj, err := client.Project().Get(project)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project '%s': %w", project, err)
+	}
+	result := []modelAPI.Entity{projectObj}
+	for _, kind := range kinds {
+		accessor, err := objects.For(client, kind, project)
+		if err != nil {
+			return nil, err
+		}
+		list, err := accessor.List()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the %s objects of project '%s': %w", kind, project, err)
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+// FilePath returns the path of the file of an object: <project>/<plural kind>/<name>.<format>,
+// <project>/project.<format> for the project itself, and <plural kind>/<name>.<format> for the global objects.
+func FilePath(obj modelAPI.Entity, format Format) string {
+	kind := objects.Kind(obj)
+	fileName := objects.Name(obj) + "." + string(format)
+	switch {
+	case kind == v1.KindProject:
+		return path.Join(objects.Name(obj), "project."+string(format))
+	case v1.IsGlobal(kind):
+		return path.Join(v1.PluralKindMap[kind], fileName)
+	default:
+		return path.Join(objects.Project(obj), v1.PluralKindMap[kind], fileName)
+	}
+}
+
+// Marshal encodes the object without the metadata managed by Perses (version, createdAt and updatedAt),
+// so that exporting an unchanged object always gives the same file.
+func Marshal(obj modelAPI.Entity, format Format) ([]byte, error) {
+	normalized, err := objects.Normalize(obj)
+	if err != nil {
+		return nil, err
+	}
+	if format == JSONFormat {
+		data, err := json.MarshalIndent(normalized, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(normalized); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// ToFiles encodes every object in its own file.
+func ToFiles(list []modelAPI.Entity, format Format) ([]File, error) {
+	files := make([]File, 0, len(list))
+	for _, obj := range list {
+		data, err := Marshal(obj, format)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode %s: %w", objects.String(obj), err)
+		}
+		files = append(files, File{Path: FilePath(obj, format), Content: string(data)})
+	}
+	return files, nil
+}
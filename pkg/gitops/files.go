@@ -0,0 +1,34 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFiles writes the files in the given directory, creating the sub-directories as needed.
+func WriteFiles(dir string, files []File) error {
+	for _, file := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+			return fmt.Errorf("unable to create the directory of %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, []byte(file.Content), 0o600); err != nil {
+			return fmt.Errorf("unable to write %s: %w", filePath, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gitops
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// Decode reads the Perses objects of a YAML or JSON document. Like `percli apply`, the document can contain
+// a single object or a list of objects. In YAML, several documents can also be separated by "---".
+func Decode(data []byte) ([]modelAPI.Entity, error) {
+	var documents []any
+	if json.Valid(data) {
+		var document any
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var document any
+			err := decoder.Decode(&document)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if document != nil {
+				documents = append(documents, document)
+			}
+		}
+	}
+
+	var result []modelAPI.Entity
+	for _, document := range documents {
+		items, ok := document.([]any)
+		if !ok {
+			items = []any{document}
+		}
+		for _, item := range items {
+			obj, err := decodeObject(item)
+			if err != nil {
+				return nil, fmt.Errorf("object %d: %w", len(result), err)
+			}
+			result = append(result, obj)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no object found")
+	}
+	return result, nil
+}
+
+func decodeObject(item any) (modelAPI.Entity, error) {
+	raw, ok := item.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("an object is expected")
+	}
+	kindName, ok := raw["kind"].(string)
+	if !ok {
+		return nil, fmt.Errorf("unable to find the 'kind' field")
+	}
+	kind, err := v1.GetKind(kindName)
+	if err != nil {
+		return nil, err
+	}
+	raw["kind"] = string(*kind)
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return objects.Decode(*kind, data)
+}
+
+// Load reads the Perses objects of a file, or of every YAML and JSON file of a directory and its sub-directories.
+func Load(path string) ([]modelAPI.Entity, error) {
+	var files []string
+	err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(filePath) {
+		case ".json", ".yaml", ".yml":
+			files = append(files, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []modelAPI.Entity
+	for _, file := range files {
+		data, err := os.ReadFile(file) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		list, err := Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file %s: %w", file, err)
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lint checks the dashboards against a configurable set of style rules.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Config selects and tunes the rules. Every built-in rule is enabled with its default settings,
+// unless it is overridden by an entry of Rules.
+type Config struct {
+	Rules []RuleConfig `yaml:"rules,omitempty"`
+}
+
+// RuleConfig overrides the settings of a built-in rule.
+type RuleConfig struct {
+	// ID of the rule, e.g. "panel-title".
+	ID string `yaml:"id"`
+	// Disabled turns the rule off.
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Severity overrides the default severity of the rule.
+	Severity Severity `yaml:"severity,omitempty"`
+	// Labels lists the labels that must not be matched against a hard-coded value (rule no-hardcoded-labels).
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+func (c *Config) Verify() error {
+	var seen []string
+	for _, ruleConfig := range c.Rules {
+		if !slices.ContainsFunc(builtinRules, func(r rule) bool { return r.id == ruleConfig.ID }) {
+			return fmt.Errorf("unknown rule %q. valid rules are: %s", ruleConfig.ID, strings.Join(RuleIDs(), ", "))
+		}
+		if slices.Contains(seen, ruleConfig.ID) {
+			return fmt.Errorf("rule %q is configured more than once", ruleConfig.ID)
+		}
+		seen = append(seen, ruleConfig.ID)
+		switch ruleConfig.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return fmt.Errorf("invalid severity %q for rule %q. valid values are: error, warning, info", ruleConfig.Severity, ruleConfig.ID)
+		}
+	}
+	return nil
+}
+
+// Finding is a violation of a rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path is the path of the offending field in the dashboard, e.g. "spec.panels.cpu.spec.display.description".
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Fix suggests how to fix the finding.
+	Fix string `json:"fix"`
+}
+
+// Linter checks the dashboards against the enabled rules.
+type Linter struct {
+	rules []configuredRule
+}
+
+type configuredRule struct {
+	rule
+	severity Severity
+	settings RuleConfig
+}
+
+// New returns the linter applying the built-in rules tuned by the configuration. The configuration can be nil.
+func New(config *Config) *Linter {
+	linter := &Linter{}
+	for _, r := range builtinRules {
+		configured := configuredRule{rule: r, severity: r.severity, settings: RuleConfig{ID: r.id}}
+		if config != nil {
+			if i := slices.IndexFunc(config.Rules, func(c RuleConfig) bool { return c.ID == r.id }); i >= 0 {
+				configured.settings = config.Rules[i]
+			}
+		}
+		if configured.settings.Disabled {
+			continue
+		}
+		if configured.settings.Severity != "" {
+			configured.severity = configured.settings.Severity
+		}
+		linter.rules = append(linter.rules, configured)
+	}
+	return linter
+}
+
+// Lint returns the findings of the enabled rules, sorted by path.
+func (l *Linter) Lint(dashboard *v1.Dashboard) []Finding {
+	findings := []Finding{}
+	for _, r := range l.rules {
+		for _, violation := range r.check(dashboard, r.settings) {
+			findings = append(findings, Finding{
+				Rule:     r.id,
+				Severity: r.severity,
+				Path:     violation.path,
+				Message:  violation.message,
+				Fix:      violation.fix,
+			})
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
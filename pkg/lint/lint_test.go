@@ -0,0 +1,223 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDashboard returns a dashboard with a described time series panel 'cpu' running the given queries.
+func newDashboard(t *testing.T, queries ...string) *v1.Dashboard {
+	panelQueries := make([]any, 0, len(queries))
+	for _, query := range queries {
+		panelQueries = append(panelQueries, map[string]any{
+			"kind": "TimeSeriesQuery",
+			"spec": map[string]any{"plugin": map[string]any{"kind": "PrometheusTimeSeriesQuery", "spec": map[string]any{"query": query}}},
+		})
+	}
+	def := map[string]any{
+		"kind":     "Dashboard",
+		"metadata": map[string]any{"name": "overview", "project": "shop"},
+		"spec": map[string]any{
+			"duration":        "1h",
+			"refreshInterval": "30s",
+			"panels": map[string]any{
+				"cpu": map[string]any{
+					"kind": "Panel",
+					"spec": map[string]any{
+						"display": map[string]any{"name": "CPU", "description": "CPU usage of the pods"},
+						"plugin":  map[string]any{"kind": "TimeSeriesChart", "spec": map[string]any{"yAxis": map[string]any{"format": map[string]any{"unit": "percent"}}}},
+						"queries": panelQueries,
+					},
+				},
+			},
+			"layouts": []any{},
+		},
+	}
+	data, err := json.Marshal(def)
+	require.NoError(t, err)
+	var dashboard v1.Dashboard
+	require.NoError(t, json.Unmarshal(data, &dashboard))
+	return &dashboard
+}
+
+func TestCounterRate(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		query    string
+		messages []string
+	}{
+		{
+			title: "counter in rate",
+			query: `sum by (code) (rate(http_requests_total{job="api"}[$__rate_interval]))`,
+		},
+		{
+			title:    "counter without rate",
+			query:    `sum(http_requests_total{job="api"})`,
+			messages: []string{"panel 'cpu' query 0 uses the counter http_requests_total without rate()"},
+		},
+		{
+			title: "histogram buckets in rate",
+			query: `histogram_quantile(0.9, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))`,
+		},
+		{
+			title: "counter in a subquery",
+			query: `max_over_time(rate(http_requests_total[5m])[1h:1m])`,
+		},
+		{
+			title:    "counter without rate on one side of a binary expression",
+			query:    `rate(http_request_duration_seconds_sum[5m]) / http_request_duration_seconds_count`,
+			messages: []string{"panel 'cpu' query 0 uses the counter http_request_duration_seconds_count without rate()"},
+		},
+		{
+			title:    "counter selected by __name__",
+			query:    `{__name__="http_requests_total", job="api"}`,
+			messages: []string{"panel 'cpu' query 0 uses the counter http_requests_total without rate()"},
+		},
+		{
+			title: "suffix in a label value",
+			query: `up{job="requests_total"}`,
+		},
+		{
+			title: "suffix in a string literal",
+			query: `label_replace(up, "name", "errors_total", "", "")`,
+		},
+		{
+			title: "suffix in a label name",
+			query: `sum by (requests_total) (up)`,
+		},
+		{
+			title: "gauge",
+			query: `node_memory_MemAvailable_bytes`,
+		},
+		{
+			title: "invalid query",
+			query: `sum(http_requests_total`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var messages []string
+			for _, v := range checkCounterRate(newDashboard(t, test.query), RuleConfig{}) {
+				messages = append(messages, v.message)
+				assert.Equal(t, "spec.panels.cpu.spec.queries[0].spec.plugin.spec.query", v.path)
+			}
+			assert.Equal(t, test.messages, messages)
+		})
+	}
+}
+
+func TestHardcodedLabels(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		query    string
+		labels   []string
+		findings int
+	}{
+		{
+			title:    "hard-coded instance",
+			query:    `up{instance="host:9100"}`,
+			findings: 1,
+		},
+		{
+			title: "instance from a variable",
+			query: `up{instance=~"$instance"}`,
+		},
+		{
+			title: "instance matching anything",
+			query: `up{instance=~".*"}`,
+		},
+		{
+			title:    "configured labels",
+			query:    `up{instance="host:9100", pod="api-0"}`,
+			labels:   []string{"pod"},
+			findings: 1,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			violations := checkHardcodedLabels(newDashboard(t, test.query), RuleConfig{Labels: test.labels})
+			assert.Len(t, violations, test.findings)
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	dashboard := newDashboard(t, `sum(http_requests_total)`)
+	dashboard.Spec.Duration = ""
+	dashboard.Spec.Panels["cpu"].Spec.Display.Description = ""
+
+	findings := New(nil).Lint(dashboard)
+	rules := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		rules = append(rules, finding.Rule)
+	}
+	assert.Equal(t, []string{"dashboard-duration", "panel-description", "counter-rate"}, rules)
+
+	findings = New(&Config{Rules: []RuleConfig{
+		{ID: "panel-description", Disabled: true},
+		{ID: "counter-rate", Severity: SeverityError},
+	}}).Lint(dashboard)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "dashboard-duration", findings[0].Rule)
+	assert.Equal(t, "counter-rate", findings[1].Rule)
+	assert.Equal(t, SeverityError, findings[1].Severity)
+}
+
+func TestConfigVerify(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		config  Config
+		wantErr bool
+	}{
+		{
+			title:  "empty configuration",
+			config: Config{},
+		},
+		{
+			title:  "rule tuned",
+			config: Config{Rules: []RuleConfig{{ID: "no-hardcoded-labels", Severity: SeverityError, Labels: []string{"pod"}}}},
+		},
+		{
+			title:   "unknown rule",
+			config:  Config{Rules: []RuleConfig{{ID: "unknown"}}},
+			wantErr: true,
+		},
+		{
+			title:   "rule configured twice",
+			config:  Config{Rules: []RuleConfig{{ID: "panel-title"}, {ID: "panel-title", Disabled: true}}},
+			wantErr: true,
+		},
+		{
+			title:   "invalid severity",
+			config:  Config{Rules: []RuleConfig{{ID: "panel-title", Severity: "fatal"}}},
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.config.Verify()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/perses/mcp-server/pkg/promql"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// defaultHardcodedLabels are the labels checked by the rule no-hardcoded-labels when none is configured.
+var defaultHardcodedLabels = []string{"instance"}
+
+type violation struct {
+	path    string
+	message string
+	fix     string
+}
+
+type rule struct {
+	id       string
+	severity Severity
+	check    func(dashboard *v1.Dashboard, settings RuleConfig) []violation
+}
+
+var builtinRules = []rule{
+	{id: "panel-title", severity: SeverityWarning, check: checkPanelTitle},
+	{id: "panel-description", severity: SeverityInfo, check: checkPanelDescription},
+	{id: "counter-rate", severity: SeverityWarning, check: checkCounterRate},
+	{id: "no-hardcoded-labels", severity: SeverityWarning, check: checkHardcodedLabels},
+	{id: "timeseries-unit", severity: SeverityInfo, check: checkTimeSeriesUnit},
+	{id: "dashboard-duration", severity: SeverityWarning, check: checkDuration},
+	{id: "dashboard-refresh-interval", severity: SeverityInfo, check: checkRefreshInterval},
+}
+
+// RuleIDs returns the IDs of the built-in rules.
+func RuleIDs() []string {
+	ids := make([]string, 0, len(builtinRules))
+	for _, r := range builtinRules {
+		ids = append(ids, r.id)
+	}
+	return ids
+}
+
+func checkPanelTitle(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	var result []violation
+	forEachPanel(dashboard, func(id string, panel *v1.Panel) {
+		if panel.Spec.Display == nil || strings.TrimSpace(panel.Spec.Display.Name) == "" {
+			result = append(result, violation{
+				path:    panelPath(id, "spec.display.name"),
+				message: fmt.Sprintf("panel '%s' has no title", id),
+				fix:     "set spec.display.name to a short title describing what the panel shows",
+			})
+		}
+	})
+	return result
+}
+
+func checkPanelDescription(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	var result []violation
+	forEachPanel(dashboard, func(id string, panel *v1.Panel) {
+		if panel.Spec.Display == nil || strings.TrimSpace(panel.Spec.Display.Description) == "" {
+			result = append(result, violation{
+				path:    panelPath(id, "spec.display.description"),
+				message: fmt.Sprintf("panel '%s' has no description", id),
+				fix:     "set spec.display.description to explain how to read the panel",
+			})
+		}
+	})
+	return result
+}
+
+// counterSuffixes are the suffixes of the metrics that are counters by the naming conventions of Prometheus:
+// a counter ends with _total, and the histograms and summaries expose their counters as _count, _sum and _bucket.
+var counterSuffixes = []string{"_total", "_count", "_sum", "_bucket"}
+
+// checkCounterRate reports the counters that are used without range, i.e. not wrapped in rate(), irate() or increase().
+// The queries that can't be parsed are ignored: their syntax errors are reported by the PromQL analysis.
+func checkCounterRate(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	var result []violation
+	forEachQuery(dashboard, func(id string, i int, query string) {
+		expr, err := promql.ParseExpr(query)
+		if err != nil {
+			return
+		}
+		parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+			selector, ok := node.(*parser.VectorSelector)
+			if !ok || hasRange(path) {
+				return nil
+			}
+			name := metricName(selector)
+			if !slices.ContainsFunc(counterSuffixes, func(suffix string) bool { return strings.HasSuffix(name, suffix) }) {
+				return nil
+			}
+			result = append(result, violation{
+				path:    queryPath(id, i),
+				message: fmt.Sprintf("panel '%s' query %d uses the counter %s without rate()", id, i, name),
+				fix:     fmt.Sprintf("wrap the counter in rate(), e.g. rate(%s[$__rate_interval])", name),
+			})
+			return nil
+		})
+	})
+	return result
+}
+
+// hasRange returns true when the selector whose ancestors are given is a range vector, i.e. a matrix selector or a subquery.
+func hasRange(path []parser.Node) bool {
+	if len(path) == 0 {
+		return false
+	}
+	switch path[len(path)-1].(type) {
+	case *parser.MatrixSelector, *parser.SubqueryExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// metricName returns the metric name of the selector, given either before the braces or as a __name__ matcher.
+func metricName(selector *parser.VectorSelector) string {
+	if selector.Name != "" {
+		return selector.Name
+	}
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name == labels.MetricName && matcher.Type == labels.MatchEqual {
+			return matcher.Value
+		}
+	}
+	return ""
+}
+
+func checkHardcodedLabels(dashboard *v1.Dashboard, settings RuleConfig) []violation {
+	labels := settings.Labels
+	if len(labels) == 0 {
+		labels = defaultHardcodedLabels
+	}
+	matchers := make([]*regexp.Regexp, 0, len(labels))
+	for _, label := range labels {
+		matchers = append(matchers, regexp.MustCompile(`\b`+regexp.QuoteMeta(label)+`\s*(=~|=)\s*"([^"]*)"`))
+	}
+	var result []violation
+	forEachQuery(dashboard, func(id string, i int, query string) {
+		for j, matcher := range matchers {
+			label := labels[j]
+			for _, match := range matcher.FindAllStringSubmatch(query, -1) {
+				if strings.Contains(match[2], "$") || match[2] == "" || match[2] == ".*" || match[2] == ".+" {
+					continue
+				}
+				result = append(result, violation{
+					path:    queryPath(id, i),
+					message: fmt.Sprintf("panel '%s' query %d hard-codes %s", id, i, match[0]),
+					fix:     fmt.Sprintf("use a dashboard variable instead, e.g. %s%s\"$%s\"", label, match[1], label),
+				})
+			}
+		}
+	})
+	return result
+}
+
+func checkTimeSeriesUnit(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	var result []violation
+	forEachPanel(dashboard, func(id string, panel *v1.Panel) {
+		if panel.Spec.Plugin.Kind != "TimeSeriesChart" {
+			return
+		}
+		spec, _ := panel.Spec.Plugin.Spec.(map[string]any)
+		yAxis, _ := spec["yAxis"].(map[string]any)
+		format, _ := yAxis["format"].(map[string]any)
+		if unit, _ := format["unit"].(string); unit == "" {
+			result = append(result, violation{
+				path:    panelPath(id, "spec.plugin.spec.yAxis.format.unit"),
+				message: fmt.Sprintf("time series panel '%s' has no unit", id),
+				fix:     "set spec.plugin.spec.yAxis.format.unit (e.g. decimal, percent, bytes, seconds)",
+			})
+		}
+	})
+	return result
+}
+
+func checkDuration(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	if dashboard.Spec.Duration != "" {
+		return nil
+	}
+	return []violation{{
+		path:    "spec.duration",
+		message: "the dashboard has no default time range",
+		fix:     "set spec.duration, e.g. 1h",
+	}}
+}
+
+func checkRefreshInterval(dashboard *v1.Dashboard, _ RuleConfig) []violation {
+	if dashboard.Spec.RefreshInterval != "" {
+		return nil
+	}
+	return []violation{{
+		path:    "spec.refreshInterval",
+		message: "the dashboard has no default refresh interval",
+		fix:     "set spec.refreshInterval, e.g. 30s",
+	}}
+}
+
+// forEachPanel calls fn on every panel of the dashboard, in the order of their IDs.
+func forEachPanel(dashboard *v1.Dashboard, fn func(id string, panel *v1.Panel)) {
+	ids := make([]string, 0, len(dashboard.Spec.Panels))
+	for id := range dashboard.Spec.Panels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if panel := dashboard.Spec.Panels[id]; panel != nil {
+			fn(id, panel)
+		}
+	}
+}
+
+// forEachQuery calls fn on every query expression (e.g. PromQL query) of the panels of the dashboard.
+func forEachQuery(dashboard *v1.Dashboard, fn func(id string, i int, query string)) {
+	forEachPanel(dashboard, func(id string, panel *v1.Panel) {
+		for i, query := range panel.Spec.Queries {
+			spec, _ := query.Spec.Plugin.Spec.(map[string]any)
+			if expression, ok := spec["query"].(string); ok && expression != "" {
+				fn(id, i, expression)
+			}
+		}
+	})
+}
+
+func panelPath(id string, field string) string {
+	return fmt.Sprintf("spec.panels.%s.%s", id, field)
+}
+
+func queryPath(id string, i int) string {
+	return panelPath(id, fmt.Sprintf("spec.queries[%d].spec.plugin.spec.query", i))
+}
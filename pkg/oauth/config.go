@@ -0,0 +1,145 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	DefaultReadScope  = "perses:read"
+	DefaultWriteScope = "perses:write"
+)
+
+// Config describes how the MCP HTTP endpoint is protected, following the MCP authorization specification.
+// Access tokens are validated either locally as JWT using the keys published by the authorization server (JWKSURL),
+// or remotely using the token introspection endpoint of the authorization server (Introspection).
+type Config struct {
+	// Resource is the canonical URI of the MCP server (e.g. "https://mcp.example.com/mcp").
+	// It is published in the protected resource metadata and is the default expected audience of the tokens.
+	Resource string `yaml:"resource"`
+	// AuthorizationServers lists the issuer identifiers of the authorization servers that can issue tokens for the MCP server.
+	AuthorizationServers []string `yaml:"authorization_servers"`
+	// Issuer is the expected "iss" claim of the JWT access tokens. Defaults to the first authorization server.
+	Issuer string `yaml:"issuer,omitempty"`
+	// Audience is the expected audience of the access tokens. Defaults to Resource.
+	Audience string `yaml:"audience,omitempty"`
+	// JWKSURL is the URL of the JSON Web Key Set used to verify the signature of JWT access tokens.
+	JWKSURL string `yaml:"jwks_url,omitempty"`
+	// Introspection configures the token introspection endpoint (RFC 7662) used to validate opaque access tokens.
+	Introspection *IntrospectionConfig `yaml:"introspection,omitempty"`
+	// ReadScope is the scope required to call the tools that only read data.
+	ReadScope string `yaml:"read_scope,omitempty"`
+	// WriteScope is the scope required to call the tools that modify data.
+	WriteScope string `yaml:"write_scope,omitempty"`
+	// ToolScopes overrides the scopes required for specific tools, keyed by tool name.
+	ToolScopes map[string][]string `yaml:"tool_scopes,omitempty"`
+}
+
+type IntrospectionConfig struct {
+	// URL of the introspection endpoint.
+	URL string `yaml:"url"`
+	// ClientID used to authenticate against the introspection endpoint.
+	ClientID string `yaml:"client_id"`
+	// ClientSecret used to authenticate against the introspection endpoint.
+	ClientSecret string `yaml:"client_secret,omitempty"`
+	// ClientSecretFile is a file containing the client secret.
+	ClientSecretFile string `yaml:"client_secret_file,omitempty"`
+}
+
+func (c *IntrospectionConfig) GetClientSecret() (string, error) {
+	if len(c.ClientSecretFile) > 0 {
+		data, err := os.ReadFile(c.ClientSecretFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return c.ClientSecret, nil
+}
+
+func (c *Config) Verify() error {
+	if _, err := url.ParseRequestURI(c.Resource); err != nil {
+		return fmt.Errorf("invalid resource %q: %w", c.Resource, err)
+	}
+	if len(c.AuthorizationServers) == 0 {
+		return fmt.Errorf("at least one authorization server must be configured")
+	}
+	if (len(c.JWKSURL) > 0) == (c.Introspection != nil) {
+		return fmt.Errorf("exactly one of jwks_url or introspection must be configured")
+	}
+	if c.Introspection != nil {
+		if len(c.Introspection.URL) == 0 || len(c.Introspection.ClientID) == 0 {
+			return fmt.Errorf("introspection url and client_id must be configured")
+		}
+		if len(c.Introspection.ClientSecret) > 0 && len(c.Introspection.ClientSecretFile) > 0 {
+			return fmt.Errorf("at most one of introspection client_secret & client_secret_file must be configured")
+		}
+	}
+	if c.Issuer == "" {
+		c.Issuer = c.AuthorizationServers[0]
+	}
+	if c.Audience == "" {
+		c.Audience = c.Resource
+	}
+	if c.ReadScope == "" {
+		c.ReadScope = DefaultReadScope
+	}
+	if c.WriteScope == "" {
+		c.WriteScope = DefaultWriteScope
+	}
+	return nil
+}
+
+// RequiredScopes returns the scopes a token must hold to call the given tool.
+func (c *Config) RequiredScopes(toolName string, isWriteTool bool) []string {
+	if scopes, ok := c.ToolScopes[toolName]; ok {
+		return scopes
+	}
+	if isWriteTool {
+		return []string{c.WriteScope}
+	}
+	return []string{c.ReadScope}
+}
+
+// ScopesSupported returns every scope used by the MCP server, as published in the protected resource metadata.
+func (c *Config) ScopesSupported() []string {
+	scopes := []string{c.ReadScope, c.WriteScope}
+	seen := map[string]bool{c.ReadScope: true, c.WriteScope: true}
+	for _, toolScopes := range c.ToolScopes {
+		for _, scope := range toolScopes {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// ResourceMetadataURL returns the URL of the protected resource metadata, built as described in RFC 9728 section 3.1:
+// the well-known path is inserted between the host and the path of the resource identifier.
+func (c *Config) ResourceMetadataURL() string {
+	u, err := url.Parse(c.Resource)
+	if err != nil {
+		return ""
+	}
+	u.Path = WellKnownPath + strings.TrimSuffix(u.Path, "/")
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
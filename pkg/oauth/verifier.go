@@ -0,0 +1,253 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+)
+
+// WellKnownPath is the path prefix under which the protected resource metadata is served.
+const WellKnownPath = "/.well-known/oauth-protected-resource"
+
+const (
+	httpTimeout = 10 * time.Second
+	// jwksMinRefreshInterval limits how often the key set is fetched again when a token uses an unknown key.
+	jwksMinRefreshInterval = 30 * time.Second
+	clockLeeway            = time.Minute
+)
+
+var supportedAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// NewVerifier returns the function validating the access tokens sent to the MCP server.
+func NewVerifier(cfg Config) (auth.TokenVerifier, error) {
+	client := &http.Client{Timeout: httpTimeout}
+	if cfg.Introspection != nil {
+		v := &introspectionVerifier{cfg: cfg, client: client}
+		return v.verify, nil
+	}
+	v := &jwksVerifier{cfg: cfg, client: client}
+	return v.verify, nil
+}
+
+type jwksVerifier struct {
+	cfg       Config
+	client    *http.Client
+	mutex     sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+func (v *jwksVerifier) verify(ctx context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	parsed, err := jwt.ParseSigned(token, supportedAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", auth.ErrInvalidToken, err)
+	}
+	if len(parsed.Headers) == 0 {
+		return nil, fmt.Errorf("%w: missing JWS header", auth.ErrInvalidToken)
+	}
+	key, err := v.getKey(ctx, parsed.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwt.Claims
+	var extra map[string]any
+	if claimsErr := parsed.Claims(key.Key, &claims, &extra); claimsErr != nil {
+		return nil, fmt.Errorf("%w: %s", auth.ErrInvalidToken, claimsErr)
+	}
+	expected := jwt.Expected{
+		Issuer:      v.cfg.Issuer,
+		AnyAudience: jwt.Audience{v.cfg.Audience},
+		Time:        time.Now(),
+	}
+	if validateErr := claims.ValidateWithLeeway(expected, clockLeeway); validateErr != nil {
+		return nil, fmt.Errorf("%w: %s", auth.ErrInvalidToken, validateErr)
+	}
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: token missing expiration", auth.ErrInvalidToken)
+	}
+
+	return &auth.TokenInfo{
+		Scopes:     parseScopes(extra),
+		Expiration: claims.Expiry.Time(),
+		UserID:     claims.Issuer + "|" + claims.Subject,
+		Extra:      extra,
+	}, nil
+}
+
+// getKey returns the key matching the given ID. The key set is fetched again when the key is unknown,
+// to follow the key rotation of the authorization server.
+func (v *jwksVerifier) getKey(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if key := findKey(v.keys, keyID); key != nil {
+		return key, nil
+	}
+	if v.keys != nil && time.Since(v.fetchedAt) < jwksMinRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown signing key %q", auth.ErrInvalidToken, keyID)
+	}
+	keys, err := v.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	if key := findKey(v.keys, keyID); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", auth.ErrInvalidToken, keyID)
+}
+
+func (v *jwksVerifier) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.cfg.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch the JWKS: unexpected status %s", resp.Status)
+	}
+	keys := &jose.JSONWebKeySet{}
+	if err := json.NewDecoder(resp.Body).Decode(keys); err != nil {
+		return nil, fmt.Errorf("unable to decode the JWKS: %w", err)
+	}
+	return keys, nil
+}
+
+func findKey(keys *jose.JSONWebKeySet, keyID string) *jose.JSONWebKey {
+	if keys == nil {
+		return nil
+	}
+	for i, key := range keys.Keys {
+		if !key.IsPublic() || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		// When the token doesn't provide a key ID, the first signing key is used.
+		if keyID == "" || key.KeyID == keyID {
+			return &keys.Keys[i]
+		}
+	}
+	return nil
+}
+
+type introspectionResponse struct {
+	Active   bool            `json:"active"`
+	Scope    string          `json:"scope"`
+	Exp      int64           `json:"exp"`
+	Sub      string          `json:"sub"`
+	Iss      string          `json:"iss"`
+	Aud      json.RawMessage `json:"aud"`
+	ClientID string          `json:"client_id"`
+}
+
+type introspectionVerifier struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (v *introspectionVerifier) verify(ctx context.Context, token string, _ *http.Request) (*auth.TokenInfo, error) {
+	clientSecret, err := v.cfg.Introspection.GetClientSecret()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the introspection client secret: %w", err)
+	}
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.cfg.Introspection.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(v.cfg.Introspection.ClientID), url.QueryEscape(clientSecret))
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to introspect the token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to introspect the token: unexpected status %s", resp.Status)
+	}
+	var result introspectionResponse
+	if decodeErr := json.NewDecoder(resp.Body).Decode(&result); decodeErr != nil {
+		return nil, fmt.Errorf("unable to decode the introspection response: %w", decodeErr)
+	}
+
+	if !result.Active {
+		return nil, fmt.Errorf("%w: token is not active", auth.ErrInvalidToken)
+	}
+	if result.Iss != "" && result.Iss != v.cfg.Issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer %q", auth.ErrInvalidToken, result.Iss)
+	}
+	// A token without audience could have been issued for any other resource server, so it is rejected like the JWT access tokens.
+	var audience jwt.Audience
+	if len(result.Aud) == 0 || json.Unmarshal(result.Aud, &audience) != nil || !audience.Contains(v.cfg.Audience) {
+		return nil, fmt.Errorf("%w: token is not intended for %q", auth.ErrInvalidToken, v.cfg.Audience)
+	}
+	expiration := time.Unix(result.Exp, 0)
+	if result.Exp == 0 {
+		// The token is active but the server doesn't tell until when. It is verified again on the next request.
+		expiration = time.Now().Add(clockLeeway)
+	}
+	userID := result.Sub
+	if userID == "" {
+		userID = result.ClientID
+	}
+
+	return &auth.TokenInfo{
+		Scopes:     strings.Fields(result.Scope),
+		Expiration: expiration,
+		UserID:     result.Iss + "|" + userID,
+	}, nil
+}
+
+// parseScopes reads the scopes from the "scope" claim (space-separated string, RFC 9068)
+// or from the "scp" claim (list of strings) used by some authorization servers.
+func parseScopes(claims map[string]any) []string {
+	var scopes []string
+	for _, claim := range []string{"scope", "scp"} {
+		switch value := claims[claim].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(value)...)
+		case []any:
+			for _, v := range value {
+				if s, ok := v.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+	slices.Sort(scopes)
+	return slices.Compact(scopes)
+}
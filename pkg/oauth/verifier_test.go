@@ -0,0 +1,271 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testResource     = "https://mcp.example.com/mcp"
+	testKeyID        = "key-1"
+	testClientID     = "perses-mcp"
+	testClientSecret = "secret"
+)
+
+// fakeIssuer is an authorization server publishing its metadata and its key set, and introspecting the tokens it knows.
+type fakeIssuer struct {
+	*httptest.Server
+	key *ecdsa.PrivateKey
+	// introspection holds the introspection response of each opaque token.
+	introspection map[string]map[string]any
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	issuer := &fakeIssuer{key: key, introspection: map[string]map[string]any{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL,
+			"jwks_uri":               issuer.URL + "/jwks.json",
+			"introspection_endpoint": issuer.URL + "/introspect",
+		})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: testKeyID, Algorithm: string(jose.ES256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		if clientID, clientSecret, ok := r.BasicAuth(); !ok || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := issuer.introspection[r.PostFormValue("token")]
+		if !ok {
+			response = map[string]any{"active": false}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *fakeIssuer) config() Config {
+	cfg := Config{
+		Resource:             testResource,
+		AuthorizationServers: []string{i.URL},
+		JWKSURL:              i.URL + "/jwks.json",
+	}
+	if err := cfg.Verify(); err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func (i *fakeIssuer) introspectionConfig() Config {
+	cfg := Config{
+		Resource:             testResource,
+		AuthorizationServers: []string{i.URL},
+		Introspection: &IntrospectionConfig{
+			URL:          i.URL + "/introspect",
+			ClientID:     testClientID,
+			ClientSecret: testClientSecret,
+		},
+	}
+	if err := cfg.Verify(); err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// sign returns a JWT with the given claims, signed with the given key.
+func sign(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, (&jose.SignerOptions{}).WithHeader(jose.HeaderKey("kid"), testKeyID))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":   issuer.URL,
+			"sub":   "alice",
+			"aud":   testResource,
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"scope": "perses:read perses:write",
+		}
+	}
+	with := func(key string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	scpClaims := with("scope", nil)
+	scpClaims["scp"] = []string{"perses:read"}
+
+	testSuite := []struct {
+		title   string
+		token   string
+		scopes  []string
+		wantErr bool
+	}{
+		{
+			title:  "valid token",
+			token:  sign(t, issuer.key, validClaims()),
+			scopes: []string{"perses:read", "perses:write"},
+		},
+		{
+			title:  "scopes in the scp claim",
+			token:  sign(t, issuer.key, scpClaims),
+			scopes: []string{"perses:read"},
+		},
+		{
+			title:  "audience in a list",
+			token:  sign(t, issuer.key, with("aud", []string{"https://other.example.com", testResource})),
+			scopes: []string{"perses:read", "perses:write"},
+		},
+		{
+			title:   "bad signature",
+			token:   sign(t, otherKey, validClaims()),
+			wantErr: true,
+		},
+		{
+			title:   "expired token",
+			token:   sign(t, issuer.key, with("exp", now.Add(-time.Hour).Unix())),
+			wantErr: true,
+		},
+		{
+			title:   "missing expiration",
+			token:   sign(t, issuer.key, with("exp", nil)),
+			wantErr: true,
+		},
+		{
+			title:   "wrong issuer",
+			token:   sign(t, issuer.key, with("iss", "https://evil.example.com")),
+			wantErr: true,
+		},
+		{
+			title:   "wrong audience",
+			token:   sign(t, issuer.key, with("aud", "https://other.example.com")),
+			wantErr: true,
+		},
+		{
+			title:   "missing audience",
+			token:   sign(t, issuer.key, with("aud", nil)),
+			wantErr: true,
+		},
+		{
+			title:   "not a JWT",
+			token:   "opaque-token",
+			wantErr: true,
+		},
+	}
+	verifier, err := NewVerifier(issuer.config())
+	require.NoError(t, err)
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			info, err := verifier(context.Background(), test.token, nil)
+			if test.wantErr {
+				assert.ErrorIs(t, err, auth.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.scopes, info.Scopes)
+			assert.Equal(t, issuer.URL+"|alice", info.UserID)
+		})
+	}
+}
+
+func TestIntrospectionVerifier(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	exp := time.Now().Add(time.Hour).Unix()
+	issuer.introspection = map[string]map[string]any{
+		"valid":          {"active": true, "iss": issuer.URL, "sub": "alice", "aud": testResource, "exp": exp, "scope": "perses:read"},
+		"audience-list":  {"active": true, "iss": issuer.URL, "sub": "alice", "aud": []string{testResource}, "exp": exp, "scope": "perses:read"},
+		"inactive":       {"active": false},
+		"wrong-issuer":   {"active": true, "iss": "https://evil.example.com", "sub": "alice", "aud": testResource, "exp": exp},
+		"wrong-audience": {"active": true, "iss": issuer.URL, "sub": "alice", "aud": "https://other.example.com", "exp": exp},
+		"no-audience":    {"active": true, "iss": issuer.URL, "sub": "alice", "exp": exp},
+	}
+
+	testSuite := []struct {
+		title   string
+		token   string
+		scopes  []string
+		wantErr bool
+	}{
+		{title: "valid token", token: "valid", scopes: []string{"perses:read"}},
+		{title: "audience in a list", token: "audience-list", scopes: []string{"perses:read"}},
+		{title: "inactive token", token: "inactive", wantErr: true},
+		{title: "unknown token", token: "unknown", wantErr: true},
+		{title: "wrong issuer", token: "wrong-issuer", wantErr: true},
+		{title: "wrong audience", token: "wrong-audience", wantErr: true},
+		{title: "missing audience", token: "no-audience", wantErr: true},
+	}
+	verifier, err := NewVerifier(issuer.introspectionConfig())
+	require.NoError(t, err)
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			info, err := verifier(context.Background(), test.token, nil)
+			if test.wantErr {
+				assert.ErrorIs(t, err, auth.ErrInvalidToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.scopes, info.Scopes)
+			assert.Equal(t, issuer.URL+"|alice", info.UserID)
+			assert.Equal(t, exp, info.Expiration.Unix())
+		})
+	}
+}
+
+func TestIntrospectionVerifierClientAuthentication(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	cfg := issuer.introspectionConfig()
+	cfg.Introspection.ClientSecret = "wrong"
+	verifier, err := NewVerifier(cfg)
+	require.NoError(t, err)
+	_, err = verifier(context.Background(), "valid", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Accessor gives access to the objects of one kind (and one project for the project-scoped kinds).
+type Accessor interface {
+	Create(obj modelAPI.Entity) (modelAPI.Entity, error)
+	Update(obj modelAPI.Entity) (modelAPI.Entity, error)
+	Delete(name string) error
+	Get(name string) (modelAPI.Entity, error)
+	List() ([]modelAPI.Entity, error)
+}
+
+// For returns the accessor of the objects of the given kind. The project is ignored for the global kinds.
+func For(client apiClient.ClientInterface, kind v1.Kind, project string) (Accessor, error) {
+	if !v1.IsGlobal(kind) && project == "" {
+		return nil, fmt.Errorf("a project is required to access the %s objects", kind)
+	}
+	switch kind {
+	case v1.KindProject:
+		return accessor[*v1.Project]{client.Project()}, nil
+	case v1.KindDashboard:
+		return accessor[*v1.Dashboard]{client.Dashboard(project)}, nil
+	case v1.KindDatasource:
+		return accessor[*v1.Datasource]{client.Datasource(project)}, nil
+	case v1.KindEphemeralDashboard:
+		return accessor[*v1.EphemeralDashboard]{client.EphemeralDashboard(project)}, nil
+	case v1.KindFolder:
+		return accessor[*v1.Folder]{client.Folder(project)}, nil
+	case v1.KindRole:
+		return accessor[*v1.Role]{client.Role(project)}, nil
+	case v1.KindRoleBinding:
+		return accessor[*v1.RoleBinding]{client.RoleBinding(project)}, nil
+	case v1.KindSecret:
+		return accessor[*v1.Secret]{client.Secret(project)}, nil
+	case v1.KindVariable:
+		return accessor[*v1.Variable]{client.Variable(project)}, nil
+	case v1.KindGlobalDatasource:
+		return accessor[*v1.GlobalDatasource]{client.GlobalDatasource()}, nil
+	case v1.KindGlobalRole:
+		return accessor[*v1.GlobalRole]{client.GlobalRole()}, nil
+	case v1.KindGlobalRoleBinding:
+		return accessor[*v1.GlobalRoleBinding]{client.GlobalRoleBinding()}, nil
+	case v1.KindGlobalSecret:
+		return accessor[*v1.GlobalSecret]{client.GlobalSecret()}, nil
+	case v1.KindGlobalVariable:
+		return accessor[*v1.GlobalVariable]{client.GlobalVariable()}, nil
+	default:
+		return nil, fmt.Errorf("kind %s is not supported", kind)
+	}
+}
+
+// ForObject returns the accessor of the kind and project of the given object.
+func ForObject(client apiClient.ClientInterface, obj modelAPI.Entity) (Accessor, error) {
+	return For(client, Kind(obj), Project(obj))
+}
+
+// typedClient is the interface shared by the Perses clients of every kind.
+type typedClient[T modelAPI.Entity] interface {
+	Create(entity T) (T, error)
+	Update(entity T) (T, error)
+	Delete(name string) error
+	Get(name string) (T, error)
+	List(prefix string) ([]T, error)
+}
+
+type accessor[T modelAPI.Entity] struct {
+	client typedClient[T]
+}
+
+func (a accessor[T]) Create(obj modelAPI.Entity) (modelAPI.Entity, error) {
+	typed, ok := obj.(T)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	created, err := a.client.Create(typed)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (a accessor[T]) Update(obj modelAPI.Entity) (modelAPI.Entity, error) {
+	typed, ok := obj.(T)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	updated, err := a.client.Update(typed)
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (a accessor[T]) Delete(name string) error {
+	return a.client.Delete(name)
+}
+
+func (a accessor[T]) Get(name string) (modelAPI.Entity, error) {
+	obj, err := a.client.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (a accessor[T]) List() ([]modelAPI.Entity, error) {
+	list, err := a.client.List("")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]modelAPI.Entity, 0, len(list))
+	for _, obj := range list {
+		result = append(result, obj)
+	}
+	return result, nil
+}
+
+// IsNotFound returns true when the error reports that the requested object doesn't exist.
+func IsNotFound(err error) bool {
+	var requestErr *perseshttp.RequestError
+	return errors.As(err, &requestErr) && requestErr.StatusCode == http.StatusNotFound
+}
+
+// IsForbidden returns true when the error reports that the caller is not allowed to make the request.
+func IsForbidden(err error) bool {
+	var requestErr *perseshttp.RequestError
+	return errors.As(err, &requestErr) && (requestErr.StatusCode == http.StatusForbidden || requestErr.StatusCode == http.StatusUnauthorized)
+}
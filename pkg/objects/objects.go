@@ -0,0 +1,260 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package objects provides helpers to handle the Perses objects independently of their kind.
+package objects
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// serverManagedMetadata lists the metadata fields set by Perses, which are not part of the definition of an object.
+var serverManagedMetadata = []string{"createdAt", "updatedAt", "version"}
+
+// Kind returns the kind of the object.
+func Kind(obj modelAPI.Entity) v1.Kind {
+	return v1.Kind(obj.GetKind())
+}
+
+// Name returns the name of the object.
+func Name(obj modelAPI.Entity) string {
+	return obj.GetMetadata().GetName()
+}
+
+// Project returns the project of the object, or an empty string for a global object.
+func Project(obj modelAPI.Entity) string {
+	if metadata, ok := obj.GetMetadata().(*v1.ProjectMetadata); ok {
+		return metadata.Project
+	}
+	return ""
+}
+
+// SetProject moves a project-scoped object to the given project. It has no effect on a global object.
+func SetProject(obj modelAPI.Entity, project string) {
+	if metadata, ok := obj.GetMetadata().(*v1.ProjectMetadata); ok {
+		metadata.Project = project
+	}
+}
+
+// SetName renames the object.
+func SetName(obj modelAPI.Entity, name string) {
+	switch metadata := obj.GetMetadata().(type) {
+	case *v1.ProjectMetadata:
+		metadata.Name = name
+	case *v1.Metadata:
+		metadata.Name = name
+	}
+}
+
+// String returns a human-readable identifier of the object.
+func String(obj modelAPI.Entity) string {
+	if project := Project(obj); project != "" && Kind(obj) != v1.KindProject {
+		return fmt.Sprintf("%s '%s' in project '%s'", obj.GetKind(), Name(obj), project)
+	}
+	return fmt.Sprintf("%s '%s'", obj.GetKind(), Name(obj))
+}
+
+// Normalize returns the generic JSON representation of the object without the metadata managed by Perses
+// (version, createdAt and updatedAt), so that two definitions of the same object can be compared.
+func Normalize(obj modelAPI.Entity) (map[string]any, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if metadata, ok := result["metadata"].(map[string]any); ok {
+		for _, field := range serverManagedMetadata {
+			delete(metadata, field)
+		}
+	}
+	return result, nil
+}
+
+// Copy returns a deep copy of the object.
+func Copy(obj modelAPI.Entity) (modelAPI.Entity, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(Kind(obj), data)
+}
+
+// Decode unmarshals the JSON definition of an object of the given kind.
+func Decode(kind v1.Kind, data []byte) (modelAPI.Entity, error) {
+	obj, err := v1.GetStruct(kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", kind, err)
+	}
+	return obj, nil
+}
+
+// RenameDatasourceReferences returns a copy of the object in which the datasource selectors
+// (e.g. {"kind": "PrometheusDatasource", "name": "prometheus"}) referencing a datasource of the mapping
+// are replaced by the new name of the datasource.
+func RenameDatasourceReferences(obj modelAPI.Entity, mapping map[string]string) (modelAPI.Entity, error) {
+	return transform(obj, func(raw any) {
+		WalkDatasourceSelectors(raw, func(selector map[string]any) {
+			if name, ok := selector["name"].(string); ok {
+				if newName, renamed := mapping[name]; renamed {
+					selector["name"] = newName
+				}
+			}
+		})
+	})
+}
+
+// RenameVariableReferences returns a copy of the object in which the references to the variables of the mapping
+// ($name, ${name} and ${name:format}) are replaced by the new name of the variable.
+func RenameVariableReferences(obj modelAPI.Entity, mapping map[string]string) (modelAPI.Entity, error) {
+	return transform(obj, func(raw any) {
+		walkStrings(raw, func(value string) string {
+			return variableReference.ReplaceAllStringFunc(value, func(reference string) string {
+				name := variableReference.FindStringSubmatch(reference)
+				oldName := name[1] + name[2]
+				if newName, renamed := mapping[oldName]; renamed {
+					return strings.Replace(reference, oldName, newName, 1)
+				}
+				return reference
+			})
+		})
+	})
+}
+
+// DatasourceReference is a datasource selected by name.
+type DatasourceReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+// DatasourceReferences returns the datasources selected by name in the object.
+// The selectors without name, which select the default datasource of a kind, are ignored.
+func DatasourceReferences(obj modelAPI.Entity) ([]DatasourceReference, error) {
+	raw, err := toRaw(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result []DatasourceReference
+	WalkDatasourceSelectors(raw, func(selector map[string]any) {
+		name, _ := selector["name"].(string)
+		kind, _ := selector["kind"].(string)
+		if ref := (DatasourceReference{Kind: kind, Name: name}); name != "" && !slices.Contains(result, ref) {
+			result = append(result, ref)
+		}
+	})
+	return result, nil
+}
+
+// VariableReferences returns the names of the variables referenced in the strings of the object.
+func VariableReferences(obj modelAPI.Entity) ([]string, error) {
+	raw, err := toRaw(obj)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	walkStrings(raw, func(value string) string {
+		for _, match := range variableReference.FindAllStringSubmatch(value, -1) {
+			if name := match[1] + match[2]; !slices.Contains(result, name) {
+				result = append(result, name)
+			}
+		}
+		return value
+	})
+	return result, nil
+}
+
+// variableReference matches $name, ${name} and ${name:format}. The name is captured by the first or the second group.
+var variableReference = regexp.MustCompile(`\$(?:([a-zA-Z0-9_-]+)|\{([a-zA-Z0-9_-]+)(?::[^}]*)?})`)
+
+func toRaw(obj modelAPI.Entity) (any, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// transform returns a copy of the object modified by fn, which receives the generic JSON representation of the object.
+func transform(obj modelAPI.Entity, fn func(raw any)) (modelAPI.Entity, error) {
+	raw, err := toRaw(obj)
+	if err != nil {
+		return nil, err
+	}
+	fn(raw)
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(Kind(obj), data)
+}
+
+// walkStrings replaces every string of the generic JSON value by the result of fn.
+func walkStrings(raw any, fn func(value string) string) {
+	switch value := raw.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if s, ok := child.(string); ok {
+				value[key] = fn(s)
+				continue
+			}
+			walkStrings(child, fn)
+		}
+	case []any:
+		for i, child := range value {
+			if s, ok := child.(string); ok {
+				value[i] = fn(s)
+				continue
+			}
+			walkStrings(child, fn)
+		}
+	}
+}
+
+// WalkDatasourceSelectors calls fn on every datasource selector found in the generic JSON representation of an object.
+func WalkDatasourceSelectors(raw any, fn func(selector map[string]any)) {
+	switch value := raw.(type) {
+	case map[string]any:
+		for key, child := range value {
+			if selector, ok := child.(map[string]any); ok && key == "datasource" && isDatasourceSelector(selector) {
+				fn(selector)
+				continue
+			}
+			WalkDatasourceSelectors(child, fn)
+		}
+	case []any:
+		for _, child := range value {
+			WalkDatasourceSelectors(child, fn)
+		}
+	}
+}
+
+func isDatasourceSelector(value map[string]any) bool {
+	_, hasKind := value["kind"].(string)
+	return hasKind && len(value) <= 2
+}
@@ -0,0 +1,213 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package paneldata runs the queries of a dashboard panel through the datasource proxy of Perses, to get the data the panel displays.
+package paneldata
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/prometheus"
+	"github.com/perses/mcp-server/pkg/references"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const prometheusQueryKind = "PrometheusTimeSeriesQuery"
+
+// Range is the time range over which the queries are evaluated, at every step.
+type Range struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// Query is a query of the panel, as sent to the datasource.
+type Query struct {
+	// Kind is the kind of the query plugin, e.g. "PrometheusTimeSeriesQuery".
+	Kind string `json:"kind"`
+	// Expression is the query with the variables replaced by their value.
+	Expression string `json:"expression,omitempty"`
+	// Datasource is the name of the datasource selected by the query, empty for the default one.
+	Datasource string `json:"datasource,omitempty"`
+}
+
+// Series is a series returned by a query of the panel, named by the legend format of the query.
+type Series struct {
+	Name string `json:"name"`
+	// Query is the index of the query which returned the series.
+	Query   int                 `json:"query"`
+	Labels  map[string]string   `json:"-"`
+	Samples []prometheus.Sample `json:"-"`
+}
+
+// Data is what a panel displays over a time range.
+type Data struct {
+	Panel       string `json:"panel"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Kind is the kind of the panel plugin, e.g. "TimeSeriesChart".
+	Kind string `json:"kind"`
+	// Unit is the unit of the values set in the format of the panel, e.g. "bytes".
+	Unit    string   `json:"unit,omitempty"`
+	Queries []Query  `json:"queries"`
+	Series  []Series `json:"-"`
+	Range   Range    `json:"-"`
+	// Notes explains the queries which couldn't run and the approximations made to run the other ones.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Fetch runs the queries of the panel of the dashboard over the time range. The variables of the queries take the given values,
+// or else their default value. Only the Prometheus queries can run: the other ones are listed in the notes.
+func Fetch(client apiClient.ClientInterface, dashboardObj *v1.Dashboard, panelID string, timeRange Range, values map[string]string) (*Data, error) {
+	panel, ok := dashboardObj.Spec.Panels[panelID]
+	if !ok || panel == nil {
+		return nil, fmt.Errorf("panel '%s' doesn't exist in dashboard '%s'", panelID, dashboardObj.Metadata.Name)
+	}
+	data := &Data{
+		Panel: panelID,
+		Kind:  panel.Spec.Plugin.Kind,
+		Unit:  unit(panel.Spec.Plugin.Spec),
+		Range: timeRange,
+	}
+	if panel.Spec.Display != nil {
+		data.Title = panel.Spec.Display.Name
+		data.Description = panel.Spec.Display.Description
+	}
+
+	interpolator := newInterpolator(dashboardObj, timeRange, values)
+	for i, query := range panel.Spec.Queries {
+		spec, _ := query.Spec.Plugin.Spec.(map[string]any)
+		expression, _ := spec["query"].(string)
+		datasource := datasourceName(spec)
+		data.Queries = append(data.Queries, Query{Kind: query.Spec.Plugin.Kind, Expression: expression, Datasource: datasource})
+		if query.Spec.Plugin.Kind != prometheusQueryKind {
+			data.Notes = append(data.Notes, fmt.Sprintf("query %d is a %s, only the Prometheus queries are run", i, query.Spec.Plugin.Kind))
+			continue
+		}
+		if strings.TrimSpace(expression) == "" {
+			continue
+		}
+		expression, unresolved := interpolator.interpolate(expression)
+		data.Queries[i].Expression = expression
+		if len(unresolved) > 0 {
+			data.Notes = append(data.Notes, fmt.Sprintf("query %d is not run, the variables %s have no value: provide one", i, strings.Join(unresolved, ", ")))
+			continue
+		}
+
+		source, found, err := references.ResolveDatasource(client, dashboardObj.Metadata.Project, dashboardObj, prometheus.DatasourceKind, datasource)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			data.Notes = append(data.Notes, fmt.Sprintf("query %d is not run, its Prometheus datasource doesn't exist", i))
+			continue
+		}
+		result, err := prometheus.NewClient(client, dashboardObj.Metadata.Project, dashboardObj.Metadata.Name, source).
+			QueryRange(expression, timeRange.Start, timeRange.End, timeRange.Step)
+		if err != nil {
+			data.Notes = append(data.Notes, fmt.Sprintf("query %d failed: %s", i, err))
+			continue
+		}
+		legend, _ := spec["seriesNameFormat"].(string)
+		for _, series := range result {
+			data.Series = append(data.Series, Series{
+				Name:    seriesName(legend, series.Labels),
+				Query:   i,
+				Labels:  series.Labels,
+				Samples: series.Samples,
+			})
+		}
+	}
+	data.Notes = append(data.Notes, interpolator.notes...)
+	return data, nil
+}
+
+func datasourceName(spec map[string]any) string {
+	selector, _ := spec["datasource"].(map[string]any)
+	name, _ := selector["name"].(string)
+	return name
+}
+
+// unit returns the unit of the format of the panel: the one of the Y axis for the charts, the one of the values for the other panels.
+func unit(spec any) string {
+	for _, path := range [][]string{{"yAxis", "format", "unit"}, {"format", "unit"}} {
+		value := spec
+		for _, key := range path {
+			object, _ := value.(map[string]any)
+			value = object[key]
+		}
+		if unit, ok := value.(string); ok {
+			return unit
+		}
+	}
+	return ""
+}
+
+var legendReference = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// seriesName returns the name of the series given by the legend format, e.g. "{{pod}}",
+// or its labels like Prometheus prints them when there is no format.
+func seriesName(legend string, labels map[string]string) string {
+	if legend != "" {
+		return legendReference.ReplaceAllStringFunc(legend, func(match string) string {
+			return labels[legendReference.FindStringSubmatch(match)[1]]
+		})
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if name != "__name__" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	matchers := make([]string, 0, len(names))
+	for _, name := range names {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	if len(matchers) == 0 {
+		return labels["__name__"]
+	}
+	return labels["__name__"] + "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// Stats summarizes the samples of a series.
+type Stats struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	// Last is the value of the most recent sample.
+	Last float64 `json:"last"`
+	// Samples is the number of samples of the series.
+	Samples int `json:"samples"`
+}
+
+// Stats returns the summary of the samples of the series, or nil when it has no sample.
+func (s Series) Stats() *Stats {
+	if len(s.Samples) == 0 {
+		return nil
+	}
+	stats := &Stats{Min: s.Samples[0].Value, Max: s.Samples[0].Value, Last: s.Samples[len(s.Samples)-1].Value, Samples: len(s.Samples)}
+	sum := 0.0
+	for _, sample := range s.Samples {
+		stats.Min = min(stats.Min, sample.Value)
+		stats.Max = max(stats.Max, sample.Value)
+		sum += sample.Value
+	}
+	stats.Mean = sum / float64(len(s.Samples))
+	return stats
+}
@@ -0,0 +1,111 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package paneldata
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+// scrapeInterval is the scrape interval assumed to compute $__rate_interval, the default one of Prometheus.
+const scrapeInterval = 15 * time.Second
+
+var variableReference = regexp.MustCompile(`\$\{([a-zA-Z0-9_]+)(?::[^}]*)?\}|\$([a-zA-Z0-9_]+)`)
+
+// interpolator replaces the variables of the queries by their value, like Perses does before sending a query.
+type interpolator struct {
+	values map[string]string
+	// notes explains the values which are approximated.
+	notes []string
+}
+
+func newInterpolator(dashboardObj *v1.Dashboard, timeRange Range, values map[string]string) *interpolator {
+	i := &interpolator{values: make(map[string]string)}
+	rangeDuration := timeRange.End.Sub(timeRange.Start)
+	builtins := map[string]string{
+		"__interval":      common.Duration(timeRange.Step).String(),
+		"__interval_ms":   strconv.FormatInt(timeRange.Step.Milliseconds(), 10),
+		"__rate_interval": common.Duration(max(timeRange.Step+scrapeInterval, 4*scrapeInterval)).String(),
+		"__range":         common.Duration(rangeDuration).String(),
+		"__range_s":       strconv.FormatInt(int64(rangeDuration.Seconds()), 10),
+		"__range_ms":      strconv.FormatInt(rangeDuration.Milliseconds(), 10),
+	}
+	for name, value := range builtins {
+		i.values[name] = value
+	}
+	for _, variable := range dashboardObj.Spec.Variables {
+		name := variable.Spec.GetName()
+		if value, ok := values[name]; ok {
+			i.values[name] = value
+			continue
+		}
+		switch spec := variable.Spec.(type) {
+		case *dashboard.TextVariableSpec:
+			i.values[name] = spec.Value
+		case *dashboard.ListVariableSpec:
+			switch {
+			case spec.DefaultValue != nil && len(spec.DefaultValue.SliceValues) > 0:
+				i.values[name] = formatValues(spec.DefaultValue.SliceValues)
+			case spec.DefaultValue != nil && spec.DefaultValue.SingleValue != "":
+				i.values[name] = spec.DefaultValue.SingleValue
+			case spec.AllowAllValue && spec.CustomAllValue != "":
+				i.values[name] = spec.CustomAllValue
+			case spec.AllowAllValue:
+				i.values[name] = ".*"
+				i.notes = append(i.notes, fmt.Sprintf("variable '%s' has no default value, it is set to all its values with the regular expression .*", name))
+			}
+		}
+	}
+	// the values given for variables which aren't in the dashboard are used too, e.g. to replace a variable of the project.
+	for name, value := range values {
+		if _, ok := i.values[name]; !ok {
+			i.values[name] = value
+		}
+	}
+	return i
+}
+
+// interpolate returns the expression with the variables replaced by their value, and the variables which have no value.
+func (i *interpolator) interpolate(expression string) (string, []string) {
+	var unresolved []string
+	result := variableReference.ReplaceAllStringFunc(expression, func(match string) string {
+		groups := variableReference.FindStringSubmatch(match)
+		name := groups[1] + groups[2]
+		value, ok := i.values[name]
+		if !ok {
+			if !slices.Contains(unresolved, name) {
+				unresolved = append(unresolved, name)
+			}
+			return match
+		}
+		return value
+	})
+	return result, unresolved
+}
+
+// formatValues returns the value of a variable with several values selected, as a regular expression matching any of them.
+func formatValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "(" + strings.Join(values, "|") + ")"
+}
@@ -0,0 +1,210 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package persestest provides a fake Perses API for the tests of the packages calling Perses.
+package persestest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+// Store implements the REST API of Perses for any kind, keeping the objects as raw JSON.
+// The path of an object is its resource path, e.g. "projects/shop/datasources/prometheus" or "globaldatasources/shared".
+type Store struct {
+	mutex    sync.Mutex
+	objects  map[string]json.RawMessage
+	failures map[string]int
+	requests []string
+	writes   []string
+}
+
+// New starts a fake Perses holding the given objects, and returns it with a client calling it.
+func New(t testing.TB, live ...string) (*Store, apiClient.ClientInterface) {
+	store := &Store{objects: map[string]json.RawMessage{}, failures: map[string]int{}}
+	for _, def := range live {
+		store.objects[Path(Decode(t, def))] = json.RawMessage(def)
+	}
+	srv := httptest.NewServer(store)
+	t.Cleanup(srv.Close)
+	client := apiClient.NewWithClient(&perseshttp.RESTClient{BaseURL: common.MustParseURL(srv.URL), Client: srv.Client()})
+	return store, client
+}
+
+// Path returns the resource path of the object.
+func Path(obj modelAPI.Entity) string {
+	resource := strings.ToLower(string(objects.Kind(obj))) + "s"
+	if project := objects.Project(obj); project != "" {
+		return "projects/" + project + "/" + resource + "/" + objects.Name(obj)
+	}
+	return resource + "/" + objects.Name(obj)
+}
+
+// Decode unmarshals the JSON definition of an object, whatever its kind.
+func Decode(t testing.TB, def string) modelAPI.Entity {
+	var header struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal([]byte(def), &header); err != nil {
+		t.Fatalf("invalid definition: %s", err)
+	}
+	obj, err := objects.Decode(v1.Kind(header.Kind), []byte(def))
+	if err != nil {
+		t.Fatalf("invalid definition: %s", err)
+	}
+	return obj
+}
+
+// Fail makes the requests with the given method on the given path (e.g. "PUT projects/shop/dashboards/overview") fail with the status.
+func (s *Store) Fail(request string, status int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures[request] = status
+}
+
+// Get returns the object stored at the path.
+func (s *Store) Get(path string) (json.RawMessage, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	obj, ok := s.objects[path]
+	return obj, ok
+}
+
+// Put stores the object, replacing the object with the same path.
+func (s *Store) Put(t testing.TB, def string) {
+	path := Path(Decode(t, def))
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[path] = json.RawMessage(def)
+}
+
+// Requests returns every request received, e.g. "GET projects/shop/datasources".
+func (s *Store) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Writes returns the requests which modified the objects, e.g. "POST projects/shop/datasources/loki".
+func (s *Store) Writes() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.writes)
+}
+
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	s.requests = append(s.requests, r.Method+" "+path)
+	var data []byte
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		data, _ = io.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			var obj struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			}
+			_ = json.Unmarshal(data, &obj)
+			path += "/" + obj.Metadata.Name
+		}
+	}
+	if status, ok := s.failures[r.Method+" "+path]; ok {
+		http.Error(w, `{"message":"injected failure"}`, status)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if obj, ok := s.objects[path]; ok {
+			_, _ = w.Write(obj)
+			return
+		}
+		if strings.Count(path, "/")%2 == 1 {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(s.list(path))
+	case http.MethodPost:
+		if _, ok := s.objects[path]; ok {
+			http.Error(w, `{"message":"already exists"}`, http.StatusConflict)
+			return
+		}
+		s.objects[path] = data
+		s.writes = append(s.writes, r.Method+" "+path)
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		if _, ok := s.objects[path]; !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		s.objects[path] = data
+		s.writes = append(s.writes, r.Method+" "+path)
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		if _, ok := s.objects[path]; !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		delete(s.objects, path)
+		if strings.HasPrefix(path, "projects/") && strings.Count(path, "/") == 1 {
+			// deleting a project deletes its objects.
+			for key := range s.objects {
+				if strings.HasPrefix(key, path+"/") {
+					delete(s.objects, key)
+				}
+			}
+		}
+		s.writes = append(s.writes, r.Method+" "+path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list returns the objects of a collection, sorted by path. A project collection without project
+// (e.g. "datasources") returns the objects of every project.
+func (s *Store) list(path string) []json.RawMessage {
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	crossProject := !strings.Contains(path, "/") && path != "projects"
+	result := []json.RawMessage{}
+	for _, key := range keys {
+		name, ok := strings.CutPrefix(key, path+"/")
+		if crossProject && !ok && strings.HasPrefix(key, "projects/") {
+			// projects/<project>/<resource>/<name>
+			parts := strings.Split(key, "/")
+			ok = len(parts) == 4 && parts[2] == path
+			name = parts[len(parts)-1]
+		}
+		if ok && !strings.Contains(name, "/") {
+			result = append(result, s.objects[key])
+		}
+	}
+	return result
+}
@@ -0,0 +1,156 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package prometheus queries the Prometheus datasources through the proxy of the Perses API.
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/references"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+)
+
+// DatasourceKind is the kind of the Prometheus datasources.
+const DatasourceKind = "PrometheusDatasource"
+
+// Client calls the HTTP API of a Prometheus datasource.
+type Client struct {
+	client    apiClient.ClientInterface
+	project   string
+	dashboard string
+	source    references.ResolvedDatasource
+}
+
+// NewClient returns the client of the datasource resolved from a dashboard of the project.
+// The project and the dashboard are only used when the datasource is not a global one.
+func NewClient(client apiClient.ClientInterface, project string, dashboard string, source references.ResolvedDatasource) *Client {
+	return &Client{
+		client:    client,
+		project:   project,
+		dashboard: dashboard,
+		source:    source,
+	}
+}
+
+// MetricMetadata is the metadata Prometheus exposes about a metric.
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+}
+
+// Metadata returns the metadata of the metrics scraped by Prometheus, indexed by metric name.
+func (c *Client) Metadata() (map[string][]MetricMetadata, error) {
+	var result map[string][]MetricMetadata
+	if err := c.get("metadata", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Sample is a value of a series at a point in time.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a series of a range query, with its samples in chronological order.
+type Series struct {
+	Labels  map[string]string
+	Samples []Sample
+}
+
+// QueryRange evaluates the PromQL expression over the time range, at every step.
+// The samples which aren't finite numbers (NaN, +Inf, -Inf) are left out.
+func (c *Client) QueryRange(query string, start time.Time, end time.Time, step time.Duration) ([]Series, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	var data struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]any          `json:"values"`
+		} `json:"result"`
+	}
+	if err := c.get("query_range", params, &data); err != nil {
+		return nil, err
+	}
+	if data.ResultType != "matrix" {
+		return nil, fmt.Errorf("query_range returned a result of type %q instead of a matrix", data.ResultType)
+	}
+	result := make([]Series, 0, len(data.Result))
+	for _, raw := range data.Result {
+		series := Series{Labels: raw.Metric, Samples: make([]Sample, 0, len(raw.Values))}
+		for _, pair := range raw.Values {
+			timestamp, ok := pair[0].(float64)
+			text, _ := pair[1].(string)
+			value, err := strconv.ParseFloat(text, 64)
+			if !ok || err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			series.Samples = append(series.Samples, Sample{
+				Time:  time.Unix(0, int64(timestamp*float64(time.Second))),
+				Value: value,
+			})
+		}
+		result = append(result, series)
+	}
+	return result, nil
+}
+
+// response is the envelope of the responses of Prometheus. Data must be set to a pointer to the expected data.
+type response struct {
+	Status    string `json:"status"`
+	Data      any    `json:"data"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+}
+
+type values url.Values
+
+func (v values) GetValues() url.Values {
+	return url.Values(v)
+}
+
+// get calls the endpoint /api/v1/<endpoint> of Prometheus and decodes the data of the response in result.
+func (c *Client) get(endpoint string, params url.Values, result any) error {
+	request := c.client.RESTClient().Get().
+		APIPrefix("/proxy").
+		APIVersion("").
+		Name(fmt.Sprintf("%s/api/v1/%s", c.source.Name, endpoint)).
+		Query(values(params))
+	switch c.source.Scope {
+	case references.GlobalScope:
+		request = request.Resource("globaldatasources")
+	case references.DashboardScope:
+		request = request.Project(c.project).Resource(fmt.Sprintf("dashboards/%s/datasources", c.dashboard))
+	default:
+		request = request.Project(c.project).Resource("datasources")
+	}
+	resp := response{Data: result}
+	if err := request.Do().Object(&resp); err != nil {
+		return fmt.Errorf("error calling endpoint '%s' of %s datasource '%s': %w", endpoint, c.source.Scope, c.source.Name, err)
+	}
+	if resp.Status != "success" {
+		return fmt.Errorf("endpoint '%s' of %s datasource '%s' returned %s: %s", endpoint, c.source.Scope, c.source.Name, resp.ErrorType, resp.Error)
+	}
+	return nil
+}
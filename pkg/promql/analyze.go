@@ -0,0 +1,268 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package promql analyzes the PromQL queries of the dashboards with the parser of Prometheus.
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+type IssueType string
+
+const (
+	SyntaxError    IssueType = "syntax_error"
+	RateOnGauge    IssueType = "rate_on_gauge"
+	MissingBy      IssueType = "missing_by"
+	ExpensiveRegex IssueType = "expensive_regex"
+)
+
+const (
+	panelQueryKind     = "PrometheusTimeSeriesQuery"
+	promQLVariableKind = "PrometheusPromQLVariable"
+)
+
+// Query is a PromQL query of a dashboard.
+type Query struct {
+	// Location describes where the query is in the dashboard, e.g. "panel 'cpu' query 0".
+	Location string `json:"location"`
+	// Path is the path of the query in the dashboard, e.g. "spec.panels.cpu.spec.queries[0].spec.plugin.spec.query".
+	Path       string `json:"path"`
+	Expression string `json:"expression"`
+	// Legend is the format of the series names, e.g. "{{pod}}". It is empty for the variables.
+	Legend string `json:"-"`
+	// Datasource is the name of the Prometheus datasource selected by the query, empty for the default one.
+	Datasource string `json:"-"`
+}
+
+// Issue is a problem found in a query.
+type Issue struct {
+	Type IssueType `json:"type"`
+	Query
+	Message string `json:"message"`
+	// Fix suggests how to fix the issue.
+	Fix string `json:"fix,omitempty"`
+}
+
+// MetricTypes gives the type (counter, gauge, histogram...) of the metrics, indexed by metric name.
+type MetricTypes map[string]string
+
+// Queries returns the PromQL queries of the panels and of the variables of the dashboard.
+func Queries(dashboardObj *v1.Dashboard) []Query {
+	var result []Query
+	ids := make([]string, 0, len(dashboardObj.Spec.Panels))
+	for id := range dashboardObj.Spec.Panels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		panel := dashboardObj.Spec.Panels[id]
+		if panel == nil {
+			continue
+		}
+		for i, query := range panel.Spec.Queries {
+			if query.Spec.Plugin.Kind != panelQueryKind {
+				continue
+			}
+			spec, _ := query.Spec.Plugin.Spec.(map[string]any)
+			expression, _ := spec["query"].(string)
+			if strings.TrimSpace(expression) == "" {
+				continue
+			}
+			legend, _ := spec["seriesNameFormat"].(string)
+			result = append(result, Query{
+				Location:   fmt.Sprintf("panel '%s' query %d", id, i),
+				Path:       fmt.Sprintf("spec.panels.%s.spec.queries[%d].spec.plugin.spec.query", id, i),
+				Expression: expression,
+				Legend:     legend,
+				Datasource: datasourceName(spec),
+			})
+		}
+	}
+	for i, variable := range dashboardObj.Spec.Variables {
+		listVariable, ok := variable.Spec.(*dashboard.ListVariableSpec)
+		if !ok || listVariable.Plugin.Kind != promQLVariableKind {
+			continue
+		}
+		spec, _ := listVariable.Plugin.Spec.(map[string]any)
+		expression, _ := spec["expr"].(string)
+		if strings.TrimSpace(expression) == "" {
+			continue
+		}
+		result = append(result, Query{
+			Location:   fmt.Sprintf("variable '%s'", listVariable.Name),
+			Path:       fmt.Sprintf("spec.variables[%d].spec.plugin.spec.expr", i),
+			Expression: expression,
+			Datasource: datasourceName(spec),
+		})
+	}
+	return result
+}
+
+func datasourceName(spec map[string]any) string {
+	selector, _ := spec["datasource"].(map[string]any)
+	name, _ := selector["name"].(string)
+	return name
+}
+
+// ParseExpr parses an expression of a dashboard, in which the variables are replaced by placeholders keeping it valid.
+func ParseExpr(expression string) (parser.Expr, error) {
+	return parser.ParseExpr(substituteVariables(expression))
+}
+
+// Analyze returns the issues of the query. The checks using the type of the metrics are skipped when metricTypes is nil.
+func Analyze(query Query, metricTypes MetricTypes) []Issue {
+	expr, err := ParseExpr(query.Expression)
+	if err != nil {
+		return []Issue{{
+			Type:    SyntaxError,
+			Query:   query,
+			Message: syntaxErrorMessage(err),
+			Fix:     "fix the expression, e.g. by running it in the Prometheus expression browser",
+		}}
+	}
+	var issues []Issue
+	newIssue := func(issueType IssueType, message string, fix string) {
+		issues = append(issues, Issue{Type: issueType, Query: query, Message: message, Fix: fix})
+	}
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.Call:
+			if metricTypes != nil {
+				if message, fix, ok := checkRateOnGauge(n, metricTypes); ok {
+					newIssue(RateOnGauge, message, fix)
+				}
+			}
+		case *parser.VectorSelector:
+			for _, found := range checkRegexMatchers(n) {
+				newIssue(ExpensiveRegex, found.message, found.fix)
+			}
+		}
+		return nil
+	})
+	for _, label := range legendLabels(query.Legend) {
+		if ok, droppedBy := outputLabels(expr).has(label); !ok {
+			newIssue(MissingBy,
+				fmt.Sprintf("the legend uses the label '%s' but %s removes it, so every series gets the same name", label, droppedBy),
+				fmt.Sprintf("add '%s' to the by clause of the aggregation, or remove it from the legend", label))
+		}
+	}
+	return issues
+}
+
+func syntaxErrorMessage(err error) string {
+	var parseErrors parser.ParseErrors
+	if !errors.As(err, &parseErrors) {
+		return err.Error()
+	}
+	// the positions of the errors are not reported, as they refer to the expression with the variables substituted.
+	messages := make([]string, 0, len(parseErrors))
+	for _, parseErr := range parseErrors {
+		messages = append(messages, parseErr.Err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// counterFunctions are the functions that only make sense on counters.
+var counterFunctions = map[string]string{
+	"rate":     "deriv",
+	"irate":    "deriv",
+	"increase": "delta",
+}
+
+func checkRateOnGauge(call *parser.Call, metricTypes MetricTypes) (string, string, bool) {
+	replacement, ok := counterFunctions[call.Func.Name]
+	if !ok || len(call.Args) == 0 {
+		return "", "", false
+	}
+	matrix, ok := call.Args[0].(*parser.MatrixSelector)
+	if !ok {
+		return "", "", false
+	}
+	selector, ok := matrix.VectorSelector.(*parser.VectorSelector)
+	if !ok || selector.Name == "" || metricTypes[selector.Name] != "gauge" {
+		return "", "", false
+	}
+	return fmt.Sprintf("%s() is applied to '%s', which is a gauge: the result is meaningless as a gauge can go down", call.Func.Name, selector.Name),
+		fmt.Sprintf("use %s() to get the trend of a gauge, or use the gauge directly (e.g. with avg_over_time())", replacement), true
+}
+
+type problem struct {
+	message string
+	fix     string
+}
+
+// checkRegexMatchers returns the expensive regex matchers of the selector.
+func checkRegexMatchers(selector *parser.VectorSelector) []problem {
+	var result []problem
+	hasEqualityMatcher := false
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Type == labels.MatchEqual && matcher.Value != "" {
+			hasEqualityMatcher = true
+		}
+	}
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Type != labels.MatchRegexp && matcher.Type != labels.MatchNotRegexp {
+			continue
+		}
+		if hasPlaceholder(matcher.Value) {
+			// the value comes from a variable, whose values are unknown.
+			continue
+		}
+		switch {
+		case !hasEqualityMatcher && matcher.Name == labels.MetricName:
+			result = append(result, problem{
+				fmt.Sprintf("the selector matches the metric name with the regex %s, which scans the names of every metric", matcher),
+				"select the metric by name, or add an equality matcher (e.g. job=\"...\") to narrow the series to scan",
+			})
+		case leadingWildcard.MatchString(matcher.Value):
+			result = append(result, problem{
+				fmt.Sprintf("the regex %s starts with a wildcard, so it is tested against every value of the label '%s'", matcher, matcher.Name),
+				"anchor the regex on a prefix, or list the expected values (e.g. a|b|c)",
+			})
+		case strings.HasPrefix(matcher.Value, "(?i)"):
+			result = append(result, problem{
+				fmt.Sprintf("the regex %s is case-insensitive, so it is tested against every value of the label '%s'", matcher, matcher.Name),
+				"match the exact case of the values instead",
+			})
+		}
+	}
+	return result
+}
+
+// leadingWildcard matches the regexes starting with .* or .+ followed by something else (e.g. ".*error.*"),
+// which can't be optimized into a lookup of the index.
+var leadingWildcard = regexp.MustCompile(`^\.[*+].`)
+
+// legendReference matches the labels used in a legend, e.g. {{pod}} or {{ pod }}.
+var legendReference = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+func legendLabels(legend string) []string {
+	var result []string
+	for _, match := range legendReference.FindAllStringSubmatch(legend, -1) {
+		if !slices.Contains(result, match[1]) {
+			result = append(result, match[1])
+		}
+	}
+	return result
+}
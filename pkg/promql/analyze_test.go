@@ -0,0 +1,221 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstituteVariables(t *testing.T) {
+	testSuite := []struct {
+		title      string
+		expression string
+		result     string
+	}{
+		{
+			title:      "label value",
+			expression: `up{job="$job"}`,
+			result:     `up{job="perses_variable_job"}`,
+		},
+		{
+			title:      "range and offset",
+			expression: `rate(http_requests_total[$__rate_interval] offset $shift)`,
+			result:     `rate(http_requests_total[5m] offset 5m)`,
+		},
+		{
+			title:      "subquery",
+			expression: `max_over_time(up[$range:$step])`,
+			result:     `max_over_time(up[5m:5m])`,
+		},
+		{
+			title:      "aggregation parameter",
+			expression: `topk($limit, up)`,
+			result:     `topk(1, up)`,
+		},
+		{
+			title:      "braces and format",
+			expression: `up{instance=~"${instance:regex}"} / ${metric}`,
+			result:     `up{instance=~"perses_variable_instance"} / perses_variable_metric`,
+		},
+		{
+			title:      "label name",
+			expression: `sum by ($label) (up)`,
+			result:     `sum by (perses_variable_label) (up)`,
+		},
+		{
+			title:      "string with a bracket",
+			expression: `label_replace(up, "dst", "[$value", "src", "(.*)")`,
+			result:     `label_replace(up, "dst", "[perses_variable_value", "src", "(.*)")`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.result, substituteVariables(test.expression))
+			_, err := ParseExpr(test.expression)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	metricTypes := MetricTypes{"node_memory_MemAvailable_bytes": "gauge", "http_requests_total": "counter"}
+	testSuite := []struct {
+		title       string
+		expression  string
+		legend      string
+		metricTypes MetricTypes
+		issues      []IssueType
+	}{
+		{
+			title:       "valid query",
+			expression:  `sum by (code) (rate(http_requests_total{job="$job"}[$__rate_interval]))`,
+			legend:      "{{code}}",
+			metricTypes: metricTypes,
+		},
+		{
+			title:      "syntax error",
+			expression: `sum(rate(http_requests_total[5m])`,
+			issues:     []IssueType{SyntaxError},
+		},
+		{
+			title:       "rate on a gauge",
+			expression:  `rate(node_memory_MemAvailable_bytes[5m])`,
+			metricTypes: metricTypes,
+			issues:      []IssueType{RateOnGauge},
+		},
+		{
+			title:      "rate on a gauge without the metric types",
+			expression: `rate(node_memory_MemAvailable_bytes[5m])`,
+		},
+		{
+			title:      "legend label removed by the aggregation",
+			expression: `sum by (job) (rate(http_requests_total[5m]))`,
+			legend:     "{{job}} {{ pod }}",
+			issues:     []IssueType{MissingBy},
+		},
+		{
+			title:      "legend label removed by without",
+			expression: `sum without (pod) (up)`,
+			legend:     "{{pod}}",
+			issues:     []IssueType{MissingBy},
+		},
+		{
+			title:      "legend label kept by topk",
+			expression: `topk(5, up)`,
+			legend:     "{{pod}}",
+		},
+		{
+			title:      "legend label added by label_replace",
+			expression: `label_replace(sum(up), "pod", "$1", "instance", "(.*)")`,
+			legend:     "{{pod}}",
+		},
+		{
+			title:      "legend label dropped by on",
+			expression: `up / on (job) up`,
+			legend:     "{{pod}}",
+			issues:     []IssueType{MissingBy},
+		},
+		{
+			title:      "regex on the metric name",
+			expression: `{__name__=~"http_.*"}`,
+			issues:     []IssueType{ExpensiveRegex},
+		},
+		{
+			title:      "regex on the metric name with an equality matcher",
+			expression: `{__name__=~"http_.*", job="api"}`,
+		},
+		{
+			title:      "regex with a leading wildcard",
+			expression: `up{instance=~".*:9100"}`,
+			issues:     []IssueType{ExpensiveRegex},
+		},
+		{
+			title:      "case-insensitive regex",
+			expression: `up{job=~"(?i)api"}`,
+			issues:     []IssueType{ExpensiveRegex},
+		},
+		{
+			title:      "regex from a variable",
+			expression: `up{instance=~".*$instance"}`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			query := Query{Location: "panel 'cpu' query 0", Expression: test.expression, Legend: test.legend}
+			var issues []IssueType
+			for _, issue := range Analyze(query, test.metricTypes) {
+				issues = append(issues, issue.Type)
+				assert.NotEmpty(t, issue.Message)
+				assert.Equal(t, query, issue.Query)
+			}
+			assert.Equal(t, test.issues, issues)
+		})
+	}
+}
+
+func TestSyntaxErrorMessage(t *testing.T) {
+	issues := Analyze(Query{Expression: `rate(up[$__rate_interval]`}, nil)
+	require.Len(t, issues, 1)
+	assert.NotContains(t, issues[0].Message, placeholderName)
+	assert.NotContains(t, issues[0].Message, "1:", "the positions refer to the substituted expression")
+}
+
+func TestQueries(t *testing.T) {
+	var dashboardObj v1.Dashboard
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"kind": "Dashboard",
+		"metadata": {"name": "overview", "project": "shop"},
+		"spec": {
+			"variables": [
+				{"kind": "ListVariable", "spec": {"name": "job", "plugin": {"kind": "PrometheusPromQLVariable", "spec": {"expr": "up", "datasource": {"kind": "PrometheusDatasource", "name": "thanos"}}}}},
+				{"kind": "TextVariable", "spec": {"name": "filter", "value": "up"}}
+			],
+			"panels": {
+				"memory": {"kind": "Panel", "spec": {"plugin": {"kind": "TimeSeriesChart", "spec": {}}, "queries": [
+					{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "node_memory_MemAvailable_bytes"}}}}
+				]}},
+				"cpu": {"kind": "Panel", "spec": {"plugin": {"kind": "TimeSeriesChart", "spec": {}}, "queries": [
+					{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "TempoTraceQuery", "spec": {"query": "{}"}}}},
+					{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "rate(cpu[5m])", "seriesNameFormat": "{{pod}}"}}}},
+					{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": " "}}}}
+				]}}
+			},
+			"layouts": []
+		}
+	}`), &dashboardObj))
+	assert.Equal(t, []Query{
+		{
+			Location:   "panel 'cpu' query 1",
+			Path:       "spec.panels.cpu.spec.queries[1].spec.plugin.spec.query",
+			Expression: "rate(cpu[5m])",
+			Legend:     "{{pod}}",
+		},
+		{
+			Location:   "panel 'memory' query 0",
+			Path:       "spec.panels.memory.spec.queries[0].spec.plugin.spec.query",
+			Expression: "node_memory_MemAvailable_bytes",
+		},
+		{
+			Location:   "variable 'job'",
+			Path:       "spec.variables[0].spec.plugin.spec.expr",
+			Expression: "up",
+			Datasource: "thanos",
+		},
+	}, Queries(&dashboardObj))
+}
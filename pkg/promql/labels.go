@@ -0,0 +1,215 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// labelSet describes the labels of the series returned by an expression. When restricted, the series only have
+// the labels of kept, otherwise they have every label of the selected series but the ones of dropped.
+type labelSet struct {
+	restricted bool
+	kept       []string
+	dropped    []string
+	// droppedBy describes the operation removing a label, e.g. "sum by (job)".
+	droppedBy map[string]string
+	// restrictedBy describes the operation restricting the labels to kept, for the labels missing from droppedBy.
+	restrictedBy string
+}
+
+// has returns true when the series can have the label, and otherwise the operation dropping it.
+func (s labelSet) has(label string) (bool, string) {
+	if s.restricted {
+		if slices.Contains(s.kept, label) {
+			return true, ""
+		}
+		if by, ok := s.droppedBy[label]; ok {
+			return false, by
+		}
+		return false, s.restrictedBy
+	}
+	if slices.Contains(s.dropped, label) {
+		return false, s.droppedBy[label]
+	}
+	return true, ""
+}
+
+// keep restricts the labels to the given ones.
+func (s labelSet) keep(labels []string, by string) labelSet {
+	result := labelSet{restricted: true, restrictedBy: by, droppedBy: make(map[string]string)}
+	for _, label := range labels {
+		if ok, droppedBy := s.has(label); ok {
+			result.kept = append(result.kept, label)
+		} else {
+			result.droppedBy[label] = droppedBy
+		}
+	}
+	return result
+}
+
+// drop removes the given labels.
+func (s labelSet) drop(labels []string, by string) labelSet {
+	droppedBy := make(map[string]string, len(s.droppedBy)+len(labels))
+	for label, op := range s.droppedBy {
+		droppedBy[label] = op
+	}
+	for _, label := range labels {
+		if ok, _ := s.has(label); ok {
+			droppedBy[label] = by
+		}
+	}
+	s.droppedBy = droppedBy
+	if s.restricted {
+		s.kept = slices.DeleteFunc(slices.Clone(s.kept), func(label string) bool { return slices.Contains(labels, label) })
+	} else {
+		s.dropped = append(slices.Clone(s.dropped), labels...)
+	}
+	return s
+}
+
+// add adds a label, e.g. the destination label of label_replace.
+func (s labelSet) add(label string) labelSet {
+	if s.restricted {
+		if !slices.Contains(s.kept, label) {
+			s.kept = append(slices.Clone(s.kept), label)
+		}
+		return s
+	}
+	s.dropped = slices.DeleteFunc(slices.Clone(s.dropped), func(l string) bool { return l == label })
+	return s
+}
+
+// outputLabels returns the labels of the series returned by the expression. It follows the aggregations,
+// the vector matching of the binary operations and the label functions, and considers that the other functions
+// keep the labels of their vector argument.
+func outputLabels(expr parser.Expr) labelSet {
+	switch e := expr.(type) {
+	case *parser.ParenExpr:
+		return outputLabels(e.Expr)
+	case *parser.UnaryExpr:
+		return outputLabels(e.Expr)
+	case *parser.StepInvariantExpr:
+		return outputLabels(e.Expr)
+	case *parser.SubqueryExpr:
+		return outputLabels(e.Expr)
+	case *parser.MatrixSelector:
+		return outputLabels(e.VectorSelector)
+	case *parser.AggregateExpr:
+		return aggregationLabels(e)
+	case *parser.BinaryExpr:
+		return binaryLabels(e)
+	case *parser.Call:
+		return callLabels(e)
+	case *parser.NumberLiteral, *parser.StringLiteral:
+		return labelSet{restricted: true, restrictedBy: "a literal"}
+	}
+	return labelSet{}
+}
+
+func aggregationLabels(e *parser.AggregateExpr) labelSet {
+	inner := outputLabels(e.Expr)
+	switch e.Op {
+	case parser.TOPK, parser.BOTTOMK, parser.LIMITK, parser.LIMIT_RATIO:
+		// these aggregations return the series of their argument.
+		return inner
+	}
+	op := e.Op.String()
+	var result labelSet
+	if e.Without {
+		result = inner.drop(e.Grouping, fmt.Sprintf("%s without (%s)", op, strings.Join(e.Grouping, ", ")))
+	} else {
+		by := op
+		if len(e.Grouping) > 0 {
+			by = fmt.Sprintf("%s by (%s)", op, strings.Join(e.Grouping, ", "))
+		}
+		result = inner.keep(e.Grouping, by)
+	}
+	if e.Op == parser.COUNT_VALUES {
+		if label, ok := e.Param.(*parser.StringLiteral); ok {
+			result = result.add(label.Val)
+		}
+	}
+	return result
+}
+
+func binaryLabels(e *parser.BinaryExpr) labelSet {
+	lhsScalar := e.LHS.Type() == parser.ValueTypeScalar
+	rhsScalar := e.RHS.Type() == parser.ValueTypeScalar
+	switch {
+	case lhsScalar && rhsScalar:
+		return labelSet{restricted: true, restrictedBy: "a scalar operation"}
+	case lhsScalar:
+		return outputLabels(e.RHS)
+	case rhsScalar || e.VectorMatching == nil:
+		return outputLabels(e.LHS)
+	}
+	matching := e.VectorMatching
+	switch {
+	case e.Op.IsSetOperator():
+		return outputLabels(e.LHS)
+	case matching.Card == parser.CardOneToOne && e.Op.IsComparisonOperator() && !e.ReturnBool:
+		// a comparison filters the series of the left-hand side.
+		return outputLabels(e.LHS)
+	case matching.Card == parser.CardOneToOne && matching.On:
+		return outputLabels(e.LHS).keep(matching.MatchingLabels, fmt.Sprintf("%s on (%s)", e.Op, strings.Join(matching.MatchingLabels, ", ")))
+	case matching.Card == parser.CardOneToOne:
+		return outputLabels(e.LHS).drop(matching.MatchingLabels, fmt.Sprintf("%s ignoring (%s)", e.Op, strings.Join(matching.MatchingLabels, ", ")))
+	case matching.Card == parser.CardOneToMany:
+		return outputLabels(e.RHS)
+	}
+	return outputLabels(e.LHS)
+}
+
+func callLabels(e *parser.Call) labelSet {
+	switch e.Func.Name {
+	case "label_replace", "label_join":
+		result := outputLabels(e.Args[0])
+		if label, ok := e.Args[1].(*parser.StringLiteral); ok {
+			result = result.add(label.Val)
+		}
+		return result
+	case "vector", "scalar", "time", "pi":
+		return labelSet{restricted: true, restrictedBy: e.Func.Name + "()"}
+	case "absent", "absent_over_time":
+		// absent() only keeps the labels of the equality matchers of its selector.
+		return labelSet{restricted: true, kept: equalityLabels(e.Args[0]), restrictedBy: e.Func.Name + "()"}
+	}
+	for _, arg := range e.Args {
+		if arg.Type() == parser.ValueTypeVector || arg.Type() == parser.ValueTypeMatrix {
+			return outputLabels(arg)
+		}
+	}
+	return labelSet{}
+}
+
+func equalityLabels(expr parser.Expr) []string {
+	var result []string
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if selector, ok := node.(*parser.VectorSelector); ok {
+			for _, matcher := range selector.LabelMatchers {
+				if matcher.Type == labels.MatchEqual && matcher.Name != "__name__" {
+					result = append(result, matcher.Name)
+				}
+			}
+		}
+		return nil
+	})
+	return result
+}
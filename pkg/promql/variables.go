@@ -0,0 +1,90 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package promql
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// placeholderDuration replaces the variables used as a duration, e.g. [$__rate_interval].
+	placeholderDuration = "5m"
+	// placeholderNumber replaces the variables used as the parameter of an aggregation, e.g. topk($limit, ...).
+	placeholderNumber = "1"
+	// placeholderName prefixes the name replacing the other variables, so that they remain recognizable after the substitution.
+	placeholderName = "perses_variable_"
+)
+
+var (
+	variableReference = regexp.MustCompile(`\$\{([a-zA-Z0-9_]+)(?::[^}]*)?\}|\$([a-zA-Z0-9_]+)`)
+	offsetKeyword     = regexp.MustCompile(`\boffset\s*$`)
+	numberParameter   = regexp.MustCompile(`\b(?:topk|bottomk|limitk|limit_ratio|quantile|quantile_over_time|histogram_quantile)\s*\(\s*$`)
+)
+
+// substituteVariables replaces the variables of the expression by placeholders that keep the expression valid,
+// as the PromQL parser doesn't know about the dashboard variables. The replacement depends on where the variable is used:
+// a duration in a range or an offset, a number as the parameter of an aggregation, and a name everywhere else
+// (label value, metric name, label name).
+func substituteVariables(expression string) string {
+	var result strings.Builder
+	last := 0
+	inString := false
+	var quote byte
+	for _, match := range variableReference.FindAllStringSubmatchIndex(expression, -1) {
+		// track whether the variable is in a string literal, by scanning the characters since the previous variable.
+		for i := last; i < match[0]; i++ {
+			switch c := expression[i]; {
+			case inString && c == '\\':
+				i++
+			case inString && c == quote:
+				inString = false
+			case !inString && (c == '"' || c == '\'' || c == '`'):
+				inString, quote = true, c
+			}
+		}
+		result.WriteString(expression[last:match[0]])
+		before := strings.TrimRight(expression[:match[0]], " \t\n")
+		var name string
+		if match[2] >= 0 {
+			name = expression[match[2]:match[3]]
+		} else {
+			name = expression[match[4]:match[5]]
+		}
+		switch {
+		case inString:
+			result.WriteString(placeholderName + name)
+		case inRange(before) || offsetKeyword.MatchString(before):
+			result.WriteString(placeholderDuration)
+		case numberParameter.MatchString(before):
+			result.WriteString(placeholderNumber)
+		default:
+			result.WriteString(placeholderName + name)
+		}
+		last = match[1]
+	}
+	result.WriteString(expression[last:])
+	return result.String()
+}
+
+// inRange returns true when the text ends in an open range or subquery bracket, e.g. "rate(foo[" or "foo[1h:".
+func inRange(text string) bool {
+	open := strings.LastIndexByte(text, '[')
+	return open >= 0 && !strings.ContainsRune(text[open:], ']')
+}
+
+// hasPlaceholder returns true when the value contains a variable replaced by substituteVariables.
+func hasPlaceholder(value string) bool {
+	return strings.Contains(value, placeholderName)
+}
@@ -0,0 +1,497 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// NewClient wraps the given client so that every create, update and delete call is rejected
+// when it targets an object matched by one of the rules.
+// If no rule is provided, the client is returned as is.
+func NewClient(client apiClient.ClientInterface, rules []Rule) apiClient.ClientInterface {
+	if len(rules) == 0 {
+		return client
+	}
+	return &protectedClient{
+		ClientInterface: client,
+		guard:           NewGuard(rules),
+	}
+}
+
+type protectedClient struct {
+	apiClient.ClientInterface
+	guard *Guard
+}
+
+func (c *protectedClient) Dashboard(project string) apiClient.DashboardInterface {
+	return &protectedDashboard{DashboardInterface: c.ClientInterface.Dashboard(project), guard: c.guard, project: project}
+}
+
+type protectedDashboard struct {
+	apiClient.DashboardInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedDashboard) Create(entity *v1.Dashboard) (*v1.Dashboard, error) {
+	if err := checkCreate(d.guard, v1.KindDashboard, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.DashboardInterface.Create(entity)
+}
+
+func (d *protectedDashboard) Update(entity *v1.Dashboard) (*v1.Dashboard, error) {
+	if err := checkUpdate(d.guard, v1.KindDashboard, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.DashboardInterface.Update(entity)
+}
+
+func (d *protectedDashboard) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindDashboard, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.DashboardInterface.Delete(name)
+}
+
+func (c *protectedClient) Datasource(project string) apiClient.DatasourceInterface {
+	return &protectedDatasource{DatasourceInterface: c.ClientInterface.Datasource(project), guard: c.guard, project: project}
+}
+
+type protectedDatasource struct {
+	apiClient.DatasourceInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedDatasource) Create(entity *v1.Datasource) (*v1.Datasource, error) {
+	if err := checkCreate(d.guard, v1.KindDatasource, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.DatasourceInterface.Create(entity)
+}
+
+func (d *protectedDatasource) Update(entity *v1.Datasource) (*v1.Datasource, error) {
+	if err := checkUpdate(d.guard, v1.KindDatasource, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.DatasourceInterface.Update(entity)
+}
+
+func (d *protectedDatasource) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindDatasource, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.DatasourceInterface.Delete(name)
+}
+
+func (c *protectedClient) EphemeralDashboard(project string) apiClient.EphemeralDashboardInterface {
+	return &protectedEphemeralDashboard{EphemeralDashboardInterface: c.ClientInterface.EphemeralDashboard(project), guard: c.guard, project: project}
+}
+
+type protectedEphemeralDashboard struct {
+	apiClient.EphemeralDashboardInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedEphemeralDashboard) Create(entity *v1.EphemeralDashboard) (*v1.EphemeralDashboard, error) {
+	if err := checkCreate(d.guard, v1.KindEphemeralDashboard, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.EphemeralDashboardInterface.Create(entity)
+}
+
+func (d *protectedEphemeralDashboard) Update(entity *v1.EphemeralDashboard) (*v1.EphemeralDashboard, error) {
+	if err := checkUpdate(d.guard, v1.KindEphemeralDashboard, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.EphemeralDashboardInterface.Update(entity)
+}
+
+func (d *protectedEphemeralDashboard) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindEphemeralDashboard, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.EphemeralDashboardInterface.Delete(name)
+}
+
+func (c *protectedClient) Folder(project string) apiClient.FolderInterface {
+	return &protectedFolder{FolderInterface: c.ClientInterface.Folder(project), guard: c.guard, project: project}
+}
+
+type protectedFolder struct {
+	apiClient.FolderInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedFolder) Create(entity *v1.Folder) (*v1.Folder, error) {
+	if err := checkCreate(d.guard, v1.KindFolder, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.FolderInterface.Create(entity)
+}
+
+func (d *protectedFolder) Update(entity *v1.Folder) (*v1.Folder, error) {
+	if err := checkUpdate(d.guard, v1.KindFolder, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.FolderInterface.Update(entity)
+}
+
+func (d *protectedFolder) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindFolder, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.FolderInterface.Delete(name)
+}
+
+func (c *protectedClient) GlobalDatasource() apiClient.GlobalDatasourceInterface {
+	return &protectedGlobalDatasource{GlobalDatasourceInterface: c.ClientInterface.GlobalDatasource(), guard: c.guard}
+}
+
+type protectedGlobalDatasource struct {
+	apiClient.GlobalDatasourceInterface
+	guard *Guard
+}
+
+func (d *protectedGlobalDatasource) Create(entity *v1.GlobalDatasource) (*v1.GlobalDatasource, error) {
+	if err := checkCreate(d.guard, v1.KindGlobalDatasource, "", entity); err != nil {
+		return nil, err
+	}
+	return d.GlobalDatasourceInterface.Create(entity)
+}
+
+func (d *protectedGlobalDatasource) Update(entity *v1.GlobalDatasource) (*v1.GlobalDatasource, error) {
+	if err := checkUpdate(d.guard, v1.KindGlobalDatasource, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.GlobalDatasourceInterface.Update(entity)
+}
+
+func (d *protectedGlobalDatasource) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindGlobalDatasource, "", name, d.Get); err != nil {
+		return err
+	}
+	return d.GlobalDatasourceInterface.Delete(name)
+}
+
+func (c *protectedClient) GlobalRole() apiClient.GlobalRoleInterface {
+	return &protectedGlobalRole{GlobalRoleInterface: c.ClientInterface.GlobalRole(), guard: c.guard}
+}
+
+type protectedGlobalRole struct {
+	apiClient.GlobalRoleInterface
+	guard *Guard
+}
+
+func (d *protectedGlobalRole) Create(entity *v1.GlobalRole) (*v1.GlobalRole, error) {
+	if err := checkCreate(d.guard, v1.KindGlobalRole, "", entity); err != nil {
+		return nil, err
+	}
+	return d.GlobalRoleInterface.Create(entity)
+}
+
+func (d *protectedGlobalRole) Update(entity *v1.GlobalRole) (*v1.GlobalRole, error) {
+	if err := checkUpdate(d.guard, v1.KindGlobalRole, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.GlobalRoleInterface.Update(entity)
+}
+
+func (d *protectedGlobalRole) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindGlobalRole, "", name, d.Get); err != nil {
+		return err
+	}
+	return d.GlobalRoleInterface.Delete(name)
+}
+
+func (c *protectedClient) GlobalRoleBinding() apiClient.GlobalRoleBindingInterface {
+	return &protectedGlobalRoleBinding{GlobalRoleBindingInterface: c.ClientInterface.GlobalRoleBinding(), guard: c.guard}
+}
+
+type protectedGlobalRoleBinding struct {
+	apiClient.GlobalRoleBindingInterface
+	guard *Guard
+}
+
+func (d *protectedGlobalRoleBinding) Create(entity *v1.GlobalRoleBinding) (*v1.GlobalRoleBinding, error) {
+	if err := checkCreate(d.guard, v1.KindGlobalRoleBinding, "", entity); err != nil {
+		return nil, err
+	}
+	return d.GlobalRoleBindingInterface.Create(entity)
+}
+
+func (d *protectedGlobalRoleBinding) Update(entity *v1.GlobalRoleBinding) (*v1.GlobalRoleBinding, error) {
+	if err := checkUpdate(d.guard, v1.KindGlobalRoleBinding, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.GlobalRoleBindingInterface.Update(entity)
+}
+
+func (d *protectedGlobalRoleBinding) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindGlobalRoleBinding, "", name, d.Get); err != nil {
+		return err
+	}
+	return d.GlobalRoleBindingInterface.Delete(name)
+}
+
+func (c *protectedClient) GlobalSecret() apiClient.GlobalSecretInterface {
+	return &protectedGlobalSecret{GlobalSecretInterface: c.ClientInterface.GlobalSecret(), guard: c.guard}
+}
+
+type protectedGlobalSecret struct {
+	apiClient.GlobalSecretInterface
+	guard *Guard
+}
+
+func (d *protectedGlobalSecret) Create(entity *v1.GlobalSecret) (*v1.GlobalSecret, error) {
+	if err := checkCreate(d.guard, v1.KindGlobalSecret, "", entity); err != nil {
+		return nil, err
+	}
+	return d.GlobalSecretInterface.Create(entity)
+}
+
+func (d *protectedGlobalSecret) Update(entity *v1.GlobalSecret) (*v1.GlobalSecret, error) {
+	if err := checkUpdate(d.guard, v1.KindGlobalSecret, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.GlobalSecretInterface.Update(entity)
+}
+
+func (d *protectedGlobalSecret) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindGlobalSecret, "", name, d.Get); err != nil {
+		return err
+	}
+	return d.GlobalSecretInterface.Delete(name)
+}
+
+func (c *protectedClient) GlobalVariable() apiClient.GlobalVariableInterface {
+	return &protectedGlobalVariable{GlobalVariableInterface: c.ClientInterface.GlobalVariable(), guard: c.guard}
+}
+
+type protectedGlobalVariable struct {
+	apiClient.GlobalVariableInterface
+	guard *Guard
+}
+
+func (d *protectedGlobalVariable) Create(entity *v1.GlobalVariable) (*v1.GlobalVariable, error) {
+	if err := checkCreate(d.guard, v1.KindGlobalVariable, "", entity); err != nil {
+		return nil, err
+	}
+	return d.GlobalVariableInterface.Create(entity)
+}
+
+func (d *protectedGlobalVariable) Update(entity *v1.GlobalVariable) (*v1.GlobalVariable, error) {
+	if err := checkUpdate(d.guard, v1.KindGlobalVariable, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.GlobalVariableInterface.Update(entity)
+}
+
+func (d *protectedGlobalVariable) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindGlobalVariable, "", name, d.Get); err != nil {
+		return err
+	}
+	return d.GlobalVariableInterface.Delete(name)
+}
+
+func (c *protectedClient) Project() apiClient.ProjectInterface {
+	return &protectedProject{ProjectInterface: c.ClientInterface.Project(), guard: c.guard, client: c.ClientInterface}
+}
+
+type protectedProject struct {
+	apiClient.ProjectInterface
+	guard  *Guard
+	client apiClient.ClientInterface
+}
+
+func (d *protectedProject) Create(entity *v1.Project) (*v1.Project, error) {
+	if err := checkCreate(d.guard, v1.KindProject, "", entity); err != nil {
+		return nil, err
+	}
+	return d.ProjectInterface.Create(entity)
+}
+
+func (d *protectedProject) Update(entity *v1.Project) (*v1.Project, error) {
+	if err := checkUpdate(d.guard, v1.KindProject, "", entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.ProjectInterface.Update(entity)
+}
+
+func (d *protectedProject) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindProject, "", name, d.Get); err != nil {
+		return err
+	}
+	if err := d.checkCascade(name); err != nil {
+		return err
+	}
+	return d.ProjectInterface.Delete(name)
+}
+
+func (c *protectedClient) Role(project string) apiClient.RoleInterface {
+	return &protectedRole{RoleInterface: c.ClientInterface.Role(project), guard: c.guard, project: project}
+}
+
+type protectedRole struct {
+	apiClient.RoleInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedRole) Create(entity *v1.Role) (*v1.Role, error) {
+	if err := checkCreate(d.guard, v1.KindRole, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.RoleInterface.Create(entity)
+}
+
+func (d *protectedRole) Update(entity *v1.Role) (*v1.Role, error) {
+	if err := checkUpdate(d.guard, v1.KindRole, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.RoleInterface.Update(entity)
+}
+
+func (d *protectedRole) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindRole, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.RoleInterface.Delete(name)
+}
+
+func (c *protectedClient) RoleBinding(project string) apiClient.RoleBindingInterface {
+	return &protectedRoleBinding{RoleBindingInterface: c.ClientInterface.RoleBinding(project), guard: c.guard, project: project}
+}
+
+type protectedRoleBinding struct {
+	apiClient.RoleBindingInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedRoleBinding) Create(entity *v1.RoleBinding) (*v1.RoleBinding, error) {
+	if err := checkCreate(d.guard, v1.KindRoleBinding, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.RoleBindingInterface.Create(entity)
+}
+
+func (d *protectedRoleBinding) Update(entity *v1.RoleBinding) (*v1.RoleBinding, error) {
+	if err := checkUpdate(d.guard, v1.KindRoleBinding, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.RoleBindingInterface.Update(entity)
+}
+
+func (d *protectedRoleBinding) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindRoleBinding, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.RoleBindingInterface.Delete(name)
+}
+
+func (c *protectedClient) Secret(project string) apiClient.SecretInterface {
+	return &protectedSecret{SecretInterface: c.ClientInterface.Secret(project), guard: c.guard, project: project}
+}
+
+type protectedSecret struct {
+	apiClient.SecretInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedSecret) Create(entity *v1.Secret) (*v1.Secret, error) {
+	if err := checkCreate(d.guard, v1.KindSecret, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.SecretInterface.Create(entity)
+}
+
+func (d *protectedSecret) Update(entity *v1.Secret) (*v1.Secret, error) {
+	if err := checkUpdate(d.guard, v1.KindSecret, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.SecretInterface.Update(entity)
+}
+
+func (d *protectedSecret) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindSecret, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.SecretInterface.Delete(name)
+}
+
+func (c *protectedClient) Variable(project string) apiClient.VariableInterface {
+	return &protectedVariable{VariableInterface: c.ClientInterface.Variable(project), guard: c.guard, project: project}
+}
+
+type protectedVariable struct {
+	apiClient.VariableInterface
+	guard   *Guard
+	project string
+}
+
+func (d *protectedVariable) Create(entity *v1.Variable) (*v1.Variable, error) {
+	if err := checkCreate(d.guard, v1.KindVariable, d.project, entity); err != nil {
+		return nil, err
+	}
+	return d.VariableInterface.Create(entity)
+}
+
+func (d *protectedVariable) Update(entity *v1.Variable) (*v1.Variable, error) {
+	if err := checkUpdate(d.guard, v1.KindVariable, d.project, entity, d.Get); err != nil {
+		return nil, err
+	}
+	return d.VariableInterface.Update(entity)
+}
+
+func (d *protectedVariable) Delete(name string) error {
+	if err := checkDelete(d.guard, v1.KindVariable, d.project, name, d.Get); err != nil {
+		return err
+	}
+	return d.VariableInterface.Delete(name)
+}
+
+// checkCascade verifies the objects that would be deleted along with the project.
+// It relies on the unprotected client, as the checks only need to read the objects.
+func (d *protectedProject) checkCascade(project string) error {
+	client := d.client
+	checks := []func() error{
+		func() error { return checkChildren(d.guard, v1.KindDashboard, project, client.Dashboard(project).List) },
+		func() error {
+			return checkChildren(d.guard, v1.KindDatasource, project, client.Datasource(project).List)
+		},
+		func() error {
+			return checkChildren(d.guard, v1.KindEphemeralDashboard, project, client.EphemeralDashboard(project).List)
+		},
+		func() error { return checkChildren(d.guard, v1.KindFolder, project, client.Folder(project).List) },
+		func() error { return checkChildren(d.guard, v1.KindRole, project, client.Role(project).List) },
+		func() error {
+			return checkChildren(d.guard, v1.KindRoleBinding, project, client.RoleBinding(project).List)
+		},
+		func() error { return checkChildren(d.guard, v1.KindSecret, project, client.Secret(project).List) },
+		func() error { return checkChildren(d.guard, v1.KindVariable, project, client.Variable(project).List) },
+	}
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/perses/common/set"
+	"github.com/perses/mcp-server/pkg/objects"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// ErrProtected is returned when a write targets an object matched by a protection rule.
+var ErrProtected = errors.New("object is protected")
+
+// Rule describes a set of Perses objects that can never be modified through the MCP server.
+// Every field that is set must match for the rule to apply. Kind is matched exactly,
+// Project and Name are glob patterns (see path.Match) and Tag must be one of the object's metadata tags.
+type Rule struct {
+	// Kind is the Perses kind to protect (e.g. "Dashboard", "GlobalDatasource"). Empty matches every kind.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Project is a glob pattern matched against the project of the object.
+	// For a Project object, the pattern is matched against the project name itself.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	// Name is a glob pattern matched against the name of the object.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Tag is a metadata tag that marks an object as protected.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+}
+
+func (r *Rule) Verify() error {
+	if r.Kind == "" && r.Project == "" && r.Name == "" && r.Tag == "" {
+		return fmt.Errorf("at least one of kind, project, name or tag must be set")
+	}
+	if r.Kind != "" {
+		kind, err := v1.GetKind(r.Kind)
+		if err != nil {
+			return err
+		}
+		r.Kind = string(*kind)
+	}
+	for _, pattern := range []string{r.Project, r.Name} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) String() string {
+	return fmt.Sprintf("kind=%q project=%q name=%q tag=%q", r.Kind, r.Project, r.Name, r.Tag)
+}
+
+func (r *Rule) matchKindAndProject(kind v1.Kind, project string) bool {
+	if r.Kind != "" && r.Kind != string(kind) {
+		return false
+	}
+	if r.Project != "" {
+		if matched, _ := path.Match(r.Project, project); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Rule) match(obj object) bool {
+	if !r.matchKindAndProject(obj.kind, obj.project) {
+		return false
+	}
+	if r.Name != "" {
+		if matched, _ := path.Match(r.Name, obj.name); !matched {
+			return false
+		}
+	}
+	if r.Tag != "" && !obj.tags.Contains(r.Tag) {
+		return false
+	}
+	return true
+}
+
+// object is the subset of a Perses entity used to evaluate the protection rules.
+type object struct {
+	kind    v1.Kind
+	project string
+	name    string
+	tags    set.Set[string]
+}
+
+func newObject(kind v1.Kind, project string, entity modelAPI.Entity) object {
+	obj := object{kind: kind, project: project}
+	switch metadata := entity.GetMetadata().(type) {
+	case *v1.ProjectMetadata:
+		obj.name = metadata.Name
+		obj.tags = metadata.Tags
+		if obj.project == "" {
+			obj.project = metadata.Project
+		}
+	case *v1.Metadata:
+		obj.name = metadata.Name
+		obj.tags = metadata.Tags
+	default:
+		obj.name = metadata.GetName()
+	}
+	if kind == v1.KindProject {
+		obj.project = obj.name
+	}
+	return obj
+}
+
+func newObjectFromName(kind v1.Kind, project string, name string) object {
+	obj := object{kind: kind, project: project, name: name}
+	if kind == v1.KindProject {
+		obj.project = name
+	}
+	return obj
+}
+
+func (o object) String() string {
+	if o.kind == v1.KindProject || o.project == "" {
+		return fmt.Sprintf("%s '%s'", o.kind, o.name)
+	}
+	return fmt.Sprintf("%s '%s' in project '%s'", o.kind, o.name, o.project)
+}
+
+// Guard evaluates the protection rules against the objects targeted by a write.
+type Guard struct {
+	rules []Rule
+}
+
+func NewGuard(rules []Rule) *Guard {
+	return &Guard{rules: rules}
+}
+
+func (g *Guard) check(obj object) error {
+	for _, rule := range g.rules {
+		if rule.match(obj) {
+			return fmt.Errorf("%w: %s cannot be modified through MCP (protected by rule %s)", ErrProtected, obj, rule.String())
+		}
+	}
+	return nil
+}
+
+// mayProtectChildren returns true when at least one rule could match an object of the given kind inside the project.
+func (g *Guard) mayProtectChildren(kind v1.Kind, project string) bool {
+	for _, rule := range g.rules {
+		if rule.matchKindAndProject(kind, project) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkCreate[T modelAPI.Entity](g *Guard, kind v1.Kind, project string, entity T) error {
+	return g.check(newObject(kind, project, entity))
+}
+
+// checkUpdate verifies both the new version of the entity and the stored one,
+// so that a protection tag cannot be removed through an update.
+func checkUpdate[T modelAPI.Entity](g *Guard, kind v1.Kind, project string, entity T, get func(name string) (T, error)) error {
+	obj := newObject(kind, project, entity)
+	if err := g.check(obj); err != nil {
+		return err
+	}
+	existing, err := get(obj.name)
+	if objects.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		// The rules on the stored object (e.g. its tags) can't be verified, so the update is refused.
+		return fmt.Errorf("unable to verify the protection of %s: %w", obj, err)
+	}
+	return g.check(newObject(kind, project, existing))
+}
+
+// checkDelete verifies the stored entity. Only the kind, project and name rules can be verified when it doesn't exist.
+func checkDelete[T modelAPI.Entity](g *Guard, kind v1.Kind, project string, name string, get func(name string) (T, error)) error {
+	existing, err := get(name)
+	if objects.IsNotFound(err) {
+		return g.check(newObjectFromName(kind, project, name))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to verify the protection of %s: %w", newObjectFromName(kind, project, name), err)
+	}
+	return g.check(newObject(kind, project, existing))
+}
+
+// checkChildren verifies every object returned by list. It is used before deleting a project,
+// since Perses removes every object of the project along with it.
+func checkChildren[T modelAPI.Entity](g *Guard, kind v1.Kind, project string, list func(prefix string) ([]T, error)) error {
+	if !g.mayProtectChildren(kind, project) {
+		return nil
+	}
+	entities, err := list("")
+	if err != nil {
+		return fmt.Errorf("unable to verify the protected %s objects of project '%s': %w", kind, project, err)
+	}
+	for _, entity := range entities {
+		if err := g.check(newObject(kind, project, entity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protection
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/perses/common/set"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func dashboard(project string, name string, tags ...string) *v1.Dashboard {
+	return &v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata:               v1.Metadata{Name: name, Tags: set.New(tags...)},
+			ProjectMetadataWrapper: v1.ProjectMetadataWrapper{Project: project},
+		},
+	}
+}
+
+func getReturning(entity *v1.Dashboard, err error) func(string) (*v1.Dashboard, error) {
+	return func(string) (*v1.Dashboard, error) {
+		return entity, err
+	}
+}
+
+var (
+	errNotFound = &perseshttp.RequestError{Message: "not found", StatusCode: http.StatusNotFound}
+	errInternal = &perseshttp.RequestError{Message: "internal error", StatusCode: http.StatusInternalServerError}
+)
+
+func TestRuleVerify(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		rule    Rule
+		kind    string
+		wantErr bool
+	}{
+		{
+			title:   "empty rule",
+			rule:    Rule{},
+			wantErr: true,
+		},
+		{
+			title: "kind is normalized",
+			rule:  Rule{Kind: "dashboard"},
+			kind:  "Dashboard",
+		},
+		{
+			title:   "unknown kind",
+			rule:    Rule{Kind: "Unknown"},
+			wantErr: true,
+		},
+		{
+			title:   "invalid name pattern",
+			rule:    Rule{Name: "prod-["},
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := test.rule.Verify()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.kind, test.rule.Kind)
+		})
+	}
+}
+
+func TestGuardCheck(t *testing.T) {
+	testSuite := []struct {
+		title     string
+		rules     []Rule
+		obj       object
+		protected bool
+	}{
+		{
+			title:     "name glob matches",
+			rules:     []Rule{{Name: "prod-*"}},
+			obj:       newObject(v1.KindDashboard, "shop", dashboard("shop", "prod-overview")),
+			protected: true,
+		},
+		{
+			title: "name glob doesn't match",
+			rules: []Rule{{Name: "prod-*"}},
+			obj:   newObject(v1.KindDashboard, "shop", dashboard("shop", "staging-overview")),
+		},
+		{
+			title:     "project glob matches",
+			rules:     []Rule{{Project: "team-?"}},
+			obj:       newObject(v1.KindDashboard, "team-a", dashboard("team-a", "overview")),
+			protected: true,
+		},
+		{
+			title:     "project rule matches the project itself",
+			rules:     []Rule{{Project: "shop"}},
+			obj:       newObjectFromName(v1.KindProject, "", "shop"),
+			protected: true,
+		},
+		{
+			title:     "tag matches",
+			rules:     []Rule{{Tag: "protected"}},
+			obj:       newObject(v1.KindDashboard, "shop", dashboard("shop", "overview", "team", "protected")),
+			protected: true,
+		},
+		{
+			title: "tag missing",
+			rules: []Rule{{Tag: "protected"}},
+			obj:   newObject(v1.KindDashboard, "shop", dashboard("shop", "overview", "team")),
+		},
+		{
+			title: "tag can't match an object known by its name only",
+			rules: []Rule{{Tag: "protected"}},
+			obj:   newObjectFromName(v1.KindDashboard, "shop", "overview"),
+		},
+		{
+			title:     "kind matches",
+			rules:     []Rule{{Kind: string(v1.KindDashboard), Project: "shop"}},
+			obj:       newObjectFromName(v1.KindDashboard, "shop", "overview"),
+			protected: true,
+		},
+		{
+			title: "kind doesn't match",
+			rules: []Rule{{Kind: string(v1.KindDatasource), Project: "shop"}},
+			obj:   newObjectFromName(v1.KindDashboard, "shop", "overview"),
+		},
+		{
+			title: "every field of the rule must match",
+			rules: []Rule{{Kind: string(v1.KindDashboard), Name: "prod-*", Tag: "protected"}},
+			obj:   newObject(v1.KindDashboard, "shop", dashboard("shop", "prod-overview")),
+		},
+		{
+			title:     "any rule matches",
+			rules:     []Rule{{Name: "prod-*"}, {Tag: "protected"}},
+			obj:       newObject(v1.KindDashboard, "shop", dashboard("shop", "overview", "protected")),
+			protected: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := NewGuard(test.rules).check(test.obj)
+			if test.protected {
+				assert.ErrorIs(t, err, ErrProtected)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	guard := NewGuard([]Rule{{Tag: "protected"}})
+	testSuite := []struct {
+		title     string
+		entity    *v1.Dashboard
+		get       func(string) (*v1.Dashboard, error)
+		protected bool
+		wantErr   bool
+	}{
+		{
+			title:  "unprotected object",
+			entity: dashboard("shop", "overview"),
+			get:    getReturning(dashboard("shop", "overview"), nil),
+		},
+		{
+			title:     "new version is protected",
+			entity:    dashboard("shop", "overview", "protected"),
+			get:       getReturning(dashboard("shop", "overview"), nil),
+			protected: true,
+		},
+		{
+			title:     "tag removed from the stored object",
+			entity:    dashboard("shop", "overview"),
+			get:       getReturning(dashboard("shop", "overview", "protected"), nil),
+			protected: true,
+		},
+		{
+			title:  "stored object doesn't exist",
+			entity: dashboard("shop", "overview"),
+			get:    getReturning(nil, errNotFound),
+		},
+		{
+			title:   "stored object can't be read",
+			entity:  dashboard("shop", "overview"),
+			get:     getReturning(nil, errInternal),
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := checkUpdate(guard, v1.KindDashboard, "shop", test.entity, test.get)
+			switch {
+			case test.protected:
+				assert.ErrorIs(t, err, ErrProtected)
+			case test.wantErr:
+				assert.Error(t, err)
+				assert.False(t, errors.Is(err, ErrProtected))
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckDelete(t *testing.T) {
+	guard := NewGuard([]Rule{{Tag: "protected"}, {Name: "prod-*"}})
+	testSuite := []struct {
+		title     string
+		name      string
+		get       func(string) (*v1.Dashboard, error)
+		protected bool
+		wantErr   bool
+	}{
+		{
+			title: "unprotected object",
+			name:  "overview",
+			get:   getReturning(dashboard("shop", "overview"), nil),
+		},
+		{
+			title:     "stored object is tagged",
+			name:      "overview",
+			get:       getReturning(dashboard("shop", "overview", "protected"), nil),
+			protected: true,
+		},
+		{
+			title:     "missing object is checked by name",
+			name:      "prod-overview",
+			get:       getReturning(nil, errNotFound),
+			protected: true,
+		},
+		{
+			title: "missing object with an unprotected name",
+			name:  "overview",
+			get:   getReturning(nil, errNotFound),
+		},
+		{
+			title:   "stored object can't be read",
+			name:    "overview",
+			get:     getReturning(nil, errInternal),
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := checkDelete(guard, v1.KindDashboard, "shop", test.name, test.get)
+			switch {
+			case test.protected:
+				assert.ErrorIs(t, err, ErrProtected)
+			case test.wantErr:
+				assert.Error(t, err)
+				assert.False(t, errors.Is(err, ErrProtected))
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
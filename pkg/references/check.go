@@ -0,0 +1,295 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package references
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type IssueType string
+
+const (
+	DanglingDatasource IssueType = "dangling_datasource"
+	UndefinedVariable  IssueType = "undefined_variable"
+	UnusedVariable     IssueType = "unused_variable"
+	MissingRole        IssueType = "missing_role"
+	MissingSecret      IssueType = "missing_secret"
+	// AmbiguousDefaultDatasource is reported for each default datasource of a kind ignored in favor of another one of the same scope.
+	AmbiguousDefaultDatasource IssueType = "ambiguous_default_datasource"
+)
+
+// Issue is a broken or useless reference found in an object.
+type Issue struct {
+	Type    IssueType `json:"type"`
+	Kind    v1.Kind   `json:"kind"`
+	Project string    `json:"project,omitempty"`
+	Name    string    `json:"name"`
+	// Location describes where the reference is in the object, e.g. "panel 'cpu' query 0".
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description"`
+}
+
+// Check returns the broken references of the inventory: the datasource selectors and the role bindings
+// pointing at objects that don't exist, the plugins without datasource selector having no default datasource,
+// the default datasources conflicting with another one, the variables used but not defined, and the variables defined but not used.
+// The global objects are only checked when the inventory holds every project, as their usages are not all known otherwise.
+// The checks depending on the skipped kinds of the inventory are not done, e.g. the missing secrets when the secrets were not loaded.
+func Check(inventory *Inventory) ([]Issue, error) {
+	issues := []Issue{}
+	kinds := inventory.datasourceKinds()
+	usedGlobalVariables := make(map[string]bool)
+	globalVariables := make([]string, 0, len(inventory.GlobalVariables))
+	for _, variable := range inventory.GlobalVariables {
+		globalVariables = append(globalVariables, variable.Metadata.Name)
+	}
+
+	if inventory.AllProjects {
+		globalIssues, err := inventory.checkGlobal(kinds, globalVariables, usedGlobalVariables)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, globalIssues...)
+	}
+	for _, project := range inventory.Projects {
+		projectIssues, err := inventory.checkProject(project, kinds, globalVariables, usedGlobalVariables)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, projectIssues...)
+	}
+
+	// the global variables can be used by the variables and the dashboards of every project.
+	if inventory.AllProjects && inventory.loaded(v1.KindVariable, v1.KindDashboard) {
+		for _, variable := range inventory.GlobalVariables {
+			if !usedGlobalVariables[variable.Metadata.Name] {
+				issues = append(issues, newIssue(UnusedVariable, variable, "", "the variable is not used by any dashboard or variable"))
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (i *Inventory) checkGlobal(kinds []string, globalVariables []string, usedGlobalVariables map[string]bool) ([]Issue, error) {
+	var issues []Issue
+	for _, conflict := range defaultConflicts(i.globalDatasourceSpecs()) {
+		for _, datasource := range i.GlobalDatasources {
+			if slices.Contains(conflict.names[1:], datasource.Metadata.Name) {
+				issues = append(issues, newIssue(AmbiguousDefaultDatasource, datasource, "", ambiguousDescription(conflict)))
+			}
+		}
+	}
+	for _, variable := range i.GlobalVariables {
+		if i.loaded(v1.KindGlobalDatasource) {
+			issues = append(issues, i.checkVariableDatasource(nil, variable, variable.Spec, kinds)...)
+		}
+		refs, err := variableReferences(variable)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range refs {
+			usedGlobalVariables[name] = true
+			if !slices.Contains(globalVariables, name) {
+				issues = append(issues, undefinedVariable(variable, "", name))
+			}
+		}
+	}
+	if i.loaded(v1.KindGlobalSecret) {
+		for _, datasource := range i.GlobalDatasources {
+			if secret := datasourceSecret(datasource.Spec); secret != "" && !slices.ContainsFunc(i.GlobalSecrets, func(s *v1.GlobalSecret) bool { return s.Metadata.Name == secret }) {
+				issues = append(issues, newIssue(MissingSecret, datasource, "", fmt.Sprintf("global secret '%s' doesn't exist", secret)))
+			}
+		}
+	}
+	if i.loaded(v1.KindGlobalRole) {
+		for _, roleBinding := range i.GlobalRoleBindings {
+			if !slices.ContainsFunc(i.GlobalRoles, func(r *v1.GlobalRole) bool { return r.Metadata.Name == roleBinding.Spec.Role }) {
+				issues = append(issues, newIssue(MissingRole, roleBinding, "", fmt.Sprintf("global role '%s' doesn't exist", roleBinding.Spec.Role)))
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (i *Inventory) checkProject(project *ProjectInventory, kinds []string, globalVariables []string, usedGlobalVariables map[string]bool) ([]Issue, error) {
+	var issues []Issue
+	usedProjectVariables := make(map[string]bool)
+	projectVariables := make([]string, 0, len(project.Variables))
+	for _, variable := range project.Variables {
+		projectVariables = append(projectVariables, variable.Metadata.Name)
+	}
+	checkDatasources := i.loaded(v1.KindDatasource, v1.KindGlobalDatasource)
+	checkVariables := i.loaded(v1.KindVariable, v1.KindGlobalVariable)
+	markUsed := func(name string) bool {
+		switch {
+		case slices.Contains(projectVariables, name):
+			usedProjectVariables[name] = true
+		case slices.Contains(globalVariables, name):
+			usedGlobalVariables[name] = true
+		default:
+			return false
+		}
+		return true
+	}
+
+	for _, conflict := range defaultConflicts(project.datasourceSpecs()) {
+		for _, datasource := range project.Datasources {
+			if slices.Contains(conflict.names[1:], datasource.Metadata.Name) {
+				issues = append(issues, newIssue(AmbiguousDefaultDatasource, datasource, "", ambiguousDescription(conflict)))
+			}
+		}
+	}
+	if i.loaded(v1.KindSecret) {
+		for _, datasource := range project.Datasources {
+			secret := datasourceSecret(datasource.Spec)
+			if secret != "" && !slices.ContainsFunc(project.Secrets, func(s *v1.Secret) bool { return s.Metadata.Name == secret }) {
+				issues = append(issues, newIssue(MissingSecret, datasource, "", fmt.Sprintf("secret '%s' doesn't exist in project '%s'", secret, project.Name)))
+			}
+		}
+	}
+	if i.loaded(v1.KindRole) {
+		for _, roleBinding := range project.RoleBindings {
+			if !slices.ContainsFunc(project.Roles, func(r *v1.Role) bool { return r.Metadata.Name == roleBinding.Spec.Role }) {
+				issues = append(issues, newIssue(MissingRole, roleBinding, "", fmt.Sprintf("role '%s' doesn't exist in project '%s'", roleBinding.Spec.Role, project.Name)))
+			}
+		}
+	}
+
+	for _, variable := range project.Variables {
+		if checkDatasources {
+			issues = append(issues, i.checkVariableDatasource(project, variable, variable.Spec, kinds)...)
+		}
+		refs, err := variableReferences(variable)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range refs {
+			if !markUsed(name) && checkVariables {
+				issues = append(issues, undefinedVariable(variable, "", name))
+			}
+		}
+	}
+
+	for _, dashboard := range project.Dashboards {
+		for _, conflict := range defaultConflicts(dashboardDatasources(dashboard)) {
+			for _, name := range conflict.names[1:] {
+				issues = append(issues, newIssue(AmbiguousDefaultDatasource, dashboard, fmt.Sprintf("datasource '%s'", name), ambiguousDescription(conflict)))
+			}
+		}
+		for _, ref := range dashboardDatasourceRefs(dashboard) {
+			kind := ref.datasourceKind(kinds)
+			if kind == "" || !checkDatasources {
+				continue
+			}
+			if _, ok := i.resolveDatasource(project, dashboard, kind, ref.DatasourceName); !ok {
+				issues = append(issues, newIssue(DanglingDatasource, dashboard, ref.Location, danglingDescription(kind, ref.DatasourceName)))
+			}
+		}
+
+		dashboardVariables := make([]string, 0, len(dashboard.Spec.Variables))
+		for _, variable := range dashboard.Spec.Variables {
+			if variable.Spec != nil {
+				dashboardVariables = append(dashboardVariables, variable.Spec.GetName())
+			}
+		}
+		refs, err := variableReferences(dashboard)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range refs {
+			if !slices.Contains(dashboardVariables, name) && !markUsed(name) && checkVariables {
+				issues = append(issues, undefinedVariable(dashboard, "", name))
+			}
+		}
+		for _, name := range dashboardVariables {
+			if !slices.Contains(refs, name) {
+				issues = append(issues, newIssue(UnusedVariable, dashboard, fmt.Sprintf("variable '%s'", name), fmt.Sprintf("variable '%s' is not used by any panel or variable of the dashboard", name)))
+			}
+		}
+	}
+
+	if i.loaded(v1.KindDashboard) {
+		for _, variable := range project.Variables {
+			if !usedProjectVariables[variable.Metadata.Name] {
+				issues = append(issues, newIssue(UnusedVariable, variable, "", fmt.Sprintf("the variable is not used by any dashboard or variable of project '%s'", project.Name)))
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (i *Inventory) checkVariableDatasource(project *ProjectInventory, variable modelAPI.Entity, spec v1.VariableSpec, kinds []string) []Issue {
+	ref, ok := variableDatasourceRef(spec)
+	if !ok {
+		return nil
+	}
+	kind := ref.datasourceKind(kinds)
+	if kind == "" {
+		return nil
+	}
+	if _, found := i.resolveDatasource(project, nil, kind, ref.DatasourceName); found {
+		return nil
+	}
+	return []Issue{newIssue(DanglingDatasource, variable, "", danglingDescription(kind, ref.DatasourceName))}
+}
+
+// variableReferences returns the variables referenced by the object, without the built-in ones (e.g. $__interval)
+// and the numbered ones, which are regex capture groups (e.g. $1 in label_replace).
+func variableReferences(obj modelAPI.Entity) ([]string, error) {
+	refs, err := objects.VariableReferences(obj)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(refs, func(name string) bool {
+		return strings.HasPrefix(name, "__") || strings.Trim(name, "0123456789") == ""
+	}), nil
+}
+
+// datasourceSecret returns the name of the secret used by the proxy of the datasource, if any.
+func datasourceSecret(spec v1.DatasourceSpec) string {
+	pluginSpec := asMap(toRaw(spec.Plugin)["spec"])
+	secret, _ := asMap(asMap(pluginSpec["proxy"])["spec"])["secret"].(string)
+	return secret
+}
+
+func danglingDescription(kind string, name string) string {
+	if name == "" {
+		return fmt.Sprintf("no default %s is defined", kind)
+	}
+	return fmt.Sprintf("%s '%s' doesn't exist", kind, name)
+}
+
+func ambiguousDescription(conflict defaultConflict) string {
+	return fmt.Sprintf("the datasource is a default %s like '%s', which is used instead as the first by name", conflict.kind, conflict.names[0])
+}
+
+func undefinedVariable(obj modelAPI.Entity, location string, name string) Issue {
+	return newIssue(UndefinedVariable, obj, location, fmt.Sprintf("variable '%s' is used but not defined", name))
+}
+
+func newIssue(issueType IssueType, obj modelAPI.Entity, location string, description string) Issue {
+	return Issue{
+		Type:        issueType,
+		Kind:        objects.Kind(obj),
+		Project:     objects.Project(obj),
+		Name:        objects.Name(obj),
+		Location:    location,
+		Description: description,
+	}
+}
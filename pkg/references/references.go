@@ -0,0 +1,470 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package references analyzes the references between the Perses objects,
+// e.g. the datasources used by the queries of the dashboards.
+package references
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/perses/mcp-server/pkg/objects"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Scope is where a datasource is defined. A datasource selector is resolved against the datasources
+// of the dashboard first, then the ones of the project, and finally the global ones.
+type Scope string
+
+const (
+	DashboardScope Scope = "dashboard"
+	ProjectScope   Scope = "project"
+	GlobalScope    Scope = "global"
+)
+
+// Inventory holds the objects analyzed: the global ones and the ones of a set of projects.
+type Inventory struct {
+	GlobalDatasources  []*v1.GlobalDatasource
+	GlobalVariables    []*v1.GlobalVariable
+	GlobalSecrets      []*v1.GlobalSecret
+	GlobalRoles        []*v1.GlobalRole
+	GlobalRoleBindings []*v1.GlobalRoleBinding
+	Projects           []*ProjectInventory
+	// AllProjects is true when the inventory holds every project, so that the usages of the global objects are known.
+	AllProjects bool
+	// SkippedKinds lists the kinds whose objects were not loaded. The analyses depending on them are not done.
+	SkippedKinds []SkippedKind
+}
+
+// SkippedKind is a kind whose objects were not loaded in the inventory.
+type SkippedKind struct {
+	Kind   v1.Kind `json:"kind"`
+	Reason string  `json:"reason"`
+}
+
+// ProjectInventory holds the objects of a project.
+type ProjectInventory struct {
+	Name         string
+	Datasources  []*v1.Datasource
+	Variables    []*v1.Variable
+	Dashboards   []*v1.Dashboard
+	Secrets      []*v1.Secret
+	Roles        []*v1.Role
+	RoleBindings []*v1.RoleBinding
+}
+
+// Load returns the inventory of the given projects, or of every project when none is given.
+// The objects of each kind are listed with a single call for all the projects. Only the kinds accepted by allowed are listed
+// (every kind when allowed is nil), and the kinds the caller is not allowed to list are skipped rather than failing the load.
+func Load(client apiClient.ClientInterface, projects []string, allowed func(kind v1.Kind) bool) (*Inventory, error) {
+	inventory := &Inventory{AllProjects: len(projects) == 0}
+	if inventory.AllProjects {
+		list, err := client.Project().List("")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the projects: %w", err)
+		}
+		for _, project := range list {
+			projects = append(projects, project.Metadata.Name)
+		}
+	}
+	loader := &loader{inventory: inventory, allowed: allowed}
+
+	var err error
+	if inventory.GlobalDatasources, err = load(loader, v1.KindGlobalDatasource, client.GlobalDatasource().List); err != nil {
+		return nil, err
+	}
+	if inventory.GlobalVariables, err = load(loader, v1.KindGlobalVariable, client.GlobalVariable().List); err != nil {
+		return nil, err
+	}
+	if inventory.GlobalSecrets, err = load(loader, v1.KindGlobalSecret, client.GlobalSecret().List); err != nil {
+		return nil, err
+	}
+	if inventory.GlobalRoles, err = load(loader, v1.KindGlobalRole, client.GlobalRole().List); err != nil {
+		return nil, err
+	}
+	if inventory.GlobalRoleBindings, err = load(loader, v1.KindGlobalRoleBinding, client.GlobalRoleBinding().List); err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return inventory, nil
+	}
+
+	byName := make(map[string]*ProjectInventory, len(projects))
+	for _, name := range projects {
+		project := &ProjectInventory{Name: name}
+		byName[name] = project
+		inventory.Projects = append(inventory.Projects, project)
+	}
+	// An empty project lists the objects of every project. A single project is listed on its own.
+	scope := ""
+	if len(projects) == 1 {
+		scope = projects[0]
+	}
+	datasources, err := load(loader, v1.KindDatasource, client.Datasource(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, datasources, func(p *ProjectInventory, obj *v1.Datasource) { p.Datasources = append(p.Datasources, obj) })
+	variables, err := load(loader, v1.KindVariable, client.Variable(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, variables, func(p *ProjectInventory, obj *v1.Variable) { p.Variables = append(p.Variables, obj) })
+	dashboards, err := load(loader, v1.KindDashboard, client.Dashboard(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, dashboards, func(p *ProjectInventory, obj *v1.Dashboard) { p.Dashboards = append(p.Dashboards, obj) })
+	secrets, err := load(loader, v1.KindSecret, client.Secret(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, secrets, func(p *ProjectInventory, obj *v1.Secret) { p.Secrets = append(p.Secrets, obj) })
+	roles, err := load(loader, v1.KindRole, client.Role(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, roles, func(p *ProjectInventory, obj *v1.Role) { p.Roles = append(p.Roles, obj) })
+	roleBindings, err := load(loader, v1.KindRoleBinding, client.RoleBinding(scope).List)
+	if err != nil {
+		return nil, err
+	}
+	assign(byName, roleBindings, func(p *ProjectInventory, obj *v1.RoleBinding) { p.RoleBindings = append(p.RoleBindings, obj) })
+	return inventory, nil
+}
+
+type loader struct {
+	inventory *Inventory
+	allowed   func(kind v1.Kind) bool
+}
+
+// load lists the objects of a kind, or records the kind as skipped when it is not allowed or when the caller can't list it.
+func load[T modelAPI.Entity](l *loader, kind v1.Kind, list func(prefix string) ([]T, error)) ([]T, error) {
+	if l.allowed != nil && !l.allowed(kind) {
+		l.inventory.SkippedKinds = append(l.inventory.SkippedKinds, SkippedKind{Kind: kind, Reason: "resource is not available"})
+		return nil, nil
+	}
+	result, err := list("")
+	if objects.IsForbidden(err) {
+		l.inventory.SkippedKinds = append(l.inventory.SkippedKinds, SkippedKind{Kind: kind, Reason: fmt.Sprintf("unable to list the objects: %s", err)})
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the %s objects: %w", kind, err)
+	}
+	return result, nil
+}
+
+// loaded returns true when the objects of every given kind are in the inventory.
+func (i *Inventory) loaded(kinds ...v1.Kind) bool {
+	for _, skipped := range i.SkippedKinds {
+		if slices.Contains(kinds, skipped.Kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// assign adds every object of the list to the inventory of its project. The objects of the other projects are ignored.
+func assign[T modelAPI.Entity](projects map[string]*ProjectInventory, list []T, add func(project *ProjectInventory, obj T)) {
+	for _, obj := range list {
+		if project, ok := projects[objects.Project(obj)]; ok {
+			add(project, obj)
+		}
+	}
+}
+
+// datasourceRef is a plugin of an object that gets its data from a datasource.
+type datasourceRef struct {
+	// Location describes where the plugin is in the object, e.g. "panel 'cpu' query 0".
+	Location string
+	// PluginKind is the kind of the plugin, e.g. PrometheusTimeSeriesQuery.
+	PluginKind string
+	// DatasourceKind is the kind of the datasource selected by the plugin, e.g. PrometheusDatasource.
+	// It is empty when the plugin has no datasource selector.
+	DatasourceKind string
+	// DatasourceName is the name of the datasource selected by the plugin.
+	// It is empty when the plugin uses the default datasource of the kind.
+	DatasourceName string
+	// Query is the expression of the query, when the plugin has one.
+	Query string
+}
+
+// wellKnownDatasourceKinds are the kinds of the datasource plugins of Perses. A plugin without datasource selector
+// uses the default datasource of one of them, or of a kind defined in the inventory.
+var wellKnownDatasourceKinds = []string{
+	"PrometheusDatasource",
+	"TempoDatasource",
+	"LokiDatasource",
+	"PyroscopeDatasource",
+	"ClickHouseDatasource",
+	"VictoriaLogsDatasource",
+}
+
+// datasourceKind returns the kind of the datasource the plugin gets its data from, among the given kinds when the plugin
+// has no datasource selector. It returns an empty string when the plugin doesn't use any of the kinds, e.g. a static list variable.
+func (r datasourceRef) datasourceKind(kinds []string) string {
+	if r.DatasourceKind != "" {
+		return r.DatasourceKind
+	}
+	for _, kind := range kinds {
+		if r.usesKind(kind) {
+			return kind
+		}
+	}
+	return ""
+}
+
+// usesKind returns true when the plugin gets its data from a datasource of the given kind.
+// A plugin without datasource selector uses the default datasource of the kind it belongs to,
+// which is guessed from the name of the kinds (e.g. PrometheusTimeSeriesQuery uses a PrometheusDatasource).
+func (r datasourceRef) usesKind(kind string) bool {
+	if r.DatasourceKind != "" {
+		return r.DatasourceKind == kind
+	}
+	family := strings.TrimSuffix(kind, "Datasource")
+	return family != "" && strings.HasPrefix(r.PluginKind, family)
+}
+
+// dashboardDatasourceRefs returns the plugins of the panels, queries and variables of the dashboard.
+// The panel plugins are only returned when they select a datasource explicitly.
+func dashboardDatasourceRefs(dashboard *v1.Dashboard) []datasourceRef {
+	spec := toRaw(dashboard.Spec)
+	panels, _ := spec["panels"].(map[string]any)
+	ids := make([]string, 0, len(panels))
+	for id := range panels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var result []datasourceRef
+	for _, id := range ids {
+		panelSpec, _ := asMap(panels[id])["spec"].(map[string]any)
+		if ref, ok := pluginRef(fmt.Sprintf("panel '%s'", id), asMap(panelSpec["plugin"])); ok && ref.DatasourceKind != "" {
+			result = append(result, ref)
+		}
+		queries, _ := panelSpec["queries"].([]any)
+		for i, query := range queries {
+			querySpec := asMap(asMap(query)["spec"])
+			if ref, ok := pluginRef(fmt.Sprintf("panel '%s' query %d", id, i), asMap(querySpec["plugin"])); ok {
+				result = append(result, ref)
+			}
+		}
+	}
+	variables, _ := spec["variables"].([]any)
+	for _, variable := range variables {
+		variableSpec := asMap(asMap(variable)["spec"])
+		name, _ := variableSpec["name"].(string)
+		if ref, ok := pluginRef(fmt.Sprintf("variable '%s'", name), asMap(variableSpec["plugin"])); ok {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// variableDatasourceRef returns the plugin of a project or global variable.
+func variableDatasourceRef(spec v1.VariableSpec) (datasourceRef, bool) {
+	return pluginRef("variable", asMap(asMap(toRaw(spec.Spec))["plugin"]))
+}
+
+func pluginRef(location string, plugin map[string]any) (datasourceRef, bool) {
+	kind, _ := plugin["kind"].(string)
+	if kind == "" {
+		return datasourceRef{}, false
+	}
+	ref := datasourceRef{Location: location, PluginKind: kind}
+	pluginSpec := asMap(plugin["spec"])
+	if selector := asMap(pluginSpec["datasource"]); selector != nil {
+		ref.DatasourceKind, _ = selector["kind"].(string)
+		ref.DatasourceName, _ = selector["name"].(string)
+	}
+	ref.Query, _ = pluginSpec["query"].(string)
+	return ref, true
+}
+
+// ResolvedDatasource is the datasource a selector resolves to.
+type ResolvedDatasource struct {
+	Scope Scope
+	Name  string
+}
+
+// resolveDatasource returns the datasource of the given kind selected by name (or the default one when the name is empty),
+// looking at the datasources of the dashboard, then of the project, and finally the global ones.
+// When several datasources of a scope are the default of the kind, the first one by name is returned (see Check).
+// The dashboard and the project can be nil, e.g. for a global variable.
+func (i *Inventory) resolveDatasource(project *ProjectInventory, dashboard *v1.Dashboard, kind string, name string) (ResolvedDatasource, bool) {
+	if dashboard != nil {
+		if dsName, ok := firstSelected(dashboardDatasources(dashboard), kind, name); ok {
+			return ResolvedDatasource{Scope: DashboardScope, Name: dsName}, true
+		}
+	}
+	if project != nil {
+		if dsName, ok := firstSelected(project.datasourceSpecs(), kind, name); ok {
+			return ResolvedDatasource{Scope: ProjectScope, Name: dsName}, true
+		}
+	}
+	if dsName, ok := firstSelected(i.globalDatasourceSpecs(), kind, name); ok {
+		return ResolvedDatasource{Scope: GlobalScope, Name: dsName}, true
+	}
+	return ResolvedDatasource{}, false
+}
+
+// datasourceKinds returns the well-known datasource kinds and the kinds of the datasources of the inventory.
+func (i *Inventory) datasourceKinds() []string {
+	kinds := slices.Clone(wellKnownDatasourceKinds)
+	addKinds := func(specs map[string]*v1.DatasourceSpec) {
+		for _, spec := range specs {
+			if !slices.Contains(kinds, spec.Plugin.Kind) {
+				kinds = append(kinds, spec.Plugin.Kind)
+			}
+		}
+	}
+	addKinds(i.globalDatasourceSpecs())
+	for _, project := range i.Projects {
+		addKinds(project.datasourceSpecs())
+		for _, dashboard := range project.Dashboards {
+			addKinds(dashboardDatasources(dashboard))
+		}
+	}
+	// The longest kinds first, so that a plugin is matched with the most specific kind.
+	sort.Slice(kinds, func(a, b int) bool {
+		if len(kinds[a]) != len(kinds[b]) {
+			return len(kinds[a]) > len(kinds[b])
+		}
+		return kinds[a] < kinds[b]
+	})
+	return kinds
+}
+
+func (i *Inventory) globalDatasourceSpecs() map[string]*v1.DatasourceSpec {
+	specs := make(map[string]*v1.DatasourceSpec, len(i.GlobalDatasources))
+	for _, datasource := range i.GlobalDatasources {
+		specs[datasource.Metadata.Name] = &datasource.Spec
+	}
+	return specs
+}
+
+func (p *ProjectInventory) datasourceSpecs() map[string]*v1.DatasourceSpec {
+	specs := make(map[string]*v1.DatasourceSpec, len(p.Datasources))
+	for _, datasource := range p.Datasources {
+		specs[datasource.Metadata.Name] = &datasource.Spec
+	}
+	return specs
+}
+
+func dashboardDatasources(dashboard *v1.Dashboard) map[string]*v1.DatasourceSpec {
+	specs := make(map[string]*v1.DatasourceSpec, len(dashboard.Spec.Datasources))
+	for name, spec := range dashboard.Spec.Datasources {
+		if spec != nil {
+			specs[name] = spec
+		}
+	}
+	return specs
+}
+
+// firstSelected returns the first datasource by name that the selector of the given kind and name selects.
+func firstSelected(specs map[string]*v1.DatasourceSpec, kind string, name string) (string, bool) {
+	selected := selectedNames(specs, kind, name)
+	if len(selected) == 0 {
+		return "", false
+	}
+	return selected[0], true
+}
+
+// defaultConflict is a datasource kind having more than one default datasource in the same scope.
+type defaultConflict struct {
+	kind string
+	// names are the default datasources of the kind, sorted by name: the first one is used.
+	names []string
+}
+
+// defaultConflicts returns the datasource kinds having more than one default datasource, sorted by kind.
+func defaultConflicts(specs map[string]*v1.DatasourceSpec) []defaultConflict {
+	var kinds []string
+	for _, spec := range specs {
+		if spec.Default && !slices.Contains(kinds, spec.Plugin.Kind) {
+			kinds = append(kinds, spec.Plugin.Kind)
+		}
+	}
+	sort.Strings(kinds)
+	var result []defaultConflict
+	for _, kind := range kinds {
+		if names := selectedNames(specs, kind, ""); len(names) > 1 {
+			result = append(result, defaultConflict{kind: kind, names: names})
+		}
+	}
+	return result
+}
+
+// selectedNames returns the names of the datasources that the selector of the given kind and name selects, sorted by name.
+func selectedNames(specs map[string]*v1.DatasourceSpec, kind string, name string) []string {
+	var result []string
+	for dsName, spec := range specs {
+		if selects(dsName, spec, kind, name) {
+			result = append(result, dsName)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ResolveDatasource returns the datasource of the given kind selected by name (or the default one when the name is empty)
+// from the dashboard of the project, without loading the whole inventory of the project.
+func ResolveDatasource(client apiClient.ClientInterface, project string, dashboard *v1.Dashboard, kind string, name string) (ResolvedDatasource, bool, error) {
+	globalDatasources, err := client.GlobalDatasource().List("")
+	if err != nil {
+		return ResolvedDatasource{}, false, fmt.Errorf("unable to list the global datasources: %w", err)
+	}
+	datasources, err := client.Datasource(project).List("")
+	if err != nil {
+		return ResolvedDatasource{}, false, fmt.Errorf("unable to list the datasources of project '%s': %w", project, err)
+	}
+	inventory := &Inventory{GlobalDatasources: globalDatasources}
+	resolved, ok := inventory.resolveDatasource(&ProjectInventory{Name: project, Datasources: datasources}, dashboard, kind, name)
+	return resolved, ok, nil
+}
+
+func selects(dsName string, spec *v1.DatasourceSpec, kind string, name string) bool {
+	if spec.Plugin.Kind != kind {
+		return false
+	}
+	if name == "" {
+		return spec.Default
+	}
+	return dsName == name
+}
+
+func toRaw(value any) map[string]any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var result map[string]any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+func asMap(value any) map[string]any {
+	if m, ok := value.(map[string]any); ok {
+		return m
+	}
+	return nil
+}
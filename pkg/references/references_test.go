@@ -0,0 +1,371 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package references
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	"github.com/perses/perses/pkg/client/perseshttp"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func datasource(project string, name string, kind string, isDefault bool) *v1.Datasource {
+	return &v1.Datasource{
+		Kind: v1.KindDatasource,
+		Metadata: v1.ProjectMetadata{
+			Metadata:               v1.Metadata{Name: name},
+			ProjectMetadataWrapper: v1.ProjectMetadataWrapper{Project: project},
+		},
+		Spec: v1.DatasourceSpec{Default: isDefault, Plugin: common.Plugin{Kind: kind, Spec: map[string]any{}}},
+	}
+}
+
+func globalDatasource(name string, kind string, isDefault bool) *v1.GlobalDatasource {
+	return &v1.GlobalDatasource{
+		Kind:     v1.KindGlobalDatasource,
+		Metadata: v1.Metadata{Name: name},
+		Spec:     v1.DatasourceSpec{Default: isDefault, Plugin: common.Plugin{Kind: kind, Spec: map[string]any{}}},
+	}
+}
+
+// dashboardWithQuery returns a dashboard with a panel running a query of the given plugin kind, with the given datasource selector.
+func dashboardWithQuery(t *testing.T, pluginKind string, selector map[string]any) *v1.Dashboard {
+	pluginSpec := map[string]any{"query": "up"}
+	if selector != nil {
+		pluginSpec["datasource"] = selector
+	}
+	def := map[string]any{
+		"kind":     "Dashboard",
+		"metadata": map[string]any{"name": "overview", "project": "shop"},
+		"spec": map[string]any{
+			"panels": map[string]any{
+				"cpu": map[string]any{
+					"kind": "Panel",
+					"spec": map[string]any{
+						"plugin": map[string]any{"kind": "TimeSeriesChart", "spec": map[string]any{}},
+						"queries": []any{map[string]any{
+							"kind": "TimeSeriesQuery",
+							"spec": map[string]any{"plugin": map[string]any{"kind": pluginKind, "spec": pluginSpec}},
+						}},
+					},
+				},
+			},
+			"layouts": []any{},
+		},
+	}
+	data, err := json.Marshal(def)
+	require.NoError(t, err)
+	var dashboard v1.Dashboard
+	require.NoError(t, json.Unmarshal(data, &dashboard))
+	return &dashboard
+}
+
+func TestCheckDatasources(t *testing.T) {
+	testSuite := []struct {
+		title       string
+		globals     []*v1.GlobalDatasource
+		datasources []*v1.Datasource
+		dashboard   *v1.Dashboard
+		issues      []string
+	}{
+		{
+			title:     "query without selector and no default datasource",
+			dashboard: dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+			issues:    []string{"dangling_datasource Dashboard/overview panel 'cpu' query 0: no default PrometheusDatasource is defined"},
+		},
+		{
+			title:       "query without selector and a project default datasource",
+			datasources: []*v1.Datasource{datasource("shop", "prometheus", "PrometheusDatasource", true)},
+			dashboard:   dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+		},
+		{
+			title:     "query without selector and a global default datasource",
+			globals:   []*v1.GlobalDatasource{globalDatasource("prometheus", "PrometheusDatasource", true)},
+			dashboard: dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+		},
+		{
+			title:       "query without selector and a datasource that is not the default",
+			datasources: []*v1.Datasource{datasource("shop", "prometheus", "PrometheusDatasource", false)},
+			dashboard:   dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+			issues:      []string{"dangling_datasource Dashboard/overview panel 'cpu' query 0: no default PrometheusDatasource is defined"},
+		},
+		{
+			title:       "query without selector of a kind defined in the inventory",
+			datasources: []*v1.Datasource{datasource("shop", "other", "CustomDatasource", false)},
+			dashboard:   dashboardWithQuery(t, "CustomTimeSeriesQuery", nil),
+			issues:      []string{"dangling_datasource Dashboard/overview panel 'cpu' query 0: no default CustomDatasource is defined"},
+		},
+		{
+			title:     "query of an unknown kind without selector",
+			dashboard: dashboardWithQuery(t, "CustomTimeSeriesQuery", nil),
+		},
+		{
+			title:       "selector of a missing datasource",
+			datasources: []*v1.Datasource{datasource("shop", "prometheus", "PrometheusDatasource", true)},
+			dashboard:   dashboardWithQuery(t, "PrometheusTimeSeriesQuery", map[string]any{"kind": "PrometheusDatasource", "name": "thanos"}),
+			issues:      []string{"dangling_datasource Dashboard/overview panel 'cpu' query 0: PrometheusDatasource 'thanos' doesn't exist"},
+		},
+		{
+			title: "several default datasources of a kind",
+			datasources: []*v1.Datasource{
+				datasource("shop", "thanos", "PrometheusDatasource", true),
+				datasource("shop", "prometheus", "PrometheusDatasource", true),
+				datasource("shop", "tempo", "TempoDatasource", true),
+			},
+			dashboard: dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+			issues: []string{
+				"ambiguous_default_datasource Datasource/thanos: the datasource is a default PrometheusDatasource like 'prometheus', which is used instead as the first by name",
+			},
+		},
+		{
+			title: "several global default datasources of a kind",
+			globals: []*v1.GlobalDatasource{
+				globalDatasource("b", "PrometheusDatasource", true),
+				globalDatasource("a", "PrometheusDatasource", true),
+			},
+			dashboard: dashboardWithQuery(t, "PrometheusTimeSeriesQuery", nil),
+			issues: []string{
+				"ambiguous_default_datasource GlobalDatasource/b: the datasource is a default PrometheusDatasource like 'a', which is used instead as the first by name",
+			},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			inventory := &Inventory{
+				GlobalDatasources: test.globals,
+				Projects: []*ProjectInventory{{
+					Name:        "shop",
+					Datasources: test.datasources,
+					Dashboards:  []*v1.Dashboard{test.dashboard},
+				}},
+				AllProjects: true,
+			}
+			issues, err := Check(inventory)
+			require.NoError(t, err)
+			descriptions := []string{}
+			for _, issue := range issues {
+				location := ""
+				if issue.Location != "" {
+					location = " " + issue.Location
+				}
+				descriptions = append(descriptions, fmt.Sprintf("%s %s/%s%s: %s", issue.Type, issue.Kind, issue.Name, location, issue.Description))
+			}
+			if test.issues == nil {
+				test.issues = []string{}
+			}
+			assert.Equal(t, test.issues, descriptions)
+		})
+	}
+}
+
+func TestCheckSkippedKinds(t *testing.T) {
+	withSecret := datasource("shop", "prometheus", "PrometheusDatasource", true)
+	withSecret.Spec.Plugin.Spec = map[string]any{"proxy": map[string]any{"kind": "HTTPProxy", "spec": map[string]any{"url": "http://prometheus:9090", "secret": "credentials"}}}
+	roleBinding := &v1.RoleBinding{
+		Kind: v1.KindRoleBinding,
+		Metadata: v1.ProjectMetadata{
+			Metadata:               v1.Metadata{Name: "viewers"},
+			ProjectMetadataWrapper: v1.ProjectMetadataWrapper{Project: "shop"},
+		},
+		Spec: v1.RoleBindingSpec{Role: "viewer"},
+	}
+	testSuite := []struct {
+		title   string
+		skipped []v1.Kind
+		issues  []IssueType
+	}{
+		{
+			title:  "every kind loaded",
+			issues: []IssueType{MissingSecret, MissingRole, DanglingDatasource},
+		},
+		{
+			title:   "secrets skipped",
+			skipped: []v1.Kind{v1.KindSecret},
+			issues:  []IssueType{MissingRole, DanglingDatasource},
+		},
+		{
+			title:   "roles skipped",
+			skipped: []v1.Kind{v1.KindRole},
+			issues:  []IssueType{MissingSecret, DanglingDatasource},
+		},
+		{
+			title:   "global datasources skipped",
+			skipped: []v1.Kind{v1.KindGlobalDatasource},
+			issues:  []IssueType{MissingSecret, MissingRole},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			inventory := &Inventory{
+				Projects: []*ProjectInventory{{
+					Name:         "shop",
+					Datasources:  []*v1.Datasource{withSecret},
+					Dashboards:   []*v1.Dashboard{dashboardWithQuery(t, "PrometheusTimeSeriesQuery", map[string]any{"kind": "PrometheusDatasource", "name": "thanos"})},
+					RoleBindings: []*v1.RoleBinding{roleBinding},
+				}},
+			}
+			for _, kind := range test.skipped {
+				inventory.SkippedKinds = append(inventory.SkippedKinds, SkippedKind{Kind: kind, Reason: "resource is not available"})
+			}
+			issues, err := Check(inventory)
+			require.NoError(t, err)
+			var types []IssueType
+			for _, issue := range issues {
+				types = append(types, issue.Type)
+			}
+			assert.Equal(t, test.issues, types)
+		})
+	}
+}
+
+func TestResolveDefaultDatasourceIsDeterministic(t *testing.T) {
+	project := &ProjectInventory{Name: "shop"}
+	for _, name := range []string{"d", "b", "a", "c"} {
+		project.Datasources = append(project.Datasources, datasource("shop", name, "PrometheusDatasource", true))
+	}
+	inventory := &Inventory{Projects: []*ProjectInventory{project}}
+	for range 20 {
+		resolved, ok := inventory.resolveDatasource(project, nil, "PrometheusDatasource", "")
+		require.True(t, ok)
+		assert.Equal(t, ResolvedDatasource{Scope: ProjectScope, Name: "a"}, resolved)
+	}
+}
+
+// fakePerses serves the objects of every project and counts the requests.
+type fakePerses struct {
+	mutex    sync.Mutex
+	requests []string
+	// forbidden are the paths the caller is not allowed to list.
+	forbidden []string
+}
+
+func (f *fakePerses) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.requests = append(f.requests, r.URL.Path)
+	f.mutex.Unlock()
+	var response any = []any{}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+	if slices.Contains(f.forbidden, path) {
+		http.Error(w, `{"message":"forbidden"}`, http.StatusForbidden)
+		return
+	}
+	switch path {
+	case "projects":
+		response = []any{
+			map[string]any{"kind": "Project", "metadata": map[string]any{"name": "shop"}, "spec": map[string]any{}},
+			map[string]any{"kind": "Project", "metadata": map[string]any{"name": "lab"}, "spec": map[string]any{}},
+		}
+	case "datasources":
+		response = []*v1.Datasource{
+			datasource("shop", "prometheus", "PrometheusDatasource", true),
+			datasource("lab", "thanos", "PrometheusDatasource", true),
+			datasource("other", "tempo", "TempoDatasource", true),
+		}
+	case "projects/shop/datasources":
+		response = []*v1.Datasource{datasource("shop", "prometheus", "PrometheusDatasource", true)}
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func TestLoad(t *testing.T) {
+	testSuite := []struct {
+		title       string
+		projects    []string
+		allowed     func(kind v1.Kind) bool
+		forbidden   []string
+		datasources map[string][]string
+		skipped     []v1.Kind
+		requests    int
+	}{
+		{
+			title:       "every project",
+			datasources: map[string][]string{"shop": {"prometheus"}, "lab": {"thanos"}},
+			// The projects, the 5 global kinds and the 6 project kinds.
+			requests: 12,
+		},
+		{
+			title:       "several projects",
+			projects:    []string{"shop", "lab"},
+			datasources: map[string][]string{"shop": {"prometheus"}, "lab": {"thanos"}},
+			requests:    11,
+		},
+		{
+			title:       "single project",
+			projects:    []string{"shop"},
+			datasources: map[string][]string{"shop": {"prometheus"}},
+			requests:    11,
+		},
+		{
+			title:       "kinds not allowed",
+			projects:    []string{"shop"},
+			allowed:     func(kind v1.Kind) bool { return kind != v1.KindSecret && kind != v1.KindGlobalSecret },
+			datasources: map[string][]string{"shop": {"prometheus"}},
+			skipped:     []v1.Kind{v1.KindGlobalSecret, v1.KindSecret},
+			requests:    9,
+		},
+		{
+			title:       "kinds the caller can't list",
+			projects:    []string{"shop"},
+			forbidden:   []string{"globalsecrets", "projects/shop/secrets"},
+			datasources: map[string][]string{"shop": {"prometheus"}},
+			skipped:     []v1.Kind{v1.KindGlobalSecret, v1.KindSecret},
+			requests:    11,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			fake := &fakePerses{forbidden: test.forbidden}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+			client := apiClient.NewWithClient(&perseshttp.RESTClient{BaseURL: common.MustParseURL(srv.URL), Client: srv.Client()})
+
+			inventory, err := Load(client, test.projects, test.allowed)
+			require.NoError(t, err)
+			datasources := map[string][]string{}
+			for _, project := range inventory.Projects {
+				for _, ds := range project.Datasources {
+					datasources[project.Name] = append(datasources[project.Name], ds.Metadata.Name)
+				}
+			}
+			assert.Equal(t, test.datasources, datasources)
+			var skipped []v1.Kind
+			for _, kind := range inventory.SkippedKinds {
+				skipped = append(skipped, kind.Kind)
+			}
+			assert.Equal(t, test.skipped, skipped)
+			assert.Len(t, fake.requests, test.requests)
+		})
+	}
+}
+
+func TestLoadFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"internal error"}`, http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	client := apiClient.NewWithClient(&perseshttp.RESTClient{BaseURL: common.MustParseURL(srv.URL), Client: srv.Client()})
+	_, err := Load(client, []string{"shop"}, nil)
+	assert.Error(t, err, "only the kinds the caller is not allowed to list are skipped")
+}
@@ -0,0 +1,120 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package references
+
+import (
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Datasource identifies the datasource whose usages are looked for.
+type Datasource struct {
+	Scope Scope `json:"scope"`
+	// Project is the project of a project datasource.
+	Project string `json:"project,omitempty"`
+	Name    string `json:"name"`
+	// Kind is the plugin kind of the datasource, e.g. PrometheusDatasource.
+	Kind    string `json:"kind"`
+	Default bool   `json:"default"`
+}
+
+// Usage is a panel query or a variable getting its data from the datasource.
+type Usage struct {
+	Kind    v1.Kind `json:"kind"`
+	Project string  `json:"project,omitempty"`
+	Name    string  `json:"name"`
+	// Location describes where the reference is in the object, e.g. "panel 'cpu' query 0".
+	Location string `json:"location"`
+	Query    string `json:"query,omitempty"`
+	// ByDefault is true when the reference doesn't name the datasource, but falls back to the default datasource of its kind.
+	ByDefault bool `json:"by_default,omitempty"`
+}
+
+// Shadow is a datasource with the same name and kind as the global datasource, which takes precedence over it
+// for the references of its project or dashboard.
+type Shadow struct {
+	Scope   Scope  `json:"scope"`
+	Project string `json:"project"`
+	// Dashboard is set when the shadowing datasource is defined in a dashboard.
+	Dashboard string `json:"dashboard,omitempty"`
+}
+
+// UsagesReport lists everything that depends on a datasource.
+type UsagesReport struct {
+	Datasource Datasource `json:"datasource"`
+	Usages     []Usage    `json:"usages"`
+	Shadows    []Shadow   `json:"shadows,omitempty"`
+	// SkippedKinds lists the kinds that were not searched, e.g. the dashboards when the resource is not available.
+	SkippedKinds []SkippedKind `json:"skipped_kinds,omitempty"`
+}
+
+// DatasourceUsages returns the panel queries and the variables of the inventory that get their data from the datasource,
+// either by naming it or by falling back to it as the default datasource of its kind.
+func DatasourceUsages(inventory *Inventory, datasource Datasource) *UsagesReport {
+	report := &UsagesReport{Datasource: datasource, Usages: []Usage{}, SkippedKinds: inventory.SkippedKinds}
+	target := ResolvedDatasource{Scope: datasource.Scope, Name: datasource.Name}
+	matches := func(project *ProjectInventory, dashboard *v1.Dashboard, ref datasourceRef) bool {
+		if !ref.usesKind(datasource.Kind) {
+			return false
+		}
+		resolved, ok := inventory.resolveDatasource(project, dashboard, datasource.Kind, ref.DatasourceName)
+		return ok && resolved == target
+	}
+
+	if datasource.Scope == GlobalScope {
+		for _, variable := range inventory.GlobalVariables {
+			if ref, ok := variableDatasourceRef(variable.Spec); ok && matches(nil, nil, ref) {
+				report.Usages = append(report.Usages, newUsage(v1.KindGlobalVariable, "", variable.Metadata.Name, ref))
+			}
+		}
+	}
+	for _, project := range inventory.Projects {
+		if datasource.Scope == ProjectScope && project.Name != datasource.Project {
+			continue
+		}
+		if datasource.Scope == GlobalScope {
+			for _, projectDatasource := range project.Datasources {
+				if projectDatasource.Metadata.Name == datasource.Name && projectDatasource.Spec.Plugin.Kind == datasource.Kind {
+					report.Shadows = append(report.Shadows, Shadow{Scope: ProjectScope, Project: project.Name})
+				}
+			}
+		}
+		for _, variable := range project.Variables {
+			if ref, ok := variableDatasourceRef(variable.Spec); ok && matches(project, nil, ref) {
+				report.Usages = append(report.Usages, newUsage(v1.KindVariable, project.Name, variable.Metadata.Name, ref))
+			}
+		}
+		for _, dashboard := range project.Dashboards {
+			if spec, ok := dashboard.Spec.Datasources[datasource.Name]; ok && spec != nil && spec.Plugin.Kind == datasource.Kind {
+				report.Shadows = append(report.Shadows, Shadow{Scope: DashboardScope, Project: project.Name, Dashboard: dashboard.Metadata.Name})
+			}
+			for _, ref := range dashboardDatasourceRefs(dashboard) {
+				if matches(project, dashboard, ref) {
+					report.Usages = append(report.Usages, newUsage(v1.KindDashboard, project.Name, dashboard.Metadata.Name, ref))
+				}
+			}
+		}
+	}
+	return report
+}
+
+func newUsage(kind v1.Kind, project string, name string, ref datasourceRef) Usage {
+	return Usage{
+		Kind:      kind,
+		Project:   project,
+		Name:      name,
+		Location:  ref.Location,
+		Query:     ref.Query,
+		ByDefault: ref.DatasourceName == "",
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	// fontScale is the size in pixels of a dot of the glyphs.
+	fontScale = 2
+	// charWidth is the space taken by a character, glyph and spacing included.
+	charWidth = (glyphWidth + 1) * fontScale
+)
+
+// glyphs is a 3x5 font for the labels of the axes: the numbers with their SI prefixes, and the times.
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	'-': {"...", "...", "###", "...", "..."},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'e': {"...", "###", "###", "#..", "###"},
+	'k': {"#..", "#.#", "##.", "#.#", "#.#"},
+	'm': {"...", "##.", "###", "#.#", "#.#"},
+	'u': {"...", "#.#", "#.#", "#.#", "###"},
+	'n': {"...", "##.", "#.#", "#.#", "#.#"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'G': {"###", "#..", "#.#", "#.#", "###"},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'P': {"###", "#.#", "###", "#..", "#.."},
+}
+
+// drawText draws the text with its top left corner at (x, y). The characters without glyph are left blank.
+func drawText(img *image.RGBA, x int, y int, text string, c color.Color) {
+	for _, char := range text {
+		glyph, ok := glyphs[char]
+		if ok {
+			for row, line := range glyph {
+				for col, dot := range line {
+					if dot != '#' {
+						continue
+					}
+					for dy := 0; dy < fontScale; dy++ {
+						for dx := 0; dx < fontScale; dx++ {
+							img.Set(x+col*fontScale+dx, y+row*fontScale+dy, c)
+						}
+					}
+				}
+			}
+		}
+		x += charWidth
+	}
+}
+
+// textWidth returns the width in pixels of the text drawn by drawText.
+func textWidth(text string) int {
+	return len([]rune(text)) * charWidth
+}
@@ -0,0 +1,274 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render draws the data of a dashboard panel without any browser: as a PNG line chart or as a table of Unicode sparklines.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/paneldata"
+)
+
+// MaxSeries is the number of series drawn on a chart, the other ones are left out.
+const MaxSeries = 12
+
+// Palette is the color of the series in the charts, in the order of the series.
+var Palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b",
+	"#e377c2", "#7f7f7f", "#bcbd22", "#17becf", "#393b79", "#637939",
+}
+
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	gridColor  = color.RGBA{R: 0xe5, G: 0xe5, B: 0xe5, A: 0xff}
+	axisColor  = color.RGBA{R: 0x59, G: 0x59, B: 0x59, A: 0xff}
+)
+
+// SeriesColor returns the color of the series at the given index, as #rrggbb.
+func SeriesColor(index int) string {
+	return Palette[index%len(Palette)]
+}
+
+// PNG draws the series of the panel as a line chart over the time range of the data, with the values on the Y axis and the times (UTC) on the X axis.
+// Two samples are joined by a line unless they are more than one and a half steps apart.
+func PNG(data *paneldata.Data, width int, height int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	series := data.Series
+	if len(series) > MaxSeries {
+		series = series[:MaxSeries]
+	}
+	low, high, ok := valueRange(series)
+	if !ok {
+		low, high = 0, 1
+	}
+	ticks := valueTicks(low, high)
+	low, high = ticks[0], ticks[len(ticks)-1]
+	labelWidth := 0
+	for _, tick := range ticks {
+		labelWidth = max(labelWidth, textWidth(formatValue(tick)))
+	}
+
+	const margin = 10
+	// the bounds are checked before building the rectangle, as image.Rect swaps the inverted coordinates.
+	left, bottom := margin+labelWidth+6, height-margin-glyphHeight*fontScale-8
+	if width-margin-left < 10 || bottom-margin < 10 {
+		return nil, fmt.Errorf("the image is too small to draw the chart")
+	}
+	plot := image.Rect(left, margin, width-margin, bottom)
+	start, end := data.Range.Start, data.Range.End
+	if !end.After(start) {
+		return nil, fmt.Errorf("the end of the time range must be after its start")
+	}
+	toX := func(t time.Time) int {
+		return plot.Min.X + int(math.Round(float64(t.Sub(start))/float64(end.Sub(start))*float64(plot.Dx())))
+	}
+	toY := func(value float64) int {
+		return plot.Max.Y - int(math.Round((value-low)/(high-low)*float64(plot.Dy())))
+	}
+
+	for _, tick := range ticks {
+		y := toY(tick)
+		drawHorizontal(img, plot.Min.X, plot.Max.X, y, gridColor)
+		label := formatValue(tick)
+		drawText(img, plot.Min.X-6-textWidth(label), y-glyphHeight*fontScale/2, label, axisColor)
+	}
+	for _, tick := range timeTicks(start, end) {
+		x := toX(tick)
+		drawVertical(img, x, plot.Min.Y, plot.Max.Y, gridColor)
+		label := formatTime(tick, end.Sub(start))
+		labelX := min(max(x-textWidth(label)/2, 0), width-textWidth(label))
+		drawText(img, labelX, plot.Max.Y+6, label, axisColor)
+	}
+	drawHorizontal(img, plot.Min.X, plot.Max.X, plot.Max.Y, axisColor)
+	drawVertical(img, plot.Min.X, plot.Min.Y, plot.Max.Y, axisColor)
+
+	maxGap := data.Range.Step + data.Range.Step/2
+	for i, s := range series {
+		c := parseColor(SeriesColor(i))
+		for j, sample := range s.Samples {
+			x, y := toX(sample.Time), toY(sample.Value)
+			joined := false
+			if j > 0 && sample.Time.Sub(s.Samples[j-1].Time) <= maxGap {
+				drawLine(img, toX(s.Samples[j-1].Time), toY(s.Samples[j-1].Value), x, y, c)
+				joined = true
+			}
+			isolated := j == len(s.Samples)-1 || s.Samples[j+1].Time.Sub(sample.Time) > maxGap
+			if !joined && isolated {
+				img.Set(x, y, c)
+				img.Set(x+1, y, c)
+				img.Set(x, y+1, c)
+				img.Set(x+1, y+1, c)
+			}
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// valueRange returns the lowest and the highest values of the series.
+func valueRange(series []paneldata.Series) (float64, float64, bool) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, sample := range s.Samples {
+			low = min(low, sample.Value)
+			high = max(high, sample.Value)
+		}
+	}
+	if math.IsInf(low, 1) {
+		return 0, 0, false
+	}
+	if low == high {
+		delta := math.Max(math.Abs(low)/10, 1)
+		return low - delta, high + delta, true
+	}
+	return low, high, true
+}
+
+// valueTicks returns round values, about five, covering the range from low to high.
+func valueTicks(low float64, high float64) []float64 {
+	raw := (high - low) / 4
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, factor := range []float64{1, 2, 2.5, 5} {
+		if raw <= factor*magnitude {
+			step = factor * magnitude
+			break
+		}
+	}
+	var ticks []float64
+	for i := math.Floor(low / step); ; i++ {
+		// the ticks are computed from their index, to avoid the drift of the additions.
+		ticks = append(ticks, i*step)
+		if i*step >= high {
+			return ticks
+		}
+	}
+}
+
+var timeSteps = []time.Duration{
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour, 48 * time.Hour, 7 * 24 * time.Hour,
+}
+
+// timeTicks returns round times between start and end, six at most.
+func timeTicks(start time.Time, end time.Time) []time.Time {
+	step := timeSteps[len(timeSteps)-1]
+	for _, candidate := range timeSteps {
+		if end.Sub(start)/candidate <= 6 {
+			step = candidate
+			break
+		}
+	}
+	var ticks []time.Time
+	for tick := start.UTC().Truncate(step); !tick.After(end); tick = tick.Add(step) {
+		if !tick.Before(start) {
+			ticks = append(ticks, tick)
+		}
+	}
+	return ticks
+}
+
+// formatTime returns the label of a time of the X axis: the time of the day, or the date for the ranges of more than two days.
+func formatTime(t time.Time, span time.Duration) string {
+	if span > 48*time.Hour {
+		return t.UTC().Format("01-02")
+	}
+	return t.UTC().Format("15:04")
+}
+
+// formatValue returns a value with three significant digits at most and an SI prefix, e.g. 1.5k or 250m.
+func formatValue(value float64) string {
+	if value == 0 {
+		return "0"
+	}
+	prefixes := []struct {
+		scale  float64
+		prefix string
+	}{{1e15, "P"}, {1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}, {1, ""}, {1e-3, "m"}, {1e-6, "u"}, {1e-9, "n"}}
+	for _, p := range prefixes {
+		if math.Abs(value) >= p.scale {
+			return strconv.FormatFloat(value/p.scale, 'g', 3, 64) + p.prefix
+		}
+	}
+	return strconv.FormatFloat(value, 'g', 3, 64)
+}
+
+func parseColor(hex string) color.RGBA {
+	var c color.RGBA
+	c.A = 0xff
+	_, _ = fmt.Sscanf(hex, "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	return c
+}
+
+func drawHorizontal(img *image.RGBA, x1 int, x2 int, y int, c color.Color) {
+	for x := x1; x <= x2; x++ {
+		img.Set(x, y, c)
+	}
+}
+
+func drawVertical(img *image.RGBA, x int, y1 int, y2 int, c color.Color) {
+	for y := y1; y <= y2; y++ {
+		img.Set(x, y, c)
+	}
+}
+
+// drawLine draws a line two pixels thick from (x1, y1) to (x2, y2), with the Bresenham algorithm.
+func drawLine(img *image.RGBA, x1 int, y1 int, x2 int, y2 int, c color.Color) {
+	dx, dy := abs(x2-x1), -abs(y2-y1)
+	sx, sy := 1, 1
+	if x1 > x2 {
+		sx = -1
+	}
+	if y1 > y2 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		img.Set(x1, y1, c)
+		img.Set(x1, y1+1, c)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y1 += sy
+		}
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
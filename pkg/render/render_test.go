@@ -0,0 +1,215 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/paneldata"
+	"github.com/perses/mcp-server/pkg/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// newData returns the data of a panel over an hour, with a series per list of values sampled every minute.
+// The NaN values are left out of the samples.
+func newData(values ...[]float64) *paneldata.Data {
+	data := &paneldata.Data{Range: paneldata.Range{Start: start, End: start.Add(time.Hour), Step: time.Minute}}
+	for i, seriesValues := range values {
+		series := paneldata.Series{Name: fmt.Sprintf("series %d", i)}
+		for j, value := range seriesValues {
+			if math.IsNaN(value) {
+				continue
+			}
+			series.Samples = append(series.Samples, prometheus.Sample{Time: start.Add(time.Duration(j) * time.Minute), Value: value})
+		}
+		data.Series = append(data.Series, series)
+	}
+	return data
+}
+
+func TestPNG(t *testing.T) {
+	t.Run("chart", func(t *testing.T) {
+		data := newData([]float64{1, 2, 3, 2, 1}, []float64{10, 20})
+		result, err := PNG(data, 400, 200)
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(result))
+		require.NoError(t, err)
+		assert.Equal(t, 400, img.Bounds().Dx())
+		assert.Equal(t, 200, img.Bounds().Dy())
+	})
+
+	t.Run("no sample", func(t *testing.T) {
+		_, err := PNG(newData(), 400, 200)
+		assert.NoError(t, err)
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		_, err := PNG(newData([]float64{1}), 20, 20)
+		assert.Error(t, err)
+	})
+
+	t.Run("empty time range", func(t *testing.T) {
+		data := newData([]float64{1})
+		data.Range.End = data.Range.Start
+		_, err := PNG(data, 400, 200)
+		assert.Error(t, err)
+	})
+}
+
+func TestValueTicks(t *testing.T) {
+	testSuite := []struct {
+		title string
+		low   float64
+		high  float64
+		ticks []float64
+	}{
+		{
+			title: "unit range",
+			low:   0,
+			high:  1,
+			ticks: []float64{0, 0.25, 0.5, 0.75, 1},
+		},
+		{
+			title: "range not starting on a tick",
+			low:   3,
+			high:  97,
+			ticks: []float64{0, 25, 50, 75, 100},
+		},
+		{
+			title: "negative values",
+			low:   -15,
+			high:  15,
+			ticks: []float64{-20, -10, 0, 10, 20},
+		},
+		{
+			title: "large values",
+			low:   1e9,
+			high:  5e9,
+			ticks: []float64{1e9, 2e9, 3e9, 4e9, 5e9},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.ticks, valueTicks(test.low, test.high))
+		})
+	}
+}
+
+func TestValueRange(t *testing.T) {
+	low, high, ok := valueRange(newData([]float64{3, 1}, []float64{7}).Series)
+	assert.True(t, ok)
+	assert.Equal(t, []float64{1, 7}, []float64{low, high})
+
+	low, high, ok = valueRange(newData([]float64{50, 50}).Series)
+	assert.True(t, ok)
+	assert.Equal(t, []float64{45, 55}, []float64{low, high}, "a flat series is centered")
+
+	_, _, ok = valueRange(newData([]float64{}).Series)
+	assert.False(t, ok)
+}
+
+func TestTimeTicks(t *testing.T) {
+	testSuite := []struct {
+		title string
+		start time.Time
+		end   time.Time
+		ticks []string
+	}{
+		{
+			title: "hour",
+			start: start.Add(5 * time.Minute),
+			end:   start.Add(65 * time.Minute),
+			ticks: []string{"10:10", "10:20", "10:30", "10:40", "10:50", "11:00"},
+		},
+		{
+			title: "day",
+			start: start,
+			end:   start.Add(24 * time.Hour),
+			ticks: []string{"12:00", "18:00", "00:00", "06:00"},
+		},
+		{
+			title: "week",
+			start: start,
+			end:   start.Add(7 * 24 * time.Hour),
+			ticks: []string{"01-03", "01-05", "01-07"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var ticks []string
+			for _, tick := range timeTicks(test.start, test.end) {
+				ticks = append(ticks, formatTime(tick, test.end.Sub(test.start)))
+			}
+			assert.Equal(t, test.ticks, ticks)
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	testSuite := []struct {
+		value  float64
+		result string
+	}{
+		{value: 0, result: "0"},
+		{value: 1, result: "1"},
+		{value: 1500, result: "1.5k"},
+		{value: -2.5e6, result: "-2.5M"},
+		{value: 0.25, result: "250m"},
+		{value: 123456, result: "123k"},
+		{value: 1e-12, result: "1e-12"},
+	}
+	for _, test := range testSuite {
+		t.Run(test.result, func(t *testing.T) {
+			assert.Equal(t, test.result, formatValue(test.value))
+			for _, char := range test.result {
+				assert.Contains(t, glyphs, char, "the labels of the axes are drawn with the glyphs")
+			}
+		})
+	}
+}
+
+func TestSparklines(t *testing.T) {
+	nan := math.NaN()
+	data := newData(
+		[]float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10},
+		[]float64{5, 5, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, nan, 5},
+		[]float64{},
+	)
+	lines := strings.Split(strings.TrimSuffix(Sparklines(data, 4), "\n"), "\n")
+	assert.Equal(t, []string{
+		"SERIES    TREND  MIN  MAX  LAST",
+		"series 0  ▁█     0    10   10",
+		"series 1  ▅ ▅    5    5    5",
+		"series 2         -    -    -",
+	}, lines)
+}
+
+func TestSparklinesMaxRows(t *testing.T) {
+	values := make([][]float64, MaxRows+3)
+	for i := range values {
+		values[i] = []float64{float64(i)}
+	}
+	lines := strings.Split(strings.TrimSuffix(Sparklines(newData(values...), 10), "\n"), "\n")
+	require.Len(t, lines, MaxRows+2)
+	assert.Equal(t, "... 3 more series", strings.TrimSpace(lines[len(lines)-1]))
+}
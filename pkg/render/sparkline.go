@@ -0,0 +1,79 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/perses/mcp-server/pkg/paneldata"
+)
+
+// MaxRows is the number of series listed in a sparkline table, the other ones are counted on a last line.
+const MaxRows = 50
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparklines returns a table with a line per series: its name, its sparkline over the time range of the data,
+// and its minimum, maximum and last values. The sparkline has one character per period of the time range,
+// the average of the samples of the period, and is blank for the periods without samples.
+func Sparklines(data *paneldata.Data, width int) string {
+	var b strings.Builder
+	writer := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "SERIES\tTREND\tMIN\tMAX\tLAST")
+	for i, series := range data.Series {
+		if i == MaxRows {
+			_, _ = fmt.Fprintf(writer, "... %d more series\t\t\t\t\n", len(data.Series)-MaxRows)
+			break
+		}
+		stats := series.Stats()
+		if stats == nil {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t-\t-\t-\n", series.Name, strings.Repeat(" ", width))
+			continue
+		}
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", series.Name, sparkline(series, data.Range, width, stats),
+			formatValue(stats.Min), formatValue(stats.Max), formatValue(stats.Last))
+	}
+	_ = writer.Flush()
+	return b.String()
+}
+
+func sparkline(series paneldata.Series, timeRange paneldata.Range, width int, stats *paneldata.Stats) string {
+	sums := make([]float64, width)
+	counts := make([]int, width)
+	period := timeRange.End.Sub(timeRange.Start) / time.Duration(width)
+	for _, sample := range series.Samples {
+		i := 0
+		if period > 0 {
+			i = min(max(int(sample.Time.Sub(timeRange.Start)/period), 0), width-1)
+		}
+		sums[i] += sample.Value
+		counts[i]++
+	}
+	line := make([]rune, width)
+	for i := range line {
+		switch {
+		case counts[i] == 0:
+			line[i] = ' '
+		case stats.Max == stats.Min:
+			line[i] = sparks[len(sparks)/2]
+		default:
+			level := (sums[i]/float64(counts[i]) - stats.Min) / (stats.Max - stats.Min)
+			line[i] = sparks[min(int(level*float64(len(sparks))), len(sparks)-1)]
+		}
+	}
+	return string(line)
+}
@@ -0,0 +1,247 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package search finds the dashboards matching a set of criteria.
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// Weights of the criteria in the score of a match. A name is a stronger signal than a panel content.
+const (
+	exactNameScore   = 10
+	nameScore        = 5
+	displayNameScore = 4
+	tagScore         = 3
+	panelScore       = 2
+)
+
+// Criteria are the conditions a dashboard must meet. Every criterion that is set must match.
+// The matching is case-insensitive, except for QueryRegex.
+type Criteria struct {
+	// Name is a substring of the dashboard name.
+	Name string
+	// DisplayName is a substring of the dashboard display name.
+	DisplayName string
+	// Tag is a tag of the dashboard.
+	Tag string
+	// PanelTitle is a substring of the title of a panel.
+	PanelTitle string
+	// Query is a substring of a query expression (e.g. the PromQL query) of a panel.
+	Query string
+	// QueryRegex is a regular expression matched against the query expressions of the panels.
+	QueryRegex string
+	// Datasource is the name of a datasource used by a panel.
+	Datasource string
+}
+
+// IsEmpty returns true when no criterion is set.
+func (c Criteria) IsEmpty() bool {
+	return c == Criteria{}
+}
+
+func (c Criteria) hasPanelCriteria() bool {
+	return c.PanelTitle != "" || c.Query != "" || c.QueryRegex != "" || c.Datasource != ""
+}
+
+// Match is a dashboard meeting the criteria.
+type Match struct {
+	Project     string `json:"project"`
+	Dashboard   string `json:"dashboard"`
+	DisplayName string `json:"display_name,omitempty"`
+	Score       int    `json:"score"`
+	// Panels lists the panels meeting the panel criteria (title, query and datasource).
+	Panels []PanelMatch `json:"panels,omitempty"`
+}
+
+// PanelMatch is a panel meeting the panel criteria.
+type PanelMatch struct {
+	// ID is the key of the panel in the dashboard spec.
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	// Queries lists the query expressions of the panel matching the query criteria.
+	Queries []string `json:"queries,omitempty"`
+}
+
+// Dashboards returns the dashboards meeting the criteria, the best matches first.
+func Dashboards(list []*v1.Dashboard, criteria Criteria) ([]Match, error) {
+	var queryRegex *regexp.Regexp
+	if criteria.QueryRegex != "" {
+		var err error
+		if queryRegex, err = regexp.Compile(criteria.QueryRegex); err != nil {
+			return nil, fmt.Errorf("invalid query regex: %w", err)
+		}
+	}
+
+	var result []Match
+	for _, dashboard := range list {
+		match, ok := matchDashboard(dashboard, criteria, queryRegex)
+		if ok {
+			result = append(result, match)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].Project != result[j].Project {
+			return result[i].Project < result[j].Project
+		}
+		return result[i].Dashboard < result[j].Dashboard
+	})
+	return result, nil
+}
+
+func matchDashboard(dashboard *v1.Dashboard, criteria Criteria, queryRegex *regexp.Regexp) (Match, bool) {
+	match := Match{
+		Project:     dashboard.Metadata.Project,
+		Dashboard:   dashboard.Metadata.Name,
+		DisplayName: DisplayName(dashboard),
+	}
+	if criteria.Name != "" {
+		switch {
+		case strings.EqualFold(match.Dashboard, criteria.Name):
+			match.Score += exactNameScore
+		case contains(match.Dashboard, criteria.Name):
+			match.Score += nameScore
+		default:
+			return match, false
+		}
+	}
+	if criteria.DisplayName != "" {
+		if !contains(match.DisplayName, criteria.DisplayName) {
+			return match, false
+		}
+		match.Score += displayNameScore
+	}
+	if criteria.Tag != "" {
+		if !hasTag(dashboard, criteria.Tag) {
+			return match, false
+		}
+		match.Score += tagScore
+	}
+	if !criteria.hasPanelCriteria() {
+		return match, true
+	}
+
+	for _, id := range sortedPanelIDs(dashboard) {
+		if panel, ok := matchPanel(id, dashboard.Spec.Panels[id], criteria, queryRegex); ok {
+			match.Panels = append(match.Panels, panel)
+			match.Score += panelScore
+		}
+	}
+	return match, len(match.Panels) > 0
+}
+
+func matchPanel(id string, panel *v1.Panel, criteria Criteria, queryRegex *regexp.Regexp) (PanelMatch, bool) {
+	match := PanelMatch{ID: id, Title: PanelTitle(panel)}
+	if panel == nil {
+		return match, false
+	}
+	if criteria.PanelTitle != "" && !contains(match.Title, criteria.PanelTitle) {
+		return match, false
+	}
+	if criteria.Datasource != "" && !slices.ContainsFunc(PanelDatasources(panel), func(name string) bool { return strings.EqualFold(name, criteria.Datasource) }) {
+		return match, false
+	}
+	if criteria.Query == "" && queryRegex == nil {
+		return match, true
+	}
+	for _, query := range panel.Spec.Queries {
+		expression := QueryExpression(query)
+		if expression == "" {
+			continue
+		}
+		if criteria.Query != "" && !contains(expression, criteria.Query) {
+			continue
+		}
+		if queryRegex != nil && !queryRegex.MatchString(expression) {
+			continue
+		}
+		match.Queries = append(match.Queries, expression)
+	}
+	return match, len(match.Queries) > 0
+}
+
+// DisplayName returns the display name of the dashboard, or an empty string when it has none.
+func DisplayName(dashboard *v1.Dashboard) string {
+	if dashboard.Spec.Display != nil {
+		return dashboard.Spec.Display.Name
+	}
+	return ""
+}
+
+// PanelTitle returns the title of the panel, or an empty string when it has none.
+func PanelTitle(panel *v1.Panel) string {
+	if panel != nil && panel.Spec.Display != nil {
+		return panel.Spec.Display.Name
+	}
+	return ""
+}
+
+// QueryExpression returns the expression of the query (e.g. the PromQL query), or an empty string when its plugin has none.
+func QueryExpression(query v1.Query) string {
+	if spec, ok := query.Spec.Plugin.Spec.(map[string]any); ok {
+		if expression, ok := spec["query"].(string); ok {
+			return expression
+		}
+	}
+	return ""
+}
+
+// PanelDatasources returns the names of the datasources explicitly selected by the panel and its queries.
+// The queries without datasource name use the default datasource of their kind, which is not reported.
+func PanelDatasources(panel *v1.Panel) []string {
+	var result []string
+	specs := []any{panel.Spec.Plugin.Spec}
+	for _, query := range panel.Spec.Queries {
+		specs = append(specs, query.Spec.Plugin.Spec)
+	}
+	for _, spec := range specs {
+		pluginSpec, _ := spec.(map[string]any)
+		selector, _ := pluginSpec["datasource"].(map[string]any)
+		if name, ok := selector["name"].(string); ok && name != "" && !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func hasTag(dashboard *v1.Dashboard, tag string) bool {
+	for dashboardTag := range dashboard.Metadata.Tags {
+		if strings.EqualFold(dashboardTag, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedPanelIDs(dashboard *v1.Dashboard) []string {
+	ids := make([]string, 0, len(dashboard.Spec.Panels))
+	for id := range dashboard.Spec.Panels {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func contains(value string, substring string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substring))
+}
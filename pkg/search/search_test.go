@@ -0,0 +1,190 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dashboards returns the dashboards of the tests:
+//   - shop/api: "API overview", tagged "golden", with the panels "requests" (two Prometheus queries on thanos) and "logs" (a Loki query)
+//   - shop/api-errors: the panel "errors" on the default datasource
+//   - bank/api: "Bank API", with the panel "requests"
+func dashboards(t *testing.T) []*v1.Dashboard {
+	definitions := []string{
+		`{
+			"kind": "Dashboard",
+			"metadata": {"name": "api", "project": "shop", "tags": ["golden"]},
+			"spec": {
+				"display": {"name": "API overview"},
+				"panels": {
+					"requests": {"kind": "Panel", "spec": {"display": {"name": "Request rate"}, "plugin": {"kind": "TimeSeriesChart", "spec": {}}, "queries": [
+						{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "sum(rate(http_requests_total[5m]))", "datasource": {"kind": "PrometheusDatasource", "name": "thanos"}}}}},
+						{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "sum(rate(http_request_duration_seconds_count[5m]))"}}}}
+					]}},
+					"logs": {"kind": "Panel", "spec": {"display": {"name": "Error logs"}, "plugin": {"kind": "LogsTable", "spec": {}}, "queries": [
+						{"kind": "LogQuery", "spec": {"plugin": {"kind": "LokiLogQuery", "spec": {"query": "{app=\"api\"} |= \"error\""}}}}
+					]}}
+				},
+				"layouts": []
+			}
+		}`,
+		`{
+			"kind": "Dashboard",
+			"metadata": {"name": "api-errors", "project": "shop"},
+			"spec": {
+				"panels": {
+					"errors": {"kind": "Panel", "spec": {"display": {"name": "Error rate"}, "plugin": {"kind": "TimeSeriesChart", "spec": {}}, "queries": [
+						{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "sum(rate(http_requests_total{code=~\"5..\"}[5m]))"}}}}
+					]}}
+				},
+				"layouts": []
+			}
+		}`,
+		`{
+			"kind": "Dashboard",
+			"metadata": {"name": "api", "project": "bank"},
+			"spec": {
+				"display": {"name": "Bank API"},
+				"panels": {
+					"requests": {"kind": "Panel", "spec": {"display": {"name": "Requests"}, "plugin": {"kind": "TimeSeriesChart", "spec": {}}, "queries": [
+						{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "sum(rate(http_requests_total[5m]))"}}}}
+					]}}
+				},
+				"layouts": []
+			}
+		}`,
+	}
+	result := make([]*v1.Dashboard, 0, len(definitions))
+	for _, definition := range definitions {
+		var dashboardObj v1.Dashboard
+		require.NoError(t, json.Unmarshal([]byte(definition), &dashboardObj))
+		result = append(result, &dashboardObj)
+	}
+	return result
+}
+
+// matchNames returns "<project>/<dashboard>" for every match, in order.
+func matchNames(matches []Match) []string {
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, match.Project+"/"+match.Dashboard)
+	}
+	return names
+}
+
+func TestDashboards(t *testing.T) {
+	testSuite := []struct {
+		title    string
+		criteria Criteria
+		matches  []string
+	}{
+		{
+			title:    "exact name first",
+			criteria: Criteria{Name: "API"},
+			matches:  []string{"bank/api", "shop/api", "shop/api-errors"},
+		},
+		{
+			title:    "name substring",
+			criteria: Criteria{Name: "errors"},
+			matches:  []string{"shop/api-errors"},
+		},
+		{
+			title:    "display name",
+			criteria: Criteria{DisplayName: "overview"},
+			matches:  []string{"shop/api"},
+		},
+		{
+			title:    "tag",
+			criteria: Criteria{Tag: "Golden"},
+			matches:  []string{"shop/api"},
+		},
+		{
+			title:    "panel title",
+			criteria: Criteria{PanelTitle: "error"},
+			matches:  []string{"shop/api", "shop/api-errors"},
+		},
+		{
+			title:    "query text",
+			criteria: Criteria{Query: "HTTP_REQUESTS_TOTAL"},
+			matches:  []string{"bank/api", "shop/api", "shop/api-errors"},
+		},
+		{
+			title:    "query regex",
+			criteria: Criteria{QueryRegex: `code=~"5..`},
+			matches:  []string{"shop/api-errors"},
+		},
+		{
+			title:    "datasource",
+			criteria: Criteria{Datasource: "Thanos"},
+			matches:  []string{"shop/api"},
+		},
+		{
+			title:    "every criterion must match",
+			criteria: Criteria{Name: "api", Query: "rate(http_requests_total[5m])"},
+			matches:  []string{"bank/api", "shop/api"},
+		},
+		{
+			title:    "a criterion not matching",
+			criteria: Criteria{Tag: "golden", PanelTitle: "latency"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			matches, err := Dashboards(dashboards(t), test.criteria)
+			require.NoError(t, err)
+			var names []string
+			if len(matches) > 0 {
+				names = matchNames(matches)
+			}
+			assert.Equal(t, test.matches, names)
+		})
+	}
+}
+
+func TestDashboardsScore(t *testing.T) {
+	matches, err := Dashboards(dashboards(t), Criteria{Name: "api", Tag: "golden", Query: "rate"})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, exactNameScore+tagScore+panelScore, matches[0].Score)
+	assert.Equal(t, []PanelMatch{{
+		ID:      "requests",
+		Title:   "Request rate",
+		Queries: []string{"sum(rate(http_requests_total[5m]))", "sum(rate(http_request_duration_seconds_count[5m]))"},
+	}}, matches[0].Panels)
+
+	matches, err = Dashboards(dashboards(t), Criteria{Query: "sum"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bank/api", "shop/api", "shop/api-errors"}, matchNames(matches), "the ties are sorted by project and dashboard")
+
+	matches, err = Dashboards(dashboards(t), Criteria{Query: "error"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop/api"}, matchNames(matches), "a non-PromQL query is searched too")
+	assert.Equal(t, "logs", matches[0].Panels[0].ID)
+
+	matches, err = Dashboards(dashboards(t), Criteria{PanelTitle: "rate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop/api", "shop/api-errors"}, matchNames(matches))
+	assert.Equal(t, panelScore, matches[0].Score)
+}
+
+func TestDashboardsInvalidRegex(t *testing.T) {
+	_, err := Dashboards(dashboards(t), Criteria{QueryRegex: "rate("})
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package templates manages the dashboard templates: Perses dashboards with [[parameter]] placeholders,
+// shipped with the server or read from a directory.
+package templates
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed builtin/*.yaml
+var builtin embed.FS
+
+// BuiltinSource is the source of the templates shipped with the server.
+const BuiltinSource = "builtin"
+
+// placeholder matches the parameters used in a template, e.g. [[namespace]].
+var placeholder = regexp.MustCompile(`\[\[([a-zA-Z_][a-zA-Z0-9_]*)\]\]`)
+
+// Parameter is a value to provide when instantiating a template.
+type Parameter struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Default is the value used when none is provided. A parameter without default is required.
+	Default *string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// Template is a dashboard with placeholders, described by a YAML or JSON file.
+type Template struct {
+	// Name identifies the template. It defaults to the name of the file without extension.
+	Name        string      `json:"name" yaml:"name"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []Parameter `json:"parameters" yaml:"parameters"`
+	// Dashboard is the Perses dashboard, in which the strings can use the parameters as [[name]].
+	Dashboard map[string]any `json:"dashboard,omitempty" yaml:"dashboard"`
+	// Source is either "builtin" or the path of the file of the template, relative to the templates directory.
+	Source string `json:"source" yaml:"-"`
+}
+
+// Library gives access to the built-in templates and to the templates of a directory.
+type Library struct {
+	directory string
+}
+
+// New returns the library of the templates. The directory can be empty, in which case only the built-in templates are available.
+func New(directory string) *Library {
+	return &Library{directory: directory}
+}
+
+// List returns the templates sorted by name. The templates of the directory replace the built-in templates with the same name.
+// The directory is read at every call, so that the templates can be edited without restarting the server.
+func (l *Library) List() ([]*Template, error) {
+	templates, err := load(builtin, "builtin", BuiltinSource)
+	if err != nil {
+		return nil, err
+	}
+	if l.directory != "" {
+		custom, err := load(os.DirFS(l.directory), ".", "")
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range custom {
+			templates = slices.DeleteFunc(templates, func(t *Template) bool { return t.Name == template.Name })
+			templates = append(templates, template)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Get returns the template with the given name.
+func (l *Library) Get(name string) (*Template, error) {
+	templates, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(templates, func(t *Template) bool { return t.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("template '%s' doesn't exist", name)
+	}
+	return templates[i], nil
+}
+
+// load reads the templates of every YAML and JSON file of the directory of fsys and its sub-directories.
+// The source of the templates is the given one, or the path of their file when it is empty.
+func load(fsys fs.FS, root string, source string) ([]*Template, error) {
+	var result []*Template
+	err := fs.WalkDir(fsys, root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		switch path.Ext(filePath) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+		var template Template
+		if err := yaml.Unmarshal(data, &template); err != nil {
+			return fmt.Errorf("invalid template %s: %w", filePath, err)
+		}
+		if template.Name == "" {
+			template.Name = strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+		}
+		template.Source = source
+		if template.Source == "" {
+			template.Source = filepath.FromSlash(filePath)
+		}
+		if err := template.verify(); err != nil {
+			return fmt.Errorf("invalid template %s: %w", filePath, err)
+		}
+		result = append(result, &template)
+		return nil
+	})
+	return result, err
+}
+
+func (t *Template) verify() error {
+	if t.Dashboard == nil {
+		return fmt.Errorf("the dashboard is missing")
+	}
+	var declared []string
+	for _, parameter := range t.Parameters {
+		if !placeholder.MatchString("[[" + parameter.Name + "]]") {
+			return fmt.Errorf("invalid parameter name %q", parameter.Name)
+		}
+		if slices.Contains(declared, parameter.Name) {
+			return fmt.Errorf("parameter %q is declared more than once", parameter.Name)
+		}
+		declared = append(declared, parameter.Name)
+	}
+	for _, used := range t.placeholders() {
+		if !slices.Contains(declared, used) {
+			return fmt.Errorf("parameter %q is used but not declared", used)
+		}
+	}
+	return nil
+}
+
+// placeholders returns the parameters used by the dashboard of the template.
+func (t *Template) placeholders() []string {
+	var result []string
+	walkStrings(t.Dashboard, func(value string) string {
+		for _, match := range placeholder.FindAllStringSubmatch(value, -1) {
+			if !slices.Contains(result, match[1]) {
+				result = append(result, match[1])
+			}
+		}
+		return value
+	})
+	return result
+}
+
+// Instantiate returns the dashboard of the template with the placeholders replaced by the values, in the given project.
+// The name of the dashboard is the one of the template when name is empty.
+func (t *Template) Instantiate(project string, name string, values map[string]string) (*v1.Dashboard, error) {
+	resolved := make(map[string]string, len(t.Parameters))
+	var missing []string
+	for _, parameter := range t.Parameters {
+		value, ok := values[parameter.Name]
+		switch {
+		case ok:
+			resolved[parameter.Name] = value
+		case parameter.Default != nil:
+			resolved[parameter.Name] = *parameter.Default
+		default:
+			missing = append(missing, parameter.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing value for the parameters: %s", strings.Join(missing, ", "))
+	}
+	for parameter := range values {
+		if _, ok := resolved[parameter]; !ok {
+			return nil, fmt.Errorf("template '%s' has no parameter %q", t.Name, parameter)
+		}
+	}
+
+	// the template is copied through JSON, so that the strings are replaced without altering the template.
+	data, err := json.Marshal(t.Dashboard)
+	if err != nil {
+		return nil, err
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	raw = walkStrings(raw, func(value string) string {
+		return placeholder.ReplaceAllStringFunc(value, func(match string) string {
+			return resolved[placeholder.FindStringSubmatch(match)[1]]
+		})
+	})
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, err
+	}
+	var dashboard v1.Dashboard
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		return nil, fmt.Errorf("the dashboard of template '%s' is invalid with these values: %w", t.Name, err)
+	}
+	if name != "" {
+		dashboard.Metadata.Name = name
+	}
+	if dashboard.Metadata.Name == "" {
+		return nil, fmt.Errorf("the name of the dashboard is required, as template '%s' doesn't set one", t.Name)
+	}
+	dashboard.Metadata.Project = project
+	return &dashboard, nil
+}
+
+// walkStrings replaces every string of the value (but the keys of the maps) by the result of fn.
+func walkStrings(value any, fn func(string) string) any {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = walkStrings(item, fn)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = walkStrings(item, fn)
+		}
+	}
+	return value
+}
@@ -0,0 +1,245 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package templates
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/promql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const serviceTemplate = `
+name: service
+parameters:
+  - name: service
+  - name: metric
+    default: up
+dashboard:
+  kind: Dashboard
+  metadata:
+    name: "[[service]]"
+  spec:
+    display:
+      name: "[[service]] ([[metric]])"
+    duration: 1h
+    panels:
+      main:
+        kind: Panel
+        spec:
+          display:
+            name: "[[metric]]"
+          plugin:
+            kind: TimeSeriesChart
+            spec: {}
+          queries:
+            - kind: TimeSeriesQuery
+              spec:
+                plugin:
+                  kind: PrometheusTimeSeriesQuery
+                  spec:
+                    query: '[[metric]]{job="[[service]]"}'
+    layouts: []
+`
+
+// parseTemplate decodes the YAML definition of a template.
+func parseTemplate(t *testing.T, definition string) *Template {
+	var template Template
+	require.NoError(t, yaml.Unmarshal([]byte(definition), &template))
+	return &template
+}
+
+func TestInstantiate(t *testing.T) {
+	testSuite := []struct {
+		title       string
+		name        string
+		values      map[string]string
+		dashboard   string
+		displayName string
+		query       string
+		err         string
+	}{
+		{
+			title:       "placeholders replaced",
+			values:      map[string]string{"service": "checkout", "metric": "http_requests_total"},
+			dashboard:   "checkout",
+			displayName: "checkout (http_requests_total)",
+			query:       `http_requests_total{job="checkout"}`,
+		},
+		{
+			title:       "default value",
+			values:      map[string]string{"service": "checkout"},
+			dashboard:   "checkout",
+			displayName: "checkout (up)",
+			query:       `up{job="checkout"}`,
+		},
+		{
+			title:       "dashboard name",
+			name:        "checkout-overview",
+			values:      map[string]string{"service": "checkout"},
+			dashboard:   "checkout-overview",
+			displayName: "checkout (up)",
+			query:       `up{job="checkout"}`,
+		},
+		{
+			title:  "missing required parameter",
+			values: map[string]string{"metric": "up"},
+			err:    "missing value for the parameters: service",
+		},
+		{
+			title:  "unknown parameter",
+			values: map[string]string{"service": "checkout", "namespace": "shop"},
+			err:    `template 'service' has no parameter "namespace"`,
+		},
+		{
+			title:  "invalid dashboard with these values",
+			values: map[string]string{"service": ""},
+			err:    "the dashboard of template 'service' is invalid with these values: name cannot be empty",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			template := parseTemplate(t, serviceTemplate)
+			dashboardObj, err := template.Instantiate("shop", test.name, test.values)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "shop", dashboardObj.Metadata.Project)
+			assert.Equal(t, test.dashboard, dashboardObj.Metadata.Name)
+			assert.Equal(t, test.displayName, dashboardObj.Spec.Display.Name)
+			queries := promql.Queries(dashboardObj)
+			require.Len(t, queries, 1)
+			assert.Equal(t, test.query, queries[0].Expression)
+			assert.Equal(t, parseTemplate(t, serviceTemplate), template, "the template is not modified")
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	testSuite := []struct {
+		title      string
+		definition string
+		err        string
+	}{
+		{
+			title:      "valid template",
+			definition: serviceTemplate,
+		},
+		{
+			title:      "missing dashboard",
+			definition: "name: empty\nparameters: []\n",
+			err:        "the dashboard is missing",
+		},
+		{
+			title:      "invalid parameter name",
+			definition: "parameters:\n  - name: service-name\ndashboard:\n  kind: Dashboard\n",
+			err:        `invalid parameter name "service-name"`,
+		},
+		{
+			title:      "parameter declared twice",
+			definition: "parameters:\n  - name: service\n  - name: service\ndashboard:\n  kind: Dashboard\n",
+			err:        `parameter "service" is declared more than once`,
+		},
+		{
+			title:      "parameter not declared",
+			definition: "parameters:\n  - name: service\ndashboard:\n  kind: Dashboard\n  metadata:\n    name: '[[service]]-[[env]]'\n",
+			err:        `parameter "env" is used but not declared`,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			err := parseTemplate(t, test.definition).verify()
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+}
+
+func TestLibrary(t *testing.T) {
+	directory := t.TempDir()
+	override := strings.Replace(serviceTemplate, "name: service\n", "name: http-service\ndescription: Custom HTTP service\n", 1)
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "team"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "team", "http.yaml"), []byte(override), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "service.yaml"), []byte(strings.Replace(serviceTemplate, "name: service\n", "", 1)), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "README.md"), []byte("# Templates"), 0o600))
+
+	templates, err := New(directory).List()
+	require.NoError(t, err)
+	var sources []string
+	for _, template := range templates {
+		sources = append(sources, template.Name+" "+template.Source)
+	}
+	assert.Equal(t, []string{
+		"http-service " + filepath.Join("team", "http.yaml"),
+		"kubernetes-workload builtin",
+		"service service.yaml",
+	}, sources, "the template of the directory replaces the built-in one, and the name defaults to the file name")
+
+	template, err := New(directory).Get("http-service")
+	require.NoError(t, err)
+	assert.Equal(t, "Custom HTTP service", template.Description)
+
+	_, err = New(directory).Get("missing")
+	assert.EqualError(t, err, "template 'missing' doesn't exist")
+
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "invalid.yaml"), []byte("name: invalid\n"), 0o600))
+	_, err = New(directory).List()
+	assert.EqualError(t, err, "invalid template invalid.yaml: the dashboard is missing")
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	templates, err := New("").List()
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	for _, template := range templates {
+		t.Run(template.Name, func(t *testing.T) {
+			assert.Equal(t, BuiltinSource, template.Source)
+			assert.NotEmpty(t, template.Description)
+			values := map[string]string{}
+			for _, parameter := range template.Parameters {
+				assert.NotEmpty(t, parameter.Description, "parameter %s", parameter.Name)
+				if parameter.Default == nil {
+					values[parameter.Name] = "value_" + parameter.Name
+				}
+			}
+			dashboardObj, err := template.Instantiate("shop", "", values)
+			require.NoError(t, err)
+			assert.NotEmpty(t, dashboardObj.Metadata.Name)
+
+			data, err := json.Marshal(dashboardObj)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "[[", "every placeholder is replaced")
+
+			queries := promql.Queries(dashboardObj)
+			assert.NotEmpty(t, queries)
+			for _, query := range queries {
+				_, err := promql.ParseExpr(query.Expression)
+				assert.NoError(t, err, "%s: %s", query.Location, query.Expression)
+			}
+		})
+	}
+}
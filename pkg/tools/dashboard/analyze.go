@@ -0,0 +1,146 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/prometheus"
+	"github.com/perses/mcp-server/pkg/promql"
+	"github.com/perses/mcp-server/pkg/references"
+	"github.com/perses/mcp-server/pkg/tools"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type AnalyzeQueriesInput struct {
+	Project string `json:"project"`
+	Name    string `json:"name"`
+}
+
+type analyzeQueriesResult struct {
+	Queries int            `json:"queries"`
+	Issues  []promql.Issue `json:"issues"`
+	// Notes explains the checks that could not be run, e.g. when the metric metadata is not available.
+	Notes []string `json:"notes,omitempty"`
+}
+
+func (d *dashboard) AnalyzeQueries() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_analyze_dashboard_queries",
+		Description: "Parse every Prometheus query of a dashboard (panels and PromQL variables) with the PromQL parser and report the problems: " +
+			"syntax errors, rate()/irate()/increase() applied to gauges (when the metric metadata is available from the datasource), " +
+			"labels used in the legend but removed by an aggregation without the matching by clause, and expensive regex matchers. " +
+			"The dashboard variables are replaced by placeholders before parsing",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Analyzes the PromQL queries of a dashboard in Perses",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input AnalyzeQueriesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, d.client)
+		dashboardObj, err := client.Dashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+
+		queries := promql.Queries(dashboardObj)
+		result := analyzeQueriesResult{Queries: len(queries), Issues: []promql.Issue{}}
+		metricTypes := make(map[string]promql.MetricTypes)
+		for _, query := range queries {
+			types, ok := metricTypes[query.Datasource]
+			if !ok {
+				var note string
+				types, note = loadMetricTypes(client, input.Project, dashboardObj, query.Datasource)
+				metricTypes[query.Datasource] = types
+				if note != "" {
+					result.Notes = append(result.Notes, note)
+				}
+			}
+			result.Issues = append(result.Issues, promql.Analyze(query, types)...)
+		}
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling query analysis: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// loadMetricTypes returns the type of the metrics of the Prometheus datasource selected by name (the default one when the name is empty).
+// When the metadata is not available, it returns nil and a note explaining why.
+func loadMetricTypes(client apiClient.ClientInterface, project string, dashboardObj *v1.Dashboard, name string) (promql.MetricTypes, string) {
+	label := "the default Prometheus datasource"
+	if name != "" {
+		label = fmt.Sprintf("Prometheus datasource '%s'", name)
+	}
+	source, found, err := references.ResolveDatasource(client, project, dashboardObj, prometheus.DatasourceKind, name)
+	if err != nil {
+		return nil, fmt.Sprintf("the metric types of %s are not available, %s is not checked: %s", label, promql.RateOnGauge, err)
+	}
+	if !found {
+		return nil, fmt.Sprintf("%s doesn't exist, %s is not checked for its queries", label, promql.RateOnGauge)
+	}
+	metadata, err := prometheus.NewClient(client, project, dashboardObj.Metadata.Name, source).Metadata()
+	if err != nil {
+		return nil, fmt.Sprintf("the metric types of %s are not available, %s is not checked: %s", label, promql.RateOnGauge, err)
+	}
+	types := make(promql.MetricTypes, len(metadata))
+	for metric, entries := range metadata {
+		if len(entries) > 0 {
+			types[metric] = entries[0].Type
+		}
+	}
+	return types, ""
+}
@@ -0,0 +1,387 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	dashboardModel "github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+type CloneDashboardInput struct {
+	Project           string            `json:"project"`
+	Name              string            `json:"name"`
+	TargetProject     string            `json:"target_project,omitempty"`
+	NewName           string            `json:"new_name,omitempty"`
+	DatasourceMapping map[string]string `json:"datasource_mapping,omitempty"`
+	VariableMapping   map[string]string `json:"variable_mapping,omitempty"`
+}
+
+type RenameDashboardInput struct {
+	Project       string `json:"project"`
+	Name          string `json:"name"`
+	NewName       string `json:"new_name"`
+	TargetProject string `json:"target_project,omitempty"`
+}
+
+type cloneResult struct {
+	Dashboard *v1.Dashboard `json:"dashboard"`
+	// Warnings lists the references of the copy that cannot be resolved in the target project.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (d *dashboard) Clone() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_clone_dashboard",
+		Description: "Copy a dashboard under a new name and/or into another project. " +
+			"The datasource and variable references can be rewritten for the target project with datasource_mapping and variable_mapping. " +
+			"The references that cannot be resolved in the target project are reported as warnings. The tool fails if the target dashboard already exists",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard to copy",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard to copy",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"target_project": {
+					Type:        "string",
+					Description: "Project to copy the dashboard to (defaults to the source project)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"new_name": {
+					Type:        "string",
+					Description: "Name of the copy (defaults to the source name)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"datasource_mapping": {
+					Type:        "object",
+					Description: "Datasource references to rewrite, as a map of source datasource name to target datasource name",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"variable_mapping": {
+					Type: "object",
+					Description: "Variable references to rewrite, as a map of source variable name to target variable name. " +
+						"The variables of the dashboard in the mapping are renamed as well",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Copies a dashboard under a new name or into another project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CloneDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		result, err := cloneDashboard(tools.Client(ctx, d.client), input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error cloning dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling cloned dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+func (d *dashboard) Rename() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_rename_dashboard",
+		Description: "Rename a dashboard, optionally moving it to another project. " +
+			"The dashboard is copied under the new name, then the original is deleted. The tool fails if the new name is already used",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard to rename",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Current name of the dashboard",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"new_name": {
+					Type:        "string",
+					Description: "New name of the dashboard",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"target_project": {
+					Type:        "string",
+					Description: "Project to move the dashboard to (defaults to the current project)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+			},
+			Required: []string{"project", "name", "new_name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Renames a dashboard in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input RenameDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, d.client)
+		result, err := cloneDashboard(client, CloneDashboardInput{
+			Project:       input.Project,
+			Name:          input.Name,
+			TargetProject: input.TargetProject,
+			NewName:       input.NewName,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error renaming dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+		if err := client.Dashboard(input.Project).Delete(input.Name); err != nil {
+			return nil, nil, fmt.Errorf("dashboard '%s' was copied to '%s' in project '%s' but the original could not be deleted: %w",
+				input.Name, result.Dashboard.Metadata.Name, result.Dashboard.Metadata.Project, err)
+		}
+
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling renamed dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// cloneDashboard creates the copy of a dashboard described by the input and returns it.
+func cloneDashboard(client apiClient.ClientInterface, input CloneDashboardInput) (*cloneResult, error) {
+	targetProject := input.TargetProject
+	if targetProject == "" {
+		targetProject = input.Project
+	}
+	newName := input.NewName
+	if newName == "" {
+		newName = input.Name
+	}
+	if targetProject == input.Project && newName == input.Name {
+		return nil, fmt.Errorf("the copy must have another name or be in another project")
+	}
+
+	source, err := client.Dashboard(input.Project).Get(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	_, err = client.Dashboard(targetProject).Get(newName)
+	if err == nil {
+		return nil, fmt.Errorf("dashboard '%s' already exists in project '%s'", newName, targetProject)
+	}
+	if !objects.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to check whether dashboard '%s' exists in project '%s': %w", newName, targetProject, err)
+	}
+
+	renamed, err := objects.RenameDatasourceReferences(source, input.DatasourceMapping)
+	if err != nil {
+		return nil, err
+	}
+	if renamed, err = objects.RenameVariableReferences(renamed, input.VariableMapping); err != nil {
+		return nil, err
+	}
+	dashboardObj, ok := renamed.(*v1.Dashboard)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", renamed)
+	}
+	if err := renameVariables(dashboardObj, input.VariableMapping); err != nil {
+		return nil, err
+	}
+	dashboardObj.Metadata = v1.ProjectMetadata{
+		Metadata: v1.Metadata{Name: newName, Tags: source.Metadata.Tags},
+		ProjectMetadataWrapper: v1.ProjectMetadataWrapper{
+			Project: targetProject,
+		},
+	}
+
+	warnings, err := unresolvedReferences(client, dashboardObj)
+	if err != nil {
+		return nil, err
+	}
+	created, err := client.Dashboard(targetProject).Create(dashboardObj)
+	if err != nil {
+		return nil, err
+	}
+	return &cloneResult{Dashboard: created, Warnings: warnings}, nil
+}
+
+// renameVariables renames the variables defined by the dashboard according to the mapping, so that they match the renamed references.
+// A variable can't be renamed to the name of another variable of the dashboard.
+func renameVariables(dashboardObj *v1.Dashboard, mapping map[string]string) error {
+	names := make([]string, 0, len(dashboardObj.Spec.Variables))
+	for _, variable := range dashboardObj.Spec.Variables {
+		if variable.Spec == nil {
+			continue
+		}
+		name := variable.Spec.GetName()
+		if newName, renamed := mapping[name]; renamed {
+			name = newName
+		}
+		if slices.Contains(names, name) {
+			return fmt.Errorf("the variable_mapping defines variable '%s' more than once in the dashboard", name)
+		}
+		names = append(names, name)
+	}
+	for _, variable := range dashboardObj.Spec.Variables {
+		switch spec := variable.Spec.(type) {
+		case *dashboardModel.ListVariableSpec:
+			if newName, renamed := mapping[spec.Name]; renamed {
+				spec.Name = newName
+			}
+		case *dashboardModel.TextVariableSpec:
+			if newName, renamed := mapping[spec.Name]; renamed {
+				spec.Name = newName
+			}
+		}
+	}
+	return nil
+}
+
+// unresolvedReferences describes the datasources and variables referenced by the dashboard
+// that are neither defined in the dashboard itself, nor in its project, nor globally.
+func unresolvedReferences(client apiClient.ClientInterface, dashboardObj *v1.Dashboard) ([]string, error) {
+	project := dashboardObj.Metadata.Project
+	var warnings []string
+
+	datasourceRefs, err := objects.DatasourceReferences(dashboardObj)
+	if err != nil {
+		return nil, err
+	}
+	if len(datasourceRefs) > 0 {
+		known := make(map[string]bool)
+		for name := range dashboardObj.Spec.Datasources {
+			known[name] = true
+		}
+		projectDatasources, err := client.Datasource(project).List("")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the datasources of project '%s': %w", project, err)
+		}
+		for _, datasource := range projectDatasources {
+			known[datasource.Metadata.Name] = true
+		}
+		globalDatasources, err := client.GlobalDatasource().List("")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the global datasources: %w", err)
+		}
+		for _, datasource := range globalDatasources {
+			known[datasource.Metadata.Name] = true
+		}
+		for _, ref := range datasourceRefs {
+			if !known[ref.Name] {
+				warnings = append(warnings, fmt.Sprintf("datasource '%s' (%s) doesn't exist in project '%s'", ref.Name, ref.Kind, project))
+			}
+		}
+	}
+
+	variableRefs, err := objects.VariableReferences(dashboardObj)
+	if err != nil {
+		return nil, err
+	}
+	// The variables starting with __ are built in (e.g. $__interval, $__range).
+	variableRefs = slices.DeleteFunc(variableRefs, func(name string) bool { return strings.HasPrefix(name, "__") })
+	if len(variableRefs) > 0 {
+		known := make(map[string]bool)
+		for _, variable := range dashboardObj.Spec.Variables {
+			if variable.Spec != nil {
+				known[variable.Spec.GetName()] = true
+			}
+		}
+		projectVariables, err := client.Variable(project).List("")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the variables of project '%s': %w", project, err)
+		}
+		for _, variable := range projectVariables {
+			known[variable.Metadata.Name] = true
+		}
+		globalVariables, err := client.GlobalVariable().List("")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the global variables: %w", err)
+		}
+		for _, variable := range globalVariables {
+			known[variable.Metadata.Name] = true
+		}
+		for _, name := range variableRefs {
+			if !known[name] {
+				warnings = append(warnings, fmt.Sprintf("variable '%s' doesn't exist in project '%s'", name, project))
+			}
+		}
+	}
+	return warnings, nil
+}
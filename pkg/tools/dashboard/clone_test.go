@@ -0,0 +1,87 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const variablesDashboard = `{
+	"kind": "Dashboard",
+	"metadata": {"name": "overview", "project": "shop"},
+	"spec": {
+		"variables": [
+			{"kind": "ListVariable", "spec": {"name": "env", "plugin": {"kind": "StaticListVariable", "spec": {"values": ["prod", "staging"]}}}},
+			{"kind": "TextVariable", "spec": {"name": "filter", "value": "job=\"api\""}}
+		],
+		"panels": {},
+		"layouts": []
+	}
+}`
+
+func TestRenameVariables(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		mapping map[string]string
+		names   []string
+		wantErr bool
+	}{
+		{
+			title: "no mapping",
+			names: []string{"env", "filter"},
+		},
+		{
+			title:   "list and text variables are renamed",
+			mapping: map[string]string{"env": "environment", "filter": "selector"},
+			names:   []string{"environment", "selector"},
+		},
+		{
+			title:   "variables swapped",
+			mapping: map[string]string{"env": "filter", "filter": "env"},
+			names:   []string{"filter", "env"},
+		},
+		{
+			title:   "mapping of a variable that isn't defined by the dashboard",
+			mapping: map[string]string{"cluster": "region"},
+			names:   []string{"env", "filter"},
+		},
+		{
+			title:   "variable renamed to another variable of the dashboard",
+			mapping: map[string]string{"env": "filter"},
+			wantErr: true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			var dashboardObj v1.Dashboard
+			require.NoError(t, json.Unmarshal([]byte(variablesDashboard), &dashboardObj))
+			err := renameVariables(&dashboardObj, test.mapping)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(dashboardObj.Spec.Variables))
+			for _, variable := range dashboardObj.Spec.Variables {
+				names = append(names, variable.Spec.GetName())
+			}
+			assert.Equal(t, test.names, names)
+		})
+	}
+}
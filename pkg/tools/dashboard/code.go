@@ -0,0 +1,109 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/dac"
+	"github.com/perses/mcp-server/pkg/tools"
+)
+
+type ConvertToCodeInput struct {
+	Project  string `json:"project"`
+	Name     string `json:"name"`
+	Language string `json:"language,omitempty"`
+}
+
+func (d *dashboard) ConvertToCode() *tools.Tool {
+	languages := make([]any, 0, len(dac.Languages))
+	for _, language := range dac.Languages {
+		languages = append(languages, string(language))
+	}
+	tool := &mcp.Tool{
+		Name: "perses_convert_dashboard_to_code",
+		Description: "Convert an existing dashboard into an equivalent Dashboard-as-Code program, written with the Go SDK " +
+			"(a main package using the dashboard, panel group, panel, variable and datasource builders) or the CUE SDK (the dac-utils builders). " +
+			"Returns the code and warnings about what it doesn't reproduce exactly: the SDKs lay out the panels of a group in lines of panels of the same size " +
+			"and name the panels after their position",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Converts a dashboard into Dashboard-as-Code",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"language": {
+					Type:        "string",
+					Description: "Language of the program (default: go)",
+					Enum:        languages,
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ConvertToCodeInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		language := dac.LanguageGo
+		if input.Language != "" {
+			language = dac.Language(input.Language)
+		}
+		dashboardObj, err := tools.Client(ctx, d.client).Dashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+		program, err := dac.Generate(dashboardObj, language)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error converting dashboard '%s' in project '%s' to code: %w", input.Name, input.Project, err)
+		}
+
+		text, err := json.Marshal(program)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling dashboard code: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
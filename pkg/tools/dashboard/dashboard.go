@@ -20,6 +20,8 @@ import (
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/lint"
+	"github.com/perses/mcp-server/pkg/templates"
 	"github.com/perses/mcp-server/pkg/tools"
 	"github.com/perses/mcp-server/pkg/tools/resource"
 	apiClient "github.com/perses/perses/pkg/client/api/v1"
@@ -27,12 +29,19 @@ import (
 )
 
 type dashboard struct {
-	client apiClient.ClientInterface
+	client    apiClient.ClientInterface
+	linter    *lint.Linter
+	templates *templates.Library
+	// snapshotsDirectory is where the snapshots compared by perses_diff_dashboards are read. Reading snapshots is disabled when it is empty.
+	snapshotsDirectory string
 }
 
-func New(client apiClient.ClientInterface) resource.Resource {
+func New(client apiClient.ClientInterface, linter *lint.Linter, library *templates.Library, snapshotsDirectory string) resource.Resource {
 	return &dashboard{
-		client: client,
+		client:             client,
+		linter:             linter,
+		templates:          library,
+		snapshotsDirectory: snapshotsDirectory,
 	}
 }
 
@@ -40,9 +49,22 @@ func (d *dashboard) GetTools() []*tools.Tool {
 	return []*tools.Tool{
 		d.List(),
 		d.Get(),
+		d.Search(),
+		d.Lint(),
+		d.AnalyzeQueries(),
+		d.Generate(),
+		d.ConvertToCode(),
+		d.RenderPanel(),
+		d.ExplainPanel(),
+		d.Diff(),
+		d.ListTemplates(),
+		d.GetTemplate(),
+		d.InstantiateTemplate(),
 		d.Create(),
 		d.Update(),
 		d.Delete(),
+		d.Clone(),
+		d.Rename(),
 	}
 }
 
@@ -76,8 +98,8 @@ func (d *dashboard) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input ListDashboardsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		response, err := d.client.Dashboard(input.Project).List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ListDashboardsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		response, err := tools.Client(ctx, d.client).Dashboard(input.Project).List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving dashboards: %w", err)
 		}
@@ -142,8 +164,8 @@ func (d *dashboard) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetDashboardByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		response, err := d.client.Dashboard(input.Project).Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetDashboardByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		response, err := tools.Client(ctx, d.client).Dashboard(input.Project).Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -193,13 +215,13 @@ func (d *dashboard) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		var dashboardObj v1.Dashboard
 		if err := json.Unmarshal([]byte(input.Dashboard), &dashboardObj); err != nil {
 			return nil, nil, fmt.Errorf("invalid dashboard JSON: %w", err)
 		}
 
-		createdDashboard, err := d.client.Dashboard(input.Project).Create(&dashboardObj)
+		createdDashboard, err := tools.Client(ctx, d.client).Dashboard(input.Project).Create(&dashboardObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating dashboard in project '%s': %w", input.Project, err)
 		}
@@ -260,13 +282,13 @@ func (d *dashboard) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		var dashboardObj v1.Dashboard
 		if err := json.Unmarshal([]byte(input.Dashboard), &dashboardObj); err != nil {
 			return nil, nil, fmt.Errorf("invalid dashboard JSON: %w", err)
 		}
 
-		updatedDashboard, err := d.client.Dashboard(input.Project).Update(&dashboardObj)
+		updatedDashboard, err := tools.Client(ctx, d.client).Dashboard(input.Project).Update(&dashboardObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating dashboard in project '%s': %w", input.Project, err)
 		}
@@ -330,8 +352,8 @@ func (d *dashboard) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := d.client.Dashboard(input.Project).Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, d.client).Dashboard(input.Project).Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
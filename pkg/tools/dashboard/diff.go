@@ -0,0 +1,204 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/diff"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type DiffDashboardsInput struct {
+	Project         string `json:"project"`
+	Name            string `json:"name"`
+	AgainstProject  string `json:"against_project,omitempty"`
+	AgainstName     string `json:"against_name,omitempty"`
+	AgainstContent  string `json:"against_content,omitempty"`
+	AgainstSnapshot string `json:"against_snapshot,omitempty"`
+}
+
+type dashboardsDiff struct {
+	// Before describes the dashboard compared against, After the live dashboard.
+	Before string `json:"before"`
+	After  string `json:"after"`
+	*diff.DashboardDiff
+}
+
+func (d *dashboard) Diff() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_diff_dashboards",
+		Description: "Compare a live dashboard against another one: another live dashboard (against_name), a JSON or YAML definition (against_content), " +
+			"or the dashboard saved in a snapshot of the snapshots directory (against_snapshot). Returns the changes turning the other dashboard into the live one: " +
+			"panels added, removed or modified keyed by panel ID, with their display, plugin, query and layout changes, variables added, removed or modified, " +
+			"and the changes of the display, the panel groups and the other settings of the dashboard",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Compares two dashboards",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the live dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the live dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"against_project": {
+					Type:        "string",
+					Description: "Project of the other dashboard, live or in the snapshot (defaults to project)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"against_name": {
+					Type:        "string",
+					Description: "Name of the other dashboard, live or in the snapshot (defaults to name with against_snapshot)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"against_content": {
+					Type:        "string",
+					Description: "JSON or YAML definition of the other dashboard",
+				},
+				"against_snapshot": {
+					Type:        "string",
+					Description: "Snapshot containing the other dashboard: its directory in the snapshots directory configured in the MCP server, e.g. 20250101T120000Z",
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DiffDashboardsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, d.client)
+		live, err := client.Dashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+		againstProject := input.AgainstProject
+		if againstProject == "" {
+			againstProject = input.Project
+		}
+
+		var against modelAPI.Entity
+		result := &dashboardsDiff{After: fmt.Sprintf("dashboard '%s' in project '%s'", input.Name, input.Project)}
+		switch {
+		case input.AgainstContent != "" && (input.AgainstSnapshot != "" || input.AgainstName != ""):
+			return nil, nil, fmt.Errorf("against_content can't be used with against_name or against_snapshot")
+		case input.AgainstContent != "":
+			against, err = decodeDashboard([]byte(input.AgainstContent))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid against_content: %w", err)
+			}
+			result.Before = "the given definition"
+		case input.AgainstSnapshot != "":
+			againstName := input.AgainstName
+			if againstName == "" {
+				againstName = input.Name
+			}
+			against, err = d.snapshotDashboard(input.AgainstSnapshot, againstProject, againstName)
+			if err != nil {
+				return nil, nil, err
+			}
+			result.Before = fmt.Sprintf("dashboard '%s' in project '%s' of snapshot '%s'", againstName, againstProject, input.AgainstSnapshot)
+		case input.AgainstName != "":
+			against, err = client.Dashboard(againstProject).Get(input.AgainstName)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.AgainstName, againstProject, err)
+			}
+			result.Before = fmt.Sprintf("dashboard '%s' in project '%s'", input.AgainstName, againstProject)
+		default:
+			return nil, nil, fmt.Errorf("one of against_name, against_content and against_snapshot is required")
+		}
+
+		beforeMap, err := objects.Normalize(against)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error comparing dashboards: %w", err)
+		}
+		afterMap, err := objects.Normalize(live)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error comparing dashboards: %w", err)
+		}
+		result.DashboardDiff = diff.Dashboards(beforeMap, afterMap)
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling dashboards diff: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// decodeDashboard decodes the definition of a single dashboard.
+func decodeDashboard(data []byte) (modelAPI.Entity, error) {
+	list, err := gitops.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) != 1 || objects.Kind(list[0]) != v1.KindDashboard {
+		return nil, fmt.Errorf("expected the definition of a single dashboard")
+	}
+	return list[0], nil
+}
+
+// snapshotDashboard reads a dashboard from a snapshot taken by perses_delete_project, laid out like `permcp export`.
+func (d *dashboard) snapshotDashboard(snapshot string, project string, name string) (modelAPI.Entity, error) {
+	if d.snapshotsDirectory == "" {
+		return nil, fmt.Errorf("reading a snapshot is disabled: snapshots_directory is not configured")
+	}
+	// Cleaning the path as an absolute one prevents escaping the snapshots directory with "..".
+	dir := filepath.Join(d.snapshotsDirectory, filepath.Clean("/"+snapshot), project, v1.PluralKindMap[v1.KindDashboard])
+	list, err := gitops.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the dashboards of project '%s' in snapshot '%s': %w", project, snapshot, err)
+	}
+	for _, obj := range list {
+		if objects.Kind(obj) == v1.KindDashboard && objects.Name(obj) == name {
+			return obj, nil
+		}
+	}
+	return nil, fmt.Errorf("dashboard '%s' of project '%s' is not in snapshot '%s'", name, project, snapshot)
+}
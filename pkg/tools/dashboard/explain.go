@@ -0,0 +1,226 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/paneldata"
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+const (
+	// explainedSeries is the number of series whose statistics are given to the model.
+	explainedSeries  = 20
+	explainMaxTokens = 1000
+)
+
+const explainSystemPrompt = "You explain the panels of Perses observability dashboards to engineers who are not familiar with them. " +
+	"Given a panel, its queries, its settings and a summary of the data it currently displays, explain in plain English and in a few short paragraphs: " +
+	"what the panel measures, how each query computes it (name the metrics, functions, filters and aggregations), how the settings and transforms change what is shown, " +
+	"and what the current values say, pointing out anything unusual. Don't invent data that isn't in the summary, and mention the queries that couldn't run."
+
+type ExplainPanelInput struct {
+	Project   string            `json:"project"`
+	Name      string            `json:"name"`
+	Panel     string            `json:"panel"`
+	Duration  string            `json:"duration,omitempty"`
+	End       string            `json:"end,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+type explainedSeriesSummary struct {
+	Name  string           `json:"name"`
+	Query int              `json:"query"`
+	Stats *paneldata.Stats `json:"stats,omitempty"`
+}
+
+// panelContext is what the model is given to explain a panel.
+type panelContext struct {
+	*paneldata.Data
+	// Settings is the spec of the panel plugin, without its transforms.
+	Settings   map[string]any           `json:"settings,omitempty"`
+	Transforms any                      `json:"transforms,omitempty"`
+	Start      time.Time                `json:"start"`
+	End        time.Time                `json:"end"`
+	Step       string                   `json:"step"`
+	Series     []explainedSeriesSummary `json:"series"`
+	// OmittedSeries is the number of series left out of the summary.
+	OmittedSeries int `json:"omittedSeries,omitempty"`
+}
+
+type panelExplanation struct {
+	Context     panelContext `json:"context"`
+	Explanation string       `json:"explanation,omitempty"`
+	Model       string       `json:"model,omitempty"`
+}
+
+func (d *dashboard) ExplainPanel() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_explain_panel",
+		Description: "Explain in plain English what a panel of a dashboard shows: gather its queries (with the variables replaced), its settings and transforms, " +
+			"and a summary of the data it currently displays, then ask the model of the MCP client, through sampling, to explain them. " +
+			"Returns the explanation with the gathered context. When the client doesn't support sampling, only the context is returned",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(true),
+			ReadOnlyHint:    true,
+			Title:           "Explains a panel of a dashboard",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"panel": {
+					Type:        "string",
+					Description: "ID of the panel, its key in spec.panels",
+				},
+				"duration": {
+					Type:        "string",
+					Description: "Length of the time range of the data summary, e.g. 30m, 6h or 7d (defaults to the duration of the dashboard)",
+				},
+				"end": {
+					Type:        "string",
+					Description: "End of the time range of the data summary, as an RFC 3339 date (defaults to now)",
+				},
+				"variables": {
+					Type:        "object",
+					Description: "Values of the variables used by the queries, e.g. {\"namespace\": \"shop\"}. The other variables take their default value",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+			},
+			Required: []string{"project", "name", "panel"},
+		},
+	}
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input ExplainPanelInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, d.client)
+		dashboardObj, err := client.Dashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+		timeRange, err := renderRange(dashboardObj, input.Duration, input.End, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := paneldata.Fetch(client, dashboardObj, input.Panel, timeRange, input.Variables)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error running the queries of panel '%s' of dashboard '%s' in project '%s': %w", input.Panel, input.Name, input.Project, err)
+		}
+
+		panelCtx := panelContext{
+			Data:   data,
+			Start:  timeRange.Start,
+			End:    timeRange.End,
+			Step:   common.Duration(timeRange.Step).String(),
+			Series: make([]explainedSeriesSummary, 0, min(len(data.Series), explainedSeries)),
+		}
+		if spec, ok := dashboardObj.Spec.Panels[input.Panel].Spec.Plugin.Spec.(map[string]any); ok {
+			panelCtx.Settings = make(map[string]any, len(spec))
+			for key, value := range spec {
+				if key == "transforms" {
+					panelCtx.Transforms = value
+					continue
+				}
+				panelCtx.Settings[key] = value
+			}
+		}
+		for i, series := range data.Series {
+			if i == explainedSeries {
+				panelCtx.OmittedSeries = len(data.Series) - explainedSeries
+				break
+			}
+			panelCtx.Series = append(panelCtx.Series, explainedSeriesSummary{Name: series.Name, Query: series.Query, Stats: series.Stats()})
+		}
+
+		result := panelExplanation{Context: panelCtx}
+		if samplingSupported(req.Session) {
+			prompt, err := json.MarshalIndent(panelCtx, "", "  ")
+			if err != nil {
+				return nil, nil, fmt.Errorf("error marshalling panel context: %w", err)
+			}
+			message, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+				SystemPrompt: explainSystemPrompt,
+				Messages: []*mcp.SamplingMessage{
+					{
+						Role: "user",
+						Content: &mcp.TextContent{
+							Text: fmt.Sprintf("Explain the panel '%s' of the dashboard '%s' in the project '%s':\n\n%s", input.Panel, input.Name, input.Project, prompt),
+						},
+					},
+				},
+				MaxTokens: explainMaxTokens,
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("error asking the client to explain panel '%s': %w", input.Panel, err)
+			}
+			text, ok := message.Content.(*mcp.TextContent)
+			if !ok {
+				return nil, nil, fmt.Errorf("the client answered the explanation of panel '%s' with no text", input.Panel)
+			}
+			result.Explanation = text.Text
+			result.Model = message.Model
+		} else {
+			result.Context.Notes = append(result.Context.Notes, "the MCP client doesn't support sampling, the panel is not explained")
+		}
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling panel explanation: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// samplingSupported reports whether the client of the session declared the sampling capability.
+func samplingSupported(session *mcp.ServerSession) bool {
+	if session == nil {
+		return false
+	}
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Sampling != nil
+}
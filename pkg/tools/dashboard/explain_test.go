@@ -0,0 +1,139 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/persestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainedDashboard = `{
+	"kind": "Dashboard",
+	"metadata": {"name": "overview", "project": "shop"},
+	"spec": {
+		"duration": "1h",
+		"panels": {
+			"cpu": {"kind": "Panel", "spec": {"display": {"name": "CPU usage"}, "plugin": {"kind": "TimeSeriesChart", "spec": {"legend": {"position": "bottom"}, "transforms": [{"kind": "MergeSeries", "spec": {}}]}}, "queries": [
+				{"kind": "TimeSeriesQuery", "spec": {"plugin": {"kind": "PrometheusTimeSeriesQuery", "spec": {"query": "sum by (pod) (rate(container_cpu_usage_seconds_total[5m]))", "datasource": {"kind": "PrometheusDatasource", "name": "thanos"}}}}}
+			]}}
+		},
+		"layouts": []
+	}
+}`
+
+// serverSession connects a client with the given options to a server, and returns the session of the server.
+func serverSession(t *testing.T, clientOptions *mcp.ClientOptions) *mcp.ServerSession {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	session, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, clientOptions).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+	return session
+}
+
+func TestSamplingSupported(t *testing.T) {
+	assert.False(t, samplingSupported(nil))
+	assert.False(t, samplingSupported(serverSession(t, nil)))
+	sampling := &mcp.ClientOptions{
+		CreateMessageHandler: func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return &mcp.CreateMessageResult{}, nil
+		},
+	}
+	assert.True(t, samplingSupported(serverSession(t, sampling)))
+}
+
+func TestExplainPanel(t *testing.T) {
+	testSuite := []struct {
+		title string
+		// answer is the answer of the client to the sampling request. The client doesn't support sampling when it is nil.
+		answer      mcp.Content
+		explanation string
+		model       string
+		note        string
+		err         string
+	}{
+		{
+			title:       "text answer",
+			answer:      &mcp.TextContent{Text: "The panel shows the CPU used by each pod."},
+			explanation: "The panel shows the CPU used by each pod.",
+			model:       "test-model",
+		},
+		{
+			title: "client without sampling",
+			note:  "the MCP client doesn't support sampling, the panel is not explained",
+		},
+		{
+			title:  "non-text answer",
+			answer: &mcp.ImageContent{Data: []byte("image"), MIMEType: "image/png"},
+			err:    "the client answered the explanation of panel 'cpu' with no text",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			_, client := persestest.New(t, explainedDashboard)
+			var clientOptions *mcp.ClientOptions
+			var requests []*mcp.CreateMessageParams
+			if test.answer != nil {
+				clientOptions = &mcp.ClientOptions{
+					CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+						requests = append(requests, req.Params)
+						return &mcp.CreateMessageResult{Content: test.answer, Model: "test-model", Role: "assistant"}, nil
+					},
+				}
+			}
+			session := toolSession(t, (&dashboard{client: client}).ExplainPanel(), clientOptions)
+			text, isError := callTool(t, session, "perses_explain_panel", map[string]any{"project": "shop", "name": "overview", "panel": "cpu"})
+			if test.err != "" {
+				assert.True(t, isError)
+				assert.Contains(t, text, test.err)
+				return
+			}
+			require.False(t, isError, text)
+			var result panelExplanation
+			require.NoError(t, json.Unmarshal([]byte(text), &result))
+			assert.Equal(t, test.explanation, result.Explanation)
+			assert.Equal(t, test.model, result.Model)
+			assert.Equal(t, "CPU usage", result.Context.Title)
+			assert.Equal(t, map[string]any{"legend": map[string]any{"position": "bottom"}}, result.Context.Settings)
+			assert.NotNil(t, result.Context.Transforms, "the transforms are separated from the settings")
+			assert.Contains(t, result.Context.Notes, "query 0 is not run, its Prometheus datasource doesn't exist")
+			if test.note != "" {
+				assert.Contains(t, result.Context.Notes, test.note)
+			}
+
+			if test.answer == nil {
+				assert.Empty(t, requests)
+				return
+			}
+			require.Len(t, requests, 1)
+			assert.Equal(t, explainSystemPrompt, requests[0].SystemPrompt)
+			assert.Equal(t, explainMaxTokens, int(requests[0].MaxTokens))
+			require.Len(t, requests[0].Messages, 1)
+			prompt := requests[0].Messages[0].Content.(*mcp.TextContent).Text
+			assert.True(t, strings.HasPrefix(prompt, "Explain the panel 'cpu' of the dashboard 'overview' in the project 'shop':"))
+			assert.Contains(t, prompt, "container_cpu_usage_seconds_total")
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/generate"
+	"github.com/perses/mcp-server/pkg/tools"
+)
+
+type GenerateDashboardInput struct {
+	Service        string   `json:"service"`
+	Project        string   `json:"project,omitempty"`
+	Name           string   `json:"name,omitempty"`
+	DisplayName    string   `json:"display_name,omitempty"`
+	Patterns       []string `json:"patterns,omitempty"`
+	MetricFamilies []string `json:"metric_families,omitempty"`
+	Datasource     string   `json:"datasource,omitempty"`
+	ServiceLabel   string   `json:"service_label,omitempty"`
+	Variables      []string `json:"variables,omitempty"`
+	RequestsMetric string   `json:"requests_metric,omitempty"`
+	DurationMetric string   `json:"duration_metric,omitempty"`
+	StatusLabel    string   `json:"status_label,omitempty"`
+}
+
+func (d *dashboard) Generate() *tools.Tool {
+	patterns := make([]any, 0, len(generate.Patterns))
+	for _, pattern := range generate.Patterns {
+		patterns = append(patterns, string(pattern))
+	}
+	tool := &mcp.Tool{
+		Name: "perses_generate_dashboard",
+		Description: "Generate a complete Perses dashboard for a service from a high-level description, ready to be passed to perses_create_dashboard. " +
+			"The dashboard gets a row of panels per standard pattern (red: request rate, error ratio and latency percentiles; " +
+			"use: CPU, memory and file descriptors of the process; go_runtime: goroutines, heap, GC pauses and threads), " +
+			"a row with a panel per additional metric family, and a variable per label to filter by. " +
+			"Every panel has a title, a description, a unit and a legend. The dashboard is returned, not created",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Generates a dashboard for a service",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"service": {
+					Type:        "string",
+					Description: "Name of the service, matched against service_label in every query",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Project the dashboard is meant for",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard (defaults to the service name)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"display_name": {
+					Type:        "string",
+					Description: "Title of the dashboard (defaults to the service name)",
+				},
+				"patterns": {
+					Type:        "array",
+					Description: "Standard patterns to chart",
+					Items: &jsonschema.Schema{
+						Type: "string",
+						Enum: patterns,
+					},
+				},
+				"metric_families": {
+					Type:        "array",
+					Description: "Additional metrics to chart, e.g. queue_depth or jobs_processed_total. Counters are charted as a rate and histogram buckets as their 99th percentile",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"datasource": {
+					Type:        "string",
+					Description: "Name of the Prometheus datasource (defaults to the default Prometheus datasource)",
+				},
+				"service_label": {
+					Type:        "string",
+					Description: "Label identifying the service in the metrics (default: job)",
+				},
+				"variables": {
+					Type:        "array",
+					Description: "Labels to filter the dashboard by, e.g. namespace or instance. Each one becomes a variable, in the given order",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"requests_metric": {
+					Type:        "string",
+					Description: "Counter of the requests for the red pattern (default: http_requests_total)",
+				},
+				"duration_metric": {
+					Type:        "string",
+					Description: "Histogram of the request durations for the red pattern (default: http_request_duration_seconds)",
+				},
+				"status_label": {
+					Type:        "string",
+					Description: "Label of the requests counter holding the status code, for the red pattern (default: code)",
+				},
+			},
+			Required: []string{"service"},
+		},
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GenerateDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		spec := generate.Spec{
+			Service:        input.Service,
+			Project:        input.Project,
+			Name:           input.Name,
+			DisplayName:    input.DisplayName,
+			MetricFamilies: input.MetricFamilies,
+			Datasource:     input.Datasource,
+			ServiceLabel:   input.ServiceLabel,
+			Variables:      input.Variables,
+			RequestsMetric: input.RequestsMetric,
+			DurationMetric: input.DurationMetric,
+			StatusLabel:    input.StatusLabel,
+		}
+		for _, pattern := range input.Patterns {
+			spec.Patterns = append(spec.Patterns, generate.Pattern(pattern))
+		}
+		dashboardObj, err := generate.Dashboard(spec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error generating dashboard for service '%s': %w", input.Service, err)
+		}
+
+		text, err := json.Marshal(dashboardObj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling generated dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
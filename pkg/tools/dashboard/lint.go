@@ -0,0 +1,117 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type LintDashboardInput struct {
+	Project   string `json:"project,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Dashboard string `json:"dashboard,omitempty"`
+}
+
+func (d *dashboard) Lint() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_lint_dashboard",
+		Description: "Check a dashboard against the house style rules configured on the server " +
+			"(e.g. panels have a title and a description, counters are used with rate(), no hard-coded instance labels, time series have a unit). " +
+			"The dashboard is either an existing one (project and name) or a dashboard JSON not created yet. " +
+			"Returns the rule ID, the severity, the path of the offending field and a suggested fix for every finding",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Lints a dashboard in Perses",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard to lint",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard to lint",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"dashboard": {
+					Type:        "string",
+					Description: "Dashboard JSON as string, to lint a dashboard before creating it",
+				},
+			},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input LintDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		dashboardObj, err := dashboardToAnalyze(tools.Client(ctx, d.client), input.Project, input.Name, input.Dashboard)
+		if err != nil {
+			return nil, nil, err
+		}
+		findings := d.linter.Lint(dashboardObj)
+		text, err := json.Marshal(findings)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling lint findings: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// dashboardToAnalyze returns the dashboard given as JSON, or the existing dashboard of the project when the JSON is empty.
+func dashboardToAnalyze(client apiClient.ClientInterface, project string, name string, dashboardJSON string) (*v1.Dashboard, error) {
+	switch {
+	case dashboardJSON != "" && name != "":
+		return nil, fmt.Errorf("only one of dashboard and name can be provided")
+	case dashboardJSON != "":
+		var dashboardObj v1.Dashboard
+		if err := json.Unmarshal([]byte(dashboardJSON), &dashboardObj); err != nil {
+			return nil, fmt.Errorf("invalid dashboard JSON: %w", err)
+		}
+		return &dashboardObj, nil
+	case project == "" || name == "":
+		return nil, fmt.Errorf("either dashboard, or project and name must be provided")
+	}
+	dashboardObj, err := client.Dashboard(project).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", name, project, err)
+	}
+	return dashboardObj, nil
+}
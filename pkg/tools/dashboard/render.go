@@ -0,0 +1,261 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/paneldata"
+	"github.com/perses/mcp-server/pkg/render"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+const (
+	renderFormatPNG  = "png"
+	renderFormatText = "text"
+
+	defaultRenderDuration = time.Hour
+	// renderPoints is the number of points of a series over the time range when no step is given.
+	renderPoints  = 240
+	minRenderStep = 15 * time.Second
+)
+
+type RenderPanelInput struct {
+	Project   string            `json:"project"`
+	Name      string            `json:"name"`
+	Panel     string            `json:"panel"`
+	Duration  string            `json:"duration,omitempty"`
+	End       string            `json:"end,omitempty"`
+	Step      string            `json:"step,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Format    string            `json:"format,omitempty"`
+	Width     int               `json:"width,omitempty"`
+	Height    int               `json:"height,omitempty"`
+}
+
+type renderedSeries struct {
+	Name  string           `json:"name"`
+	Query int              `json:"query"`
+	Color string           `json:"color,omitempty"`
+	Stats *paneldata.Stats `json:"stats,omitempty"`
+}
+
+type renderSummary struct {
+	*paneldata.Data
+	Start  time.Time        `json:"start"`
+	End    time.Time        `json:"end"`
+	Step   string           `json:"step"`
+	Series []renderedSeries `json:"series"`
+}
+
+func (d *dashboard) RenderPanel() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_render_panel",
+		Description: "Show what a panel of a dashboard displays: run its Prometheus queries through the datasource proxy over a time range " +
+			"and draw the series locally, as a PNG line chart (format png) or as a table of Unicode sparklines with the minimum, maximum and last values (format text). " +
+			"The result also lists the queries as run, the series with their color and statistics, and notes about the queries that couldn't run",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(true),
+			ReadOnlyHint:    true,
+			Title:           "Renders a panel of a dashboard",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"panel": {
+					Type:        "string",
+					Description: "ID of the panel, its key in spec.panels",
+				},
+				"duration": {
+					Type:        "string",
+					Description: "Length of the time range, e.g. 30m, 6h or 7d (defaults to the duration of the dashboard)",
+				},
+				"end": {
+					Type:        "string",
+					Description: "End of the time range, as an RFC 3339 date (defaults to now)",
+				},
+				"step": {
+					Type:        "string",
+					Description: "Interval between two points of a series, e.g. 1m (defaults to the duration divided by 240, 15s at least)",
+				},
+				"variables": {
+					Type:        "object",
+					Description: "Values of the variables used by the queries, e.g. {\"namespace\": \"shop\"}. The other variables take their default value",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"format": {
+					Type:        "string",
+					Description: "png for an image, text for a table of sparklines (default: png)",
+					Enum:        []any{renderFormatPNG, renderFormatText},
+				},
+				"width": {
+					Type:        "integer",
+					Description: "Width of the image in pixels, or number of characters of the sparklines (default: 800 pixels, 60 characters)",
+					Minimum:     jsonschema.Ptr(10.0),
+					Maximum:     jsonschema.Ptr(2000.0),
+				},
+				"height": {
+					Type:        "integer",
+					Description: "Height of the image in pixels (default: 400)",
+					Minimum:     jsonschema.Ptr(100.0),
+					Maximum:     jsonschema.Ptr(2000.0),
+				},
+			},
+			Required: []string{"project", "name", "panel"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input RenderPanelInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, d.client)
+		dashboardObj, err := client.Dashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+		timeRange, err := renderRange(dashboardObj, input.Duration, input.End, input.Step)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := paneldata.Fetch(client, dashboardObj, input.Panel, timeRange, input.Variables)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error running the queries of panel '%s' of dashboard '%s' in project '%s': %w", input.Panel, input.Name, input.Project, err)
+		}
+
+		summary := renderSummary{
+			Data:   data,
+			Start:  timeRange.Start,
+			End:    timeRange.End,
+			Step:   common.Duration(timeRange.Step).String(),
+			Series: make([]renderedSeries, 0, len(data.Series)),
+		}
+		for i, series := range data.Series {
+			rendered := renderedSeries{Name: series.Name, Query: series.Query, Stats: series.Stats()}
+			if input.Format != renderFormatText && i < render.MaxSeries {
+				rendered.Color = render.SeriesColor(i)
+			}
+			summary.Series = append(summary.Series, rendered)
+		}
+
+		var content mcp.Content
+		if input.Format == renderFormatText {
+			width := input.Width
+			if width == 0 {
+				width = 60
+			}
+			content = &mcp.TextContent{Text: render.Sparklines(data, min(width, 200))}
+		} else {
+			width, height := input.Width, input.Height
+			if width == 0 {
+				width = 800
+			}
+			if height == 0 {
+				height = 400
+			}
+			if len(data.Series) > render.MaxSeries {
+				summary.Notes = append(summary.Notes, fmt.Sprintf("only the first %d series of %d are drawn", render.MaxSeries, len(data.Series)))
+			}
+			image, err := render.PNG(data, width, height)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error drawing panel '%s': %w", input.Panel, err)
+			}
+			content = &mcp.ImageContent{Data: image, MIMEType: "image/png"}
+		}
+
+		text, err := json.Marshal(summary)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling panel data: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+				content,
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// renderRange returns the time range ending at end (now when empty) and lasting the duration (the one of the dashboard when empty).
+// The step defaults to the duration divided by renderPoints.
+func renderRange(dashboardObj *v1.Dashboard, duration string, end string, step string) (paneldata.Range, error) {
+	timeRange := paneldata.Range{End: time.Now()}
+	if end != "" {
+		parsed, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return timeRange, fmt.Errorf("invalid end %q, expected an RFC 3339 date: %w", end, err)
+		}
+		timeRange.End = parsed
+	}
+	length := defaultRenderDuration
+	if duration == "" {
+		duration = string(dashboardObj.Spec.Duration)
+	}
+	if duration != "" {
+		parsed, err := common.ParseDuration(duration)
+		if err != nil {
+			return timeRange, fmt.Errorf("invalid duration %q: %w", duration, err)
+		}
+		length = time.Duration(parsed)
+	}
+	if length <= 0 {
+		return timeRange, fmt.Errorf("the duration must be positive")
+	}
+	timeRange.Start = timeRange.End.Add(-length)
+	timeRange.Step = max((length / renderPoints).Truncate(time.Second), minRenderStep)
+	if step != "" {
+		parsed, err := common.ParseDuration(step)
+		if err != nil {
+			return timeRange, fmt.Errorf("invalid step %q: %w", step, err)
+		}
+		if parsed <= 0 {
+			return timeRange, fmt.Errorf("the step must be positive")
+		}
+		timeRange.Step = time.Duration(parsed)
+	}
+	// Prometheus refuses the range queries returning more than 11,000 points per series.
+	if length/timeRange.Step > 11000 {
+		return timeRange, fmt.Errorf("the step %s is too small for a duration of %s", common.Duration(timeRange.Step), common.Duration(length))
+	}
+	return timeRange, nil
+}
@@ -0,0 +1,158 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/search"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const defaultSearchLimit = 20
+
+type SearchDashboardsInput struct {
+	Name        string   `json:"name,omitempty"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Tag         string   `json:"tag,omitempty"`
+	PanelTitle  string   `json:"panel_title,omitempty"`
+	Query       string   `json:"query,omitempty"`
+	QueryRegex  string   `json:"query_regex,omitempty"`
+	Datasource  string   `json:"datasource,omitempty"`
+	Projects    []string `json:"projects,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+}
+
+func (d *dashboard) Search() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_search_dashboards",
+		Description: "Search the dashboards of every accessible project by name, display name, tag, panel title, query text (substring or regex) and datasource. " +
+			"Every criterion provided must match. Returns the matches ranked by relevance, with the project, the dashboard and the IDs of the matching panels",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Searches dashboards across projects in Perses",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Substring of the dashboard name",
+				},
+				"display_name": {
+					Type:        "string",
+					Description: "Substring of the dashboard display name",
+				},
+				"tag": {
+					Type:        "string",
+					Description: "Tag of the dashboard",
+				},
+				"panel_title": {
+					Type:        "string",
+					Description: "Substring of the title of a panel",
+				},
+				"query": {
+					Type:        "string",
+					Description: "Substring of a query of a panel (e.g. a PromQL metric name)",
+				},
+				"query_regex": {
+					Type:        "string",
+					Description: "Regular expression (RE2 syntax) matched against the queries of the panels",
+				},
+				"datasource": {
+					Type:        "string",
+					Description: "Name of a datasource used by a panel",
+				},
+				"projects": {
+					Type:        "array",
+					Description: "Projects to search in (defaults to every accessible project)",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"limit": {
+					Type:        "integer",
+					Description: "Maximum number of dashboards to return",
+					Minimum:     jsonschema.Ptr(1.0),
+					Default:     json.RawMessage(fmt.Sprint(defaultSearchLimit)),
+				},
+			},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input SearchDashboardsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		criteria := search.Criteria{
+			Name:        input.Name,
+			DisplayName: input.DisplayName,
+			Tag:         input.Tag,
+			PanelTitle:  input.PanelTitle,
+			Query:       input.Query,
+			QueryRegex:  input.QueryRegex,
+			Datasource:  input.Datasource,
+		}
+		if criteria.IsEmpty() {
+			return nil, nil, fmt.Errorf("at least one search criterion must be provided")
+		}
+
+		// An empty project lists the dashboards of every project the caller can access.
+		list, err := tools.Client(ctx, d.client).Dashboard("").List("")
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving dashboards: %w", err)
+		}
+		if len(input.Projects) > 0 {
+			list = slices.DeleteFunc(list, func(dashboard *v1.Dashboard) bool {
+				return !slices.Contains(input.Projects, dashboard.Metadata.Project)
+			})
+		}
+		matches, err := search.Dashboards(list, criteria)
+		if err != nil {
+			return nil, nil, err
+		}
+		limit := input.Limit
+		if limit <= 0 {
+			limit = defaultSearchLimit
+		}
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+
+		text, err := json.Marshal(matches)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling search results: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
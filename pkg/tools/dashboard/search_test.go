@@ -0,0 +1,99 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/persestest"
+	"github.com/perses/mcp-server/pkg/search"
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toolSession registers the tool on a server, and returns a client session calling it.
+func toolSession(t *testing.T, tool *tools.Tool, clientOptions *mcp.ClientOptions) *mcp.ClientSession {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	tool.RegisterWith(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, clientOptions).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// callTool calls the tool, and returns the text of its result and whether it is an error.
+func callTool(t *testing.T, session *mcp.ClientSession, name string, arguments map[string]any) (string, bool) {
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: arguments})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	return result.Content[0].(*mcp.TextContent).Text, result.IsError
+}
+
+func TestSearchDashboards(t *testing.T) {
+	_, client := persestest.New(t,
+		`{"kind":"Dashboard","metadata":{"name":"api","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`,
+		`{"kind":"Dashboard","metadata":{"name":"api-errors","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`,
+		`{"kind":"Dashboard","metadata":{"name":"api","project":"bank"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`,
+		`{"kind":"Dashboard","metadata":{"name":"nodes","project":"infra"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`,
+	)
+	session := toolSession(t, (&dashboard{client: client}).Search(), nil)
+	testSuite := []struct {
+		title     string
+		arguments map[string]any
+		matches   []string
+	}{
+		{
+			title:     "every project",
+			arguments: map[string]any{"name": "api"},
+			matches:   []string{"bank/api", "shop/api", "shop/api-errors"},
+		},
+		{
+			title:     "project filter",
+			arguments: map[string]any{"name": "api", "projects": []string{"shop", "infra"}},
+			matches:   []string{"shop/api", "shop/api-errors"},
+		},
+		{
+			title:     "limit",
+			arguments: map[string]any{"name": "api", "limit": 1},
+			matches:   []string{"bank/api"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			text, isError := callTool(t, session, "perses_search_dashboards", test.arguments)
+			require.False(t, isError, text)
+			var matches []search.Match
+			require.NoError(t, json.Unmarshal([]byte(text), &matches))
+			names := make([]string, 0, len(matches))
+			for _, match := range matches {
+				names = append(names, match.Project+"/"+match.Dashboard)
+			}
+			assert.Equal(t, test.matches, names)
+		})
+	}
+
+	text, isError := callTool(t, session, "perses_search_dashboards", map[string]any{"projects": []string{"shop"}})
+	assert.True(t, isError)
+	assert.Contains(t, text, "at least one search criterion must be provided")
+}
@@ -0,0 +1,222 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/templates"
+	"github.com/perses/mcp-server/pkg/tools"
+)
+
+type ListTemplatesInput struct{}
+
+func (d *dashboard) ListTemplates() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_list_dashboard_templates",
+		Description: "List the dashboard templates: the built-in ones (kubernetes-workload, http-service) and the ones of the templates directory of the server. " +
+			"Returns the name, the description, the parameters and the source of every template",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Lists dashboard templates",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{},
+		},
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest, _ ListTemplatesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		list, err := d.templates.List()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing dashboard templates: %w", err)
+		}
+		summaries := make([]templates.Template, 0, len(list))
+		for _, template := range list {
+			summary := *template
+			summary.Dashboard = nil
+			summaries = append(summaries, summary)
+		}
+		text, err := json.Marshal(summaries)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling dashboard templates: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type GetTemplateInput struct {
+	Template string `json:"template"`
+}
+
+func (d *dashboard) GetTemplate() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_get_dashboard_template",
+		Description: "Get a dashboard template with its parameters (name, description and default value; the parameters without default are required) " +
+			"and its dashboard, in which the parameters appear as [[name]]",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Gets a dashboard template",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"template": {
+					Type:        "string",
+					Description: "Name of the template",
+				},
+			},
+			Required: []string{"template"},
+		},
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetTemplateInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		template, err := d.templates.Get(input.Template)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := json.Marshal(template)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling dashboard template: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type InstantiateTemplateInput struct {
+	Template   string            `json:"template"`
+	Project    string            `json:"project"`
+	Name       string            `json:"name,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	DryRun     bool              `json:"dry_run,omitempty"`
+}
+
+func (d *dashboard) InstantiateTemplate() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_instantiate_dashboard_template",
+		Description: "Create a dashboard in a project from a dashboard template, replacing the [[parameter]] placeholders by the given values " +
+			"(or by their default value). With dry_run, the dashboard is returned without being created",
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Creates a dashboard from a template in Perses",
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"template": {
+					Type:        "string",
+					Description: "Name of the template",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Project to create the dashboard in",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the dashboard (defaults to the name set by the template)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"parameters": {
+					Type:        "object",
+					Description: "Values of the parameters of the template, e.g. {\"namespace\": \"shop\", \"workload\": \"checkout\"}",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Return the dashboard without creating it",
+				},
+			},
+			Required: []string{"template", "project"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input InstantiateTemplateInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		template, err := d.templates.Get(input.Template)
+		if err != nil {
+			return nil, nil, err
+		}
+		dashboardObj, err := template.Instantiate(input.Project, input.Name, input.Parameters)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error instantiating template '%s': %w", input.Template, err)
+		}
+		if !input.DryRun {
+			if dashboardObj, err = tools.Client(ctx, d.client).Dashboard(input.Project).Create(dashboardObj); err != nil {
+				return nil, nil, fmt.Errorf("error creating dashboard in project '%s': %w", input.Project, err)
+			}
+		}
+
+		text, err := json.Marshal(dashboardObj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling created dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.DashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
@@ -76,8 +76,8 @@ func (d *datasource) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input ListProjectDatasourcesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		datasources, err := d.client.Datasource(input.Project).List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ListProjectDatasourcesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		datasources, err := tools.Client(ctx, d.client).Datasource(input.Project).List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving datasources in project '%s': %w", input.Project, err)
 		}
@@ -141,8 +141,8 @@ func (d *datasource) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetProjectDatasourceByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		datasource, err := d.client.Datasource(input.Project).Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetProjectDatasourceByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		datasource, err := tools.Client(ctx, d.client).Datasource(input.Project).Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving datasource '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -203,13 +203,13 @@ func (d *datasource) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		var datasourceObj v1.Datasource
 		if err := json.Unmarshal([]byte(input.Datasource), &datasourceObj); err != nil {
 			return nil, nil, fmt.Errorf("invalid datasource JSON: %w", err)
 		}
 
-		createdDatasource, err := d.client.Datasource(input.Project).Create(&datasourceObj)
+		createdDatasource, err := tools.Client(ctx, d.client).Datasource(input.Project).Create(&datasourceObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating datasource in project '%s': %w", input.Project, err)
 		}
@@ -270,13 +270,13 @@ func (d *datasource) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		var datasourceObj v1.Datasource
 		if err := json.Unmarshal([]byte(input.Datasource), &datasourceObj); err != nil {
 			return nil, nil, fmt.Errorf("invalid datasource JSON: %w", err)
 		}
 
-		updatedDatasource, err := d.client.Datasource(input.Project).Update(&datasourceObj)
+		updatedDatasource, err := tools.Client(ctx, d.client).Datasource(input.Project).Update(&datasourceObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating datasource in project '%s': %w", input.Project, err)
 		}
@@ -340,8 +340,8 @@ func (d *datasource) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := d.client.Datasource(input.Project).Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, d.client).Datasource(input.Project).Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting datasource '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
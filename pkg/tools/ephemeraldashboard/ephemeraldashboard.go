@@ -0,0 +1,432 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ephemeraldashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/perses/mcp-server/pkg/tools/resource"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+type ephemeralDashboard struct {
+	client apiClient.ClientInterface
+}
+
+func New(client apiClient.ClientInterface) resource.Resource {
+	return &ephemeralDashboard{
+		client: client,
+	}
+}
+
+func (e *ephemeralDashboard) GetTools() []*tools.Tool {
+	return []*tools.Tool{
+		e.List(),
+		e.Get(),
+		e.Create(),
+		e.Update(),
+		e.Delete(),
+		e.Promote(),
+	}
+}
+
+type ListEphemeralDashboardsInput struct {
+	Project string `json:"project" jsonschema:"Project name to list ephemeral dashboards from"`
+}
+
+func (e *ephemeralDashboard) List() *tools.Tool {
+	tool := &mcp.Tool{
+		Name:        "perses_list_ephemeral_dashboards",
+		Description: "List ephemeral dashboards for a specific project",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+			},
+			Required: []string{"project"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Lists ephemeral dashboards for a specific project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ListEphemeralDashboardsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		response, err := tools.Client(ctx, e.client).EphemeralDashboard(input.Project).List("")
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving ephemeral dashboards in project '%s': %w", input.Project, err)
+		}
+
+		dashboardsJSON, err := json.Marshal(response)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling ephemeral dashboards: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(dashboardsJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type GetEphemeralDashboardByNameInput struct {
+	Project string `json:"project" jsonschema:"Project name"`
+	Name    string `json:"name" jsonschema:"Ephemeral dashboard name"`
+}
+
+func (e *ephemeralDashboard) Get() *tools.Tool {
+	tool := &mcp.Tool{
+		Name:        "perses_get_ephemeral_dashboard_by_name",
+		Description: "Get an ephemeral dashboard by name in a specific project",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Ephemeral dashboard name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    true,
+			Title:           "Gets an ephemeral dashboard by name in a specific project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetEphemeralDashboardByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		response, err := tools.Client(ctx, e.client).EphemeralDashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving ephemeral dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+
+		dashboardJSON, err := json.Marshal(response)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling ephemeral dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(dashboardJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type CreateEphemeralDashboardInput struct {
+	Project   string `json:"project" jsonschema:"Project name to create the ephemeral dashboard in"`
+	Dashboard string `json:"dashboard" jsonschema:"Dashboard or EphemeralDashboard JSON as string"`
+	TTL       string `json:"ttl" jsonschema:"Time to live of the ephemeral dashboard, e.g. 1h or 7d"`
+}
+
+func (e *ephemeralDashboard) Create() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_create_ephemeral_dashboard",
+		Description: "Create an ephemeral dashboard in a specific project, deleted by Perses once its time to live has elapsed. " +
+			"Suited to scratch investigations that shouldn't clutter the project. The definition can be an EphemeralDashboard or a Dashboard",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"dashboard": {
+					Type:        "string",
+					Description: "Dashboard or EphemeralDashboard JSON as string",
+				},
+				"ttl": {
+					Type:        "string",
+					Description: "Time to live of the ephemeral dashboard, e.g. 1h or 7d",
+				},
+			},
+			Required: []string{"project", "dashboard", "ttl"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Creates an ephemeral dashboard in a specific project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateEphemeralDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		dashboardObj, err := decode(input.Dashboard)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := setTTL(dashboardObj, input.TTL); err != nil {
+			return nil, nil, err
+		}
+
+		createdDashboard, err := tools.Client(ctx, e.client).EphemeralDashboard(input.Project).Create(dashboardObj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating ephemeral dashboard in project '%s': %w", input.Project, err)
+		}
+
+		dashboardJSON, err := json.Marshal(createdDashboard)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling created ephemeral dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(dashboardJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type UpdateEphemeralDashboardInput struct {
+	Project   string `json:"project" jsonschema:"Project name to update the ephemeral dashboard in"`
+	Dashboard string `json:"dashboard" jsonschema:"EphemeralDashboard JSON as string"`
+	TTL       string `json:"ttl,omitempty" jsonschema:"New time to live of the ephemeral dashboard"`
+}
+
+func (e *ephemeralDashboard) Update() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_update_ephemeral_dashboard",
+		Description: "Update an existing ephemeral dashboard in a specific project. " +
+			"The time to live is the one given, else the one of the definition, else the current one",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"dashboard": {
+					Type:        "string",
+					Description: "EphemeralDashboard JSON as string",
+				},
+				"ttl": {
+					Type:        "string",
+					Description: "New time to live of the ephemeral dashboard, e.g. 1h or 7d",
+				},
+			},
+			Required: []string{"project", "dashboard"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Updates an ephemeral dashboard in a specific project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateEphemeralDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		dashboardObj, err := decode(input.Dashboard)
+		if err != nil {
+			return nil, nil, err
+		}
+		client := tools.Client(ctx, e.client).EphemeralDashboard(input.Project)
+		switch {
+		case input.TTL != "":
+			if err := setTTL(dashboardObj, input.TTL); err != nil {
+				return nil, nil, err
+			}
+		case dashboardObj.Spec.TTL == 0:
+			current, err := client.Get(dashboardObj.Metadata.Name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error retrieving ephemeral dashboard '%s' in project '%s': %w", dashboardObj.Metadata.Name, input.Project, err)
+			}
+			dashboardObj.Spec.TTL = current.Spec.TTL
+		}
+
+		updatedDashboard, err := client.Update(dashboardObj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error updating ephemeral dashboard in project '%s': %w", input.Project, err)
+		}
+
+		dashboardJSON, err := json.Marshal(updatedDashboard)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling updated ephemeral dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(dashboardJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type DeleteEphemeralDashboardInput struct {
+	Project string `json:"project" jsonschema:"Project name"`
+	Name    string `json:"name" jsonschema:"Ephemeral dashboard name to delete"`
+}
+
+func (e *ephemeralDashboard) Delete() *tools.Tool {
+	tool := &mcp.Tool{
+		Name:        "perses_delete_ephemeral_dashboard",
+		Description: "Delete an ephemeral dashboard from a specific project",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Ephemeral dashboard name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Deletes an ephemeral dashboard from a specific project in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteEphemeralDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, e.client).EphemeralDashboard(input.Project).Delete(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error deleting ephemeral dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: fmt.Sprintf("Ephemeral dashboard '%s' deleted successfully from project '%s'", input.Name, input.Project),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// decode reads the definition of an ephemeral dashboard. A Dashboard is turned into an EphemeralDashboard with the same metadata and spec.
+func decode(data string) (*v1.EphemeralDashboard, error) {
+	var kind struct {
+		Kind v1.Kind `json:"kind"`
+	}
+	if err := json.Unmarshal([]byte(data), &kind); err != nil {
+		return nil, fmt.Errorf("invalid dashboard JSON: %w", err)
+	}
+	if kind.Kind == v1.KindDashboard {
+		var dashboardObj v1.Dashboard
+		if err := json.Unmarshal([]byte(data), &dashboardObj); err != nil {
+			return nil, fmt.Errorf("invalid dashboard JSON: %w", err)
+		}
+		return &v1.EphemeralDashboard{
+			Kind:     v1.KindEphemeralDashboard,
+			Metadata: dashboardObj.Metadata,
+			Spec:     v1.EphemeralDashboardSpec{DashboardSpec: dashboardObj.Spec},
+		}, nil
+	}
+	var dashboardObj v1.EphemeralDashboard
+	if err := json.Unmarshal([]byte(data), &dashboardObj); err != nil {
+		return nil, fmt.Errorf("invalid ephemeral dashboard JSON: %w", err)
+	}
+	return &dashboardObj, nil
+}
+
+func setTTL(dashboardObj *v1.EphemeralDashboard, ttl string) error {
+	duration, err := common.ParseDuration(ttl)
+	if err != nil {
+		return fmt.Errorf("invalid ttl %q: %w", ttl, err)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("the ttl must be positive")
+	}
+	dashboardObj.Spec.TTL = duration
+	return nil
+}
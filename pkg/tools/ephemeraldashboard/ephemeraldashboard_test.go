@@ -0,0 +1,205 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ephemeraldashboard
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/persestest"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	debug    = `{"kind":"EphemeralDashboard","metadata":{"name":"debug","project":"shop"},"spec":{"ttl":"1d","display":{"name":"Debug"},"duration":"1h","panels":{},"layouts":[]}}`
+	overview = `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"display":{"name":"Overview"},"duration":"6h","panels":{},"layouts":[]}}`
+)
+
+// session registers the tools of the ephemeral dashboards for the fake Perses holding the given objects,
+// and returns a client session calling them.
+func session(t *testing.T, live ...string) (*mcp.ClientSession, *persestest.Store) {
+	store, client := persestest.New(t, live...)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	for _, tool := range New(client).GetTools() {
+		tool.RegisterWith(server)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+	return clientSession, store
+}
+
+// callTool calls the tool, and decodes its result into result. It returns the error message of the tool.
+func callTool(t *testing.T, session *mcp.ClientSession, name string, arguments map[string]any, result any) string {
+	response, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: arguments})
+	require.NoError(t, err)
+	require.Len(t, response.Content, 1)
+	text := response.Content[0].(*mcp.TextContent).Text
+	if response.IsError {
+		return text
+	}
+	require.NoError(t, json.Unmarshal([]byte(text), result))
+	return ""
+}
+
+func TestDecode(t *testing.T) {
+	testSuite := []struct {
+		title       string
+		definition  string
+		displayName string
+		ttl         time.Duration
+		wantErr     bool
+	}{
+		{
+			title:       "ephemeral dashboard",
+			definition:  debug,
+			displayName: "Debug",
+			ttl:         24 * time.Hour,
+		},
+		{
+			title:       "dashboard",
+			definition:  overview,
+			displayName: "Overview",
+		},
+		{
+			title:      "invalid JSON",
+			definition: `{"kind":`,
+			wantErr:    true,
+		},
+		{
+			title:      "invalid dashboard",
+			definition: `{"kind":"Dashboard","metadata":{"name":"overview"},"spec":{"duration":"1x"}}`,
+			wantErr:    true,
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			dashboardObj, err := decode(test.definition)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, v1.KindEphemeralDashboard, dashboardObj.Kind)
+			assert.Equal(t, "shop", dashboardObj.Metadata.Project)
+			assert.Equal(t, test.displayName, dashboardObj.Spec.Display.Name)
+			assert.Equal(t, common.Duration(test.ttl), dashboardObj.Spec.TTL)
+		})
+	}
+}
+
+func TestSetTTL(t *testing.T) {
+	testSuite := []struct {
+		ttl     string
+		result  time.Duration
+		wantErr bool
+	}{
+		{ttl: "2h", result: 2 * time.Hour},
+		{ttl: "7d", result: 7 * 24 * time.Hour},
+		{ttl: "1h30m", result: 90 * time.Minute},
+		{ttl: "0s", wantErr: true},
+		{ttl: "-1h", wantErr: true},
+		{ttl: "tomorrow", wantErr: true},
+		{ttl: "", wantErr: true},
+	}
+	for _, test := range testSuite {
+		t.Run(test.ttl, func(t *testing.T) {
+			dashboardObj := &v1.EphemeralDashboard{}
+			err := setTTL(dashboardObj, test.ttl)
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Zero(t, dashboardObj.Spec.TTL)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, common.Duration(test.result), dashboardObj.Spec.TTL)
+		})
+	}
+}
+
+func TestUpdateTTL(t *testing.T) {
+	const definitionWithTTL = `{"kind":"EphemeralDashboard","metadata":{"name":"debug","project":"shop"},"spec":{"ttl":"3h","duration":"2h","panels":{},"layouts":[]}}`
+	const definitionWithoutTTL = `{"kind":"EphemeralDashboard","metadata":{"name":"debug","project":"shop"},"spec":{"duration":"2h","panels":{},"layouts":[]}}`
+	testSuite := []struct {
+		title      string
+		definition string
+		ttl        string
+		result     time.Duration
+		err        string
+	}{
+		{
+			title:      "ttl of the input first",
+			definition: definitionWithTTL,
+			ttl:        "2h",
+			result:     2 * time.Hour,
+		},
+		{
+			title:      "ttl of the definition",
+			definition: definitionWithTTL,
+			result:     3 * time.Hour,
+		},
+		{
+			title:      "current ttl",
+			definition: definitionWithoutTTL,
+			result:     24 * time.Hour,
+		},
+		{
+			title:      "invalid ttl",
+			definition: definitionWithTTL,
+			ttl:        "0s",
+			err:        "the ttl must be positive",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			session, store := session(t, debug)
+			arguments := map[string]any{"project": "shop", "dashboard": test.definition}
+			if test.ttl != "" {
+				arguments["ttl"] = test.ttl
+			}
+			var updated v1.EphemeralDashboard
+			errMessage := callTool(t, session, "perses_update_ephemeral_dashboard", arguments, &updated)
+			if test.err != "" {
+				assert.Contains(t, errMessage, test.err)
+				assert.Empty(t, store.Writes())
+				return
+			}
+			require.Empty(t, errMessage)
+			assert.Equal(t, common.Duration(test.result), updated.Spec.TTL)
+			assert.Equal(t, "2h", string(updated.Spec.Duration), "the definition is applied")
+		})
+	}
+}
+
+func TestCreateFromDashboard(t *testing.T) {
+	session, store := session(t)
+	var created v1.EphemeralDashboard
+	errMessage := callTool(t, session, "perses_create_ephemeral_dashboard", map[string]any{"project": "shop", "dashboard": overview, "ttl": "12h"}, &created)
+	require.Empty(t, errMessage)
+	assert.Equal(t, v1.KindEphemeralDashboard, created.Kind)
+	assert.Equal(t, common.Duration(12*time.Hour), created.Spec.TTL)
+	assert.Equal(t, []string{"POST projects/shop/ephemeraldashboards/overview"}, store.Writes())
+}
@@ -0,0 +1,147 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ephemeraldashboard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type PromoteEphemeralDashboardInput struct {
+	Project       string `json:"project"`
+	Name          string `json:"name"`
+	TargetProject string `json:"target_project,omitempty"`
+	NewName       string `json:"new_name,omitempty"`
+	KeepEphemeral bool   `json:"keep_ephemeral,omitempty"`
+}
+
+type promotionResult struct {
+	Dashboard *v1.Dashboard `json:"dashboard"`
+	// EphemeralDeleted tells whether the ephemeral dashboard was deleted once promoted.
+	EphemeralDeleted bool `json:"ephemeral_deleted"`
+}
+
+func (e *ephemeralDashboard) Promote() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_promote_ephemeral_dashboard",
+		Description: "Turn an ephemeral dashboard into a permanent dashboard, e.g. once a scratch investigation proved useful. " +
+			"The dashboard is created with the spec of the ephemeral dashboard, under new_name and in target_project when set, " +
+			"and the ephemeral dashboard is then deleted unless keep_ephemeral is set. The tool fails if the dashboard already exists",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project of the ephemeral dashboard",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the ephemeral dashboard",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"target_project": {
+					Type:        "string",
+					Description: "Project to create the dashboard in (defaults to the project of the ephemeral dashboard)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"new_name": {
+					Type:        "string",
+					Description: "Name of the dashboard (defaults to the name of the ephemeral dashboard)",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"keep_ephemeral": {
+					Type:        "boolean",
+					Description: "Keep the ephemeral dashboard instead of deleting it once promoted",
+				},
+			},
+			Required: []string{"project", "name"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(false),
+			ReadOnlyHint:    false,
+			Title:           "Promotes an ephemeral dashboard to a permanent dashboard in Perses",
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input PromoteEphemeralDashboardInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		if !tools.ResourceAllowed(ctx, tools.DashboardResource) {
+			return nil, nil, fmt.Errorf("the ephemeral dashboard cannot be promoted: resource '%s' is not available", tools.DashboardResource)
+		}
+		client := tools.Client(ctx, e.client)
+		ephemeral, err := client.EphemeralDashboard(input.Project).Get(input.Name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error retrieving ephemeral dashboard '%s' in project '%s': %w", input.Name, input.Project, err)
+		}
+
+		dashboardObj := &v1.Dashboard{
+			Kind:     v1.KindDashboard,
+			Metadata: ephemeral.Metadata,
+			Spec:     ephemeral.Spec.DashboardSpec,
+		}
+		dashboardObj.Metadata.Version = 0
+		if input.NewName != "" {
+			dashboardObj.Metadata.Name = input.NewName
+		}
+		if input.TargetProject != "" {
+			dashboardObj.Metadata.Project = input.TargetProject
+		}
+		created, err := client.Dashboard(dashboardObj.Metadata.Project).Create(dashboardObj)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating dashboard '%s' in project '%s': %w", dashboardObj.Metadata.Name, dashboardObj.Metadata.Project, err)
+		}
+
+		result := promotionResult{Dashboard: created}
+		if !input.KeepEphemeral {
+			if err := client.EphemeralDashboard(input.Project).Delete(input.Name); err != nil {
+				return nil, nil, fmt.Errorf("dashboard '%s' created in project '%s', but error deleting ephemeral dashboard '%s': %w",
+					dashboardObj.Metadata.Name, dashboardObj.Metadata.Project, input.Name, err)
+			}
+			result.EphemeralDeleted = true
+		}
+
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling promoted dashboard: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.EphemeralDashboardResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ephemeraldashboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromote(t *testing.T) {
+	testSuite := []struct {
+		title     string
+		arguments map[string]any
+		dashboard string
+		deleted   bool
+		writes    []string
+	}{
+		{
+			title:     "ephemeral dashboard deleted",
+			arguments: map[string]any{},
+			dashboard: "projects/shop/dashboards/debug",
+			deleted:   true,
+			writes:    []string{"POST projects/shop/dashboards/debug", "DELETE projects/shop/ephemeraldashboards/debug"},
+		},
+		{
+			title:     "keep ephemeral",
+			arguments: map[string]any{"keep_ephemeral": true},
+			dashboard: "projects/shop/dashboards/debug",
+			writes:    []string{"POST projects/shop/dashboards/debug"},
+		},
+		{
+			title:     "new name in another project",
+			arguments: map[string]any{"target_project": "bank", "new_name": "investigation"},
+			dashboard: "projects/bank/dashboards/investigation",
+			deleted:   true,
+			writes:    []string{"POST projects/bank/dashboards/investigation", "DELETE projects/shop/ephemeraldashboards/debug"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			session, store := session(t, debug)
+			test.arguments["project"] = "shop"
+			test.arguments["name"] = "debug"
+			var result promotionResult
+			errMessage := callTool(t, session, "perses_promote_ephemeral_dashboard", test.arguments, &result)
+			require.Empty(t, errMessage)
+			assert.Equal(t, test.deleted, result.EphemeralDeleted)
+			assert.Equal(t, "Debug", result.Dashboard.Spec.Display.Name)
+			assert.Equal(t, test.writes, store.Writes())
+			_, ok := store.Get(test.dashboard)
+			assert.True(t, ok)
+			_, ok = store.Get("projects/shop/ephemeraldashboards/debug")
+			assert.Equal(t, !test.deleted, ok)
+		})
+	}
+}
+
+func TestPromoteTargetExists(t *testing.T) {
+	session, store := session(t, debug, `{"kind":"Dashboard","metadata":{"name":"debug","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`)
+	var result promotionResult
+	errMessage := callTool(t, session, "perses_promote_ephemeral_dashboard", map[string]any{"project": "shop", "name": "debug"}, &result)
+	assert.Contains(t, errMessage, "error creating dashboard 'debug' in project 'shop'")
+	assert.Empty(t, store.Writes(), "the ephemeral dashboard is kept")
+	_, ok := store.Get("projects/shop/ephemeraldashboards/debug")
+	assert.True(t, ok)
+}
@@ -46,6 +46,7 @@ func (g *globalDatasource) GetTools() []*tools.Tool {
 		g.Create(),
 		g.Update(),
 		g.Delete(),
+		g.FindUsages(),
 	}
 }
 
@@ -62,8 +63,8 @@ func (g *globalDatasource) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		globalDatasources, err := g.client.GlobalDatasource().List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		globalDatasources, err := tools.Client(ctx, g.client).GlobalDatasource().List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global datasources: %w", err)
 		}
@@ -119,8 +120,8 @@ func (g *globalDatasource) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetGlobalDatasourceByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		globalDatasource, err := g.client.GlobalDatasource().Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetGlobalDatasourceByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		globalDatasource, err := tools.Client(ctx, g.client).GlobalDatasource().Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global datasource '%s': %w", input.Name, err)
 		}
@@ -199,7 +200,7 @@ func (g *globalDatasource) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		// Parse the URL
 		parsedURL, err := common.ParseURL(input.URL)
 		if err != nil {
@@ -251,7 +252,7 @@ func (g *globalDatasource) Create() *tools.Tool {
 			},
 		}
 
-		response, err := g.client.GlobalDatasource().Create(newGlobalDatasource)
+		response, err := tools.Client(ctx, g.client).GlobalDatasource().Create(newGlobalDatasource)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating global datasource '%s': %w", input.Name, err)
 		}
@@ -330,7 +331,7 @@ func (g *globalDatasource) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		// Parse the URL
 		parsedURL, err := common.ParseURL(input.URL)
 		if err != nil {
@@ -381,7 +382,7 @@ func (g *globalDatasource) Update() *tools.Tool {
 			},
 		}
 
-		response, err := g.client.GlobalDatasource().Update(updatedGlobalDatasource)
+		response, err := tools.Client(ctx, g.client).GlobalDatasource().Update(updatedGlobalDatasource)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating global datasource '%s': %w", input.Name, err)
 		}
@@ -437,8 +438,8 @@ func (g *globalDatasource) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := g.client.GlobalDatasource().Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteGlobalDatasourceInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, g.client).GlobalDatasource().Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting global datasource '%s': %w", input.Name, err)
 		}
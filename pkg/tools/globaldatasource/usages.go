@@ -0,0 +1,118 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package globaldatasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/references"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type FindDatasourceUsagesInput struct {
+	Name    string `json:"name"`
+	Project string `json:"project,omitempty"`
+}
+
+func (g *globalDatasource) FindUsages() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_find_datasource_usages",
+		Description: "Find everything that depends on a datasource before changing or deleting it: " +
+			"the panel queries of the dashboards and the project and global variables that get their data from it, " +
+			"either by naming it or by falling back to it as the default datasource of its kind. " +
+			"Also reports the project and dashboard datasources with the same name that take precedence over a global datasource",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Finds the usages of a datasource in Perses",
+			ReadOnlyHint:    true,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Name of the datasource",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Project of the datasource. When empty, the datasource is a global datasource and every project is scanned",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input FindDatasourceUsagesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		client := tools.Client(ctx, g.client)
+		target := references.Datasource{Scope: references.GlobalScope, Name: input.Name}
+		var projects []string
+		if input.Project != "" {
+			datasource, err := client.Datasource(input.Project).Get(input.Name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error retrieving datasource '%s' in project '%s': %w", input.Name, input.Project, err)
+			}
+			target.Scope = references.ProjectScope
+			target.Project = input.Project
+			target.Kind = datasource.Spec.Plugin.Kind
+			target.Default = datasource.Spec.Default
+			projects = []string{input.Project}
+		} else {
+			datasource, err := client.GlobalDatasource().Get(input.Name)
+			if err != nil {
+				return nil, nil, fmt.Errorf("error retrieving global datasource '%s': %w", input.Name, err)
+			}
+			target.Kind = datasource.Spec.Plugin.Kind
+			target.Default = datasource.Spec.Default
+		}
+
+		// The search must not reveal the objects of the resources that are disabled on the MCP server.
+		inventory, err := references.Load(client, projects, func(kind v1.Kind) bool {
+			return tools.ResourceAllowed(ctx, tools.ResourceFromKind(string(kind)))
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error finding the usages of datasource '%s': %w", input.Name, err)
+		}
+		report := references.DatasourceUsages(inventory, target)
+		text, err := json.Marshal(report)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling usages of datasource '%s': %w", input.Name, err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.GlobalDatasourceResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
@@ -60,8 +60,8 @@ func (g *globalRole) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		globalRoles, err := g.client.GlobalRole().List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		globalRoles, err := tools.Client(ctx, g.client).GlobalRole().List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global roles: %w", err)
 		}
@@ -117,8 +117,8 @@ func (g *globalRole) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetGlobalRoleByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		globalRole, err := g.client.GlobalRole().Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetGlobalRoleByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		globalRole, err := tools.Client(ctx, g.client).GlobalRole().Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global role '%s': %w", input.Name, err)
 		}
@@ -190,7 +190,7 @@ func (g *globalRole) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		actions := make([]role.Action, len(input.Actions))
 		for i, a := range input.Actions {
 			actions[i] = role.Action(a)
@@ -215,7 +215,7 @@ func (g *globalRole) Create() *tools.Tool {
 			},
 		}
 
-		result, err := g.client.GlobalRole().Create(globalRoleObj)
+		result, err := tools.Client(ctx, g.client).GlobalRole().Create(globalRoleObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating global role '%s': %w", input.Name, err)
 		}
@@ -287,7 +287,7 @@ func (g *globalRole) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		actions := make([]role.Action, len(input.Actions))
 		for i, a := range input.Actions {
 			actions[i] = role.Action(a)
@@ -312,7 +312,7 @@ func (g *globalRole) Update() *tools.Tool {
 			},
 		}
 
-		result, err := g.client.GlobalRole().Update(globalRoleObj)
+		result, err := tools.Client(ctx, g.client).GlobalRole().Update(globalRoleObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating global role '%s': %w", input.Name, err)
 		}
@@ -368,8 +368,8 @@ func (g *globalRole) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := g.client.GlobalRole().Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteGlobalRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, g.client).GlobalRole().Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting global role '%s': %w", input.Name, err)
 		}
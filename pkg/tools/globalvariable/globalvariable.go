@@ -60,8 +60,8 @@ func (g *globalVariable) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		variables, err := g.client.GlobalVariable().List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		variables, err := tools.Client(ctx, g.client).GlobalVariable().List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global variables: %w", err)
 		}
@@ -117,8 +117,8 @@ func (g *globalVariable) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetGlobalVariableByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		globalVariable, err := g.client.GlobalVariable().Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetGlobalVariableByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		globalVariable, err := tools.Client(ctx, g.client).GlobalVariable().Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving global variable '%s': %w", input.Name, err)
 		}
@@ -179,7 +179,7 @@ func (g *globalVariable) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		globalVar := &v1.GlobalVariable{
 			Kind: v1.KindGlobalVariable,
 			Metadata: v1.Metadata{
@@ -193,7 +193,7 @@ func (g *globalVariable) Create() *tools.Tool {
 			},
 		}
 
-		result, err := g.client.GlobalVariable().Create(globalVar)
+		result, err := tools.Client(ctx, g.client).GlobalVariable().Create(globalVar)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating global variable '%s': %w", input.Name, err)
 		}
@@ -254,7 +254,7 @@ func (g *globalVariable) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		globalVar := &v1.GlobalVariable{
 			Kind: v1.KindGlobalVariable,
 			Metadata: v1.Metadata{
@@ -268,7 +268,7 @@ func (g *globalVariable) Update() *tools.Tool {
 			},
 		}
 
-		result, err := g.client.GlobalVariable().Update(globalVar)
+		result, err := tools.Client(ctx, g.client).GlobalVariable().Update(globalVar)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating global variable '%s': %w", input.Name, err)
 		}
@@ -324,8 +324,8 @@ func (g *globalVariable) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := g.client.GlobalVariable().Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteGlobalVariableInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, g.client).GlobalVariable().Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting global variable '%s': %w", input.Name, err)
 		}
@@ -0,0 +1,103 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/perses/mcp-server/pkg/tools/resource"
+)
+
+type instance struct {
+	registry *Registry
+}
+
+func New(registry *Registry) resource.Resource {
+	return &instance{
+		registry: registry,
+	}
+}
+
+func (i *instance) GetTools() []*tools.Tool {
+	result := []*tools.Tool{
+		i.List(),
+	}
+	// The promotion needs a source and a target instance.
+	if len(i.registry.List()) > 1 {
+		result = append(result, i.Promote())
+	}
+	return result
+}
+
+type ListInstancesInput struct{}
+
+func (i *instance) List() *tools.Tool {
+	tool := &mcp.Tool{
+		Name:        "perses_list_instances",
+		Description: "List the Perses instances available through this MCP server. Pass the name of an instance in the 'instance' argument of the other tools to target it.",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Lists all Perses instances",
+			ReadOnlyHint:    true,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest, _ ListInstancesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		instancesJSON, err := json.Marshal(i.registry.List())
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling instances: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(instancesJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.InstanceResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// Get is not applicable to instances, which are defined in the configuration
+func (i *instance) Get() *tools.Tool {
+	return nil
+}
+
+// Create is not applicable to instances, which are defined in the configuration
+func (i *instance) Create() *tools.Tool {
+	return nil
+}
+
+// Update is not applicable to instances, which are defined in the configuration
+func (i *instance) Update() *tools.Tool {
+	return nil
+}
+
+// Delete is not applicable to instances, which are defined in the configuration
+func (i *instance) Delete() *tools.Tool {
+	return nil
+}
@@ -0,0 +1,420 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/diff"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// promotableKinds lists the kinds that can be promoted, in the order they are applied:
+// the datasources and the variables must exist before the dashboards using them.
+var promotableKinds = []v1.Kind{v1.KindDatasource, v1.KindVariable, v1.KindDashboard}
+
+const (
+	actionCreate    = "create"
+	actionUpdate    = "update"
+	actionUnchanged = "unchanged"
+)
+
+type PromoteInput struct {
+	SourceInstance    string            `json:"source_instance"`
+	TargetInstance    string            `json:"target_instance"`
+	Project           string            `json:"project"`
+	TargetProject     string            `json:"target_project,omitempty"`
+	Kinds             []string          `json:"kinds,omitempty"`
+	Names             []string          `json:"names,omitempty"`
+	DatasourceMapping map[string]string `json:"datasource_mapping,omitempty"`
+	Confirm           bool              `json:"confirm,omitempty"`
+	PlanHash          string            `json:"plan_hash,omitempty"`
+}
+
+type promotionStep struct {
+	Kind v1.Kind `json:"kind"`
+	Name string  `json:"name"`
+	// SourceName is set when the object is renamed by the datasource mapping.
+	SourceName string        `json:"source_name,omitempty"`
+	Action     string        `json:"action"`
+	Changes    []diff.Change `json:"changes,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	// Skipped is set on the steps left out because a previous step failed.
+	Skipped bool `json:"skipped,omitempty"`
+	object  modelAPI.Entity
+}
+
+type promotionPlan struct {
+	SourceInstance string `json:"source_instance"`
+	TargetInstance string `json:"target_instance"`
+	Project        string `json:"project"`
+	TargetProject  string `json:"target_project"`
+	// PlanHash identifies the plan, including the content of the objects to write. It must be given back to apply the plan.
+	PlanHash string `json:"plan_hash"`
+	// Applied is set when every step of the plan has been applied.
+	Applied bool             `json:"applied"`
+	Steps   []*promotionStep `json:"steps"`
+}
+
+func (i *instance) Promote() *tools.Tool {
+	kindNames := make([]any, 0, len(promotableKinds))
+	for _, kind := range promotableKinds {
+		kindNames = append(kindNames, string(kind))
+	}
+	tool := &mcp.Tool{
+		Name: "perses_promote",
+		Description: "Promote the dashboards, variables and datasources of a project from one Perses instance to another (e.g. from staging to production). " +
+			"The project name and the datasource references are rewritten for the target. " +
+			"Without confirm, nothing is modified: the tool returns the plan, the diff of every object and the plan_hash of the plan. " +
+			"Call it again with confirm=true and the plan_hash to apply the plan; the call fails if the plan changed in the meantime. " +
+			"The steps are applied in order and the promotion stops at the first failed step.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"source_instance": {
+					Type:        "string",
+					Description: "Instance to copy the objects from",
+				},
+				"target_instance": {
+					Type:        "string",
+					Description: "Instance to copy the objects to",
+				},
+				"project": {
+					Type:        "string",
+					Description: "Project of the source instance",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"target_project": {
+					Type:        "string",
+					Description: "Project of the target instance (defaults to the source project). It is created if it doesn't exist",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+				"kinds": {
+					Type:        "array",
+					Description: "Kinds of objects to promote (defaults to all of them)",
+					Items: &jsonschema.Schema{
+						Type: "string",
+						Enum: kindNames,
+					},
+				},
+				"names": {
+					Type:        "array",
+					Description: "Names of the objects to promote (defaults to every object of the selected kinds)",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"datasource_mapping": {
+					Type:        "object",
+					Description: "Datasources to rename in the target, as a map of source name to target name. The datasource references of the dashboards and variables are rewritten accordingly",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+				"confirm": {
+					Type:        "boolean",
+					Description: "Apply the plan. When false, the plan is only returned",
+				},
+				"plan_hash": {
+					Type:        "string",
+					Description: "plan_hash of the reviewed plan, required with confirm",
+				},
+			},
+			Required: []string{"source_instance", "target_instance", "project"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Promotes objects from one Perses instance to another",
+			ReadOnlyHint:    false,
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(_ context.Context, _ *mcp.CallToolRequest, input PromoteInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		plan, err := i.planPromotion(input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error planning the promotion of project '%s': %w", input.Project, err)
+		}
+		if input.Confirm {
+			if input.PlanHash == "" {
+				return nil, nil, fmt.Errorf("plan_hash is required with confirm: review the plan first")
+			}
+			if input.PlanHash != plan.PlanHash {
+				return nil, nil, fmt.Errorf("the plan changed since plan_hash '%s' was computed, review the new plan (plan_hash '%s') before confirming it", input.PlanHash, plan.PlanHash)
+			}
+			if err := i.applyPromotion(plan); err != nil {
+				return nil, nil, fmt.Errorf("error promoting project '%s': %w", input.Project, err)
+			}
+		}
+		planJSON, err := json.Marshal(plan)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling promotion plan: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(planJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.InstanceResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+func (i *instance) planPromotion(input PromoteInput) (*promotionPlan, error) {
+	source, err := i.registry.Get(input.SourceInstance)
+	if err != nil {
+		return nil, err
+	}
+	target, err := i.registry.Get(input.TargetInstance)
+	if err != nil {
+		return nil, err
+	}
+	if source.Name == target.Name {
+		return nil, fmt.Errorf("source and target instances must be different")
+	}
+	kinds, err := parsePromotableKinds(input.Kinds)
+	if err != nil {
+		return nil, err
+	}
+	targetProject := input.TargetProject
+	if targetProject == "" {
+		targetProject = input.Project
+	}
+
+	plan := &promotionPlan{
+		SourceInstance: source.Name,
+		TargetInstance: target.Name,
+		Project:        input.Project,
+		TargetProject:  targetProject,
+	}
+	projectStep, err := planProject(source, target, input.Project, targetProject)
+	if err != nil {
+		return nil, err
+	}
+	if projectStep != nil {
+		plan.Steps = append(plan.Steps, projectStep)
+	}
+
+	found := make(map[string]bool)
+	for _, kind := range kinds {
+		sourceObjects, err := listObjects(source, kind, input.Project)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range sourceObjects {
+			if len(input.Names) > 0 && !slices.Contains(input.Names, objects.Name(obj)) {
+				continue
+			}
+			found[objects.Name(obj)] = true
+			step, err := planObject(target, obj, targetProject, input.DatasourceMapping)
+			if err != nil {
+				return nil, err
+			}
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+	var missing []string
+	for _, name := range input.Names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no object named %s in project '%s' of instance '%s'", strings.Join(missing, ", "), input.Project, source.Name)
+	}
+	plan.PlanHash, err = hashPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// hashPlan returns the hash of the plan, of its steps and of the objects they write,
+// so that a plan is only applied as it was reviewed.
+func hashPlan(plan *promotionPlan) (string, error) {
+	type hashedStep struct {
+		*promotionStep
+		Object modelAPI.Entity `json:"object"`
+	}
+	steps := make([]hashedStep, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		steps = append(steps, hashedStep{promotionStep: step, Object: step.object})
+	}
+	data, err := json.Marshal(struct {
+		*promotionPlan
+		Steps []hashedStep `json:"steps"`
+	}{promotionPlan: plan, Steps: steps})
+	if err != nil {
+		return "", fmt.Errorf("error hashing the promotion plan: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func parsePromotableKinds(names []string) ([]v1.Kind, error) {
+	if len(names) == 0 {
+		return promotableKinds, nil
+	}
+	var selected []v1.Kind
+	for _, name := range names {
+		kind, err := v1.GetKind(name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(promotableKinds, *kind) {
+			return nil, fmt.Errorf("kind %s cannot be promoted", *kind)
+		}
+		selected = append(selected, *kind)
+	}
+	// Keep the dependency order whatever the order of the input.
+	return slices.DeleteFunc(slices.Clone(promotableKinds), func(kind v1.Kind) bool {
+		return !slices.Contains(selected, kind)
+	}), nil
+}
+
+func listObjects(i *Instance, kind v1.Kind, project string) ([]modelAPI.Entity, error) {
+	accessor, err := objects.For(i.Client, kind, project)
+	if err != nil {
+		return nil, err
+	}
+	list, err := accessor.List()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the %s objects of project '%s' on instance '%s': %w", kind, project, i.Name, err)
+	}
+	return list, nil
+}
+
+// planProject returns the step creating the target project, or nil when it already exists.
+func planProject(source *Instance, target *Instance, project string, targetProject string) (*promotionStep, error) {
+	_, err := target.Client.Project().Get(targetProject)
+	if err == nil {
+		return nil, nil
+	}
+	if !objects.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get project '%s' on instance '%s': %w", targetProject, target.Name, err)
+	}
+	sourceProject, err := source.Client.Project().Get(project)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project '%s' on instance '%s': %w", project, source.Name, err)
+	}
+	newProject := &v1.Project{
+		Kind:     v1.KindProject,
+		Metadata: v1.Metadata{Name: targetProject, Tags: sourceProject.Metadata.Tags},
+		Spec:     sourceProject.Spec,
+	}
+	return &promotionStep{Kind: v1.KindProject, Name: targetProject, Action: actionCreate, object: newProject}, nil
+}
+
+func planObject(target *Instance, obj modelAPI.Entity, targetProject string, datasourceMapping map[string]string) (*promotionStep, error) {
+	desired, err := objects.RenameDatasourceReferences(obj, datasourceMapping)
+	if err != nil {
+		return nil, fmt.Errorf("unable to rewrite %s: %w", objects.String(obj), err)
+	}
+	objects.SetProject(desired, targetProject)
+	step := &promotionStep{Kind: objects.Kind(obj), Name: objects.Name(obj), object: desired}
+	if newName, ok := datasourceMapping[step.Name]; ok && step.Kind == v1.KindDatasource {
+		objects.SetName(desired, newName)
+		step.SourceName = step.Name
+		step.Name = newName
+	}
+
+	accessor, err := objects.ForObject(target.Client, desired)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := accessor.Get(step.Name)
+	if objects.IsNotFound(err) {
+		step.Action = actionCreate
+		return step, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s on instance '%s': %w", objects.String(desired), target.Name, err)
+	}
+	before, err := objects.Normalize(existing)
+	if err != nil {
+		return nil, err
+	}
+	after, err := objects.Normalize(desired)
+	if err != nil {
+		return nil, err
+	}
+	step.Changes = diff.Compare(before, after)
+	step.Action = actionUpdate
+	if len(step.Changes) == 0 {
+		step.Action = actionUnchanged
+	}
+	return step, nil
+}
+
+func (i *instance) applyPromotion(plan *promotionPlan) error {
+	target, err := i.registry.Get(plan.TargetInstance)
+	if err != nil {
+		return err
+	}
+	if target.ReadOnly {
+		return fmt.Errorf("instance '%s' is read-only", target.Name)
+	}
+	for _, step := range plan.Steps {
+		if resource := tools.ResourceFromKind(string(step.Kind)); !target.AllowsResource(resource) {
+			return fmt.Errorf("the %s objects cannot be modified on instance '%s'", step.Kind, target.Name)
+		}
+	}
+	// The steps are ordered by dependency (e.g. the datasources before the dashboards using them),
+	// so the promotion stops at the first failure rather than applying steps whose dependencies are missing.
+	for n, step := range plan.Steps {
+		if step.Action == actionUnchanged {
+			continue
+		}
+		accessor, err := objects.ForObject(target.Client, step.object)
+		if err != nil {
+			return err
+		}
+		if step.Action == actionCreate {
+			_, err = accessor.Create(step.object)
+		} else {
+			_, err = accessor.Update(step.object)
+		}
+		if err != nil {
+			step.Error = err.Error()
+			for _, skipped := range plan.Steps[n+1:] {
+				skipped.Skipped = skipped.Action != actionUnchanged
+			}
+			return nil
+		}
+	}
+	plan.Applied = true
+	return nil
+}
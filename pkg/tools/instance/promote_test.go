@@ -0,0 +1,182 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/persestest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	shop       = `{"kind":"Project","metadata":{"name":"shop"},"spec":{}}`
+	prometheus = `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"directUrl":"http://prometheus:9090"}}}}`
+	job        = `{"kind":"Variable","metadata":{"name":"job","project":"shop"},"spec":{"kind":"TextVariable","spec":{"value":"api"}}}`
+	overview   = `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`
+)
+
+// promoteSession registers the tools of the instances staging and production, holding the given objects,
+// and returns a client session calling them with the fake Perses of each instance.
+func promoteSession(t *testing.T, staging []string, production []string) (*mcp.ClientSession, *persestest.Store, *persestest.Store) {
+	stagingStore, stagingClient := persestest.New(t, staging...)
+	productionStore, productionClient := persestest.New(t, production...)
+	registry := NewRegistry([]*Instance{
+		{Name: "staging", Client: stagingClient},
+		{Name: "production", Client: productionClient},
+	}, "staging")
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	for _, tool := range New(registry).GetTools() {
+		tool.RegisterWith(server)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session, stagingStore, productionStore
+}
+
+// promote calls perses_promote, and returns the plan or the error message of the tool.
+func promote(t *testing.T, session *mcp.ClientSession, arguments map[string]any) (*promotionPlan, string) {
+	arguments["source_instance"] = "staging"
+	arguments["target_instance"] = "production"
+	arguments["project"] = "shop"
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "perses_promote", Arguments: arguments})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError {
+		return nil, text
+	}
+	var plan promotionPlan
+	require.NoError(t, json.Unmarshal([]byte(text), &plan))
+	return &plan, ""
+}
+
+// stepActions returns the action of every step of the plan, e.g. "create Datasource/prometheus".
+func stepActions(plan *promotionPlan) []string {
+	result := make([]string, 0, len(plan.Steps))
+	for _, step := range plan.Steps {
+		action := step.Action
+		switch {
+		case step.Error != "":
+			action = "failed " + action
+		case step.Skipped:
+			action = "skipped " + action
+		}
+		result = append(result, action+" "+string(step.Kind)+"/"+step.Name)
+	}
+	return result
+}
+
+func TestPromotePlanThenConfirm(t *testing.T) {
+	session, _, production := promoteSession(t, []string{shop, prometheus, job, overview}, []string{shop, job})
+
+	plan, errMessage := promote(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+	assert.False(t, plan.Applied)
+	assert.NotEmpty(t, plan.PlanHash)
+	assert.Equal(t, []string{"create Datasource/prometheus", "unchanged Variable/job", "create Dashboard/overview"}, stepActions(plan))
+	assert.Empty(t, production.Writes(), "nothing is written without confirm")
+
+	again, errMessage := promote(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+	assert.Equal(t, plan.PlanHash, again.PlanHash, "the hash of a plan is stable")
+
+	applied, errMessage := promote(t, session, map[string]any{"confirm": true, "plan_hash": plan.PlanHash})
+	require.Empty(t, errMessage)
+	assert.True(t, applied.Applied)
+	assert.Equal(t, []string{"POST projects/shop/datasources/prometheus", "POST projects/shop/dashboards/overview"}, production.Writes())
+}
+
+func TestPromoteRejectsInvalidPlanHash(t *testing.T) {
+	testSuite := []struct {
+		title string
+		// planArguments are the arguments of the reviewed plan.
+		planArguments map[string]any
+		// change modifies the source after the review of the plan.
+		change   func(t *testing.T, staging *persestest.Store)
+		planHash func(plan *promotionPlan) string
+		err      string
+	}{
+		{
+			title:    "missing plan hash",
+			planHash: func(*promotionPlan) string { return "" },
+			err:      "plan_hash is required with confirm",
+		},
+		{
+			title:    "mismatched plan hash",
+			planHash: func(*promotionPlan) string { return "0123456789abcdef" },
+			err:      "the plan changed since plan_hash '0123456789abcdef' was computed",
+		},
+		{
+			title:         "plan hash of another plan",
+			planArguments: map[string]any{"datasource_mapping": map[string]any{"prometheus": "thanos"}},
+			planHash:      func(plan *promotionPlan) string { return plan.PlanHash },
+			err:           "the plan changed",
+		},
+		{
+			title: "stale plan hash",
+			change: func(t *testing.T, staging *persestest.Store) {
+				staging.Put(t, `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"duration":"6h","panels":{},"layouts":[]}}`)
+			},
+			planHash: func(plan *promotionPlan) string { return plan.PlanHash },
+			err:      "the plan changed",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			session, staging, production := promoteSession(t, []string{shop, prometheus, overview}, []string{shop})
+			planArguments := map[string]any{}
+			for key, value := range test.planArguments {
+				planArguments[key] = value
+			}
+			plan, errMessage := promote(t, session, planArguments)
+			require.Empty(t, errMessage)
+			if test.change != nil {
+				test.change(t, staging)
+			}
+			arguments := map[string]any{"confirm": true}
+			if hash := test.planHash(plan); hash != "" {
+				arguments["plan_hash"] = hash
+			}
+			_, errMessage = promote(t, session, arguments)
+			assert.Contains(t, errMessage, test.err)
+			assert.Empty(t, production.Writes())
+		})
+	}
+}
+
+func TestPromoteStopsAtFirstFailure(t *testing.T) {
+	session, _, production := promoteSession(t, []string{shop, prometheus, job, overview}, []string{shop})
+	production.Fail("POST projects/shop/variables/job", http.StatusInternalServerError)
+
+	plan, errMessage := promote(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+	applied, errMessage := promote(t, session, map[string]any{"confirm": true, "plan_hash": plan.PlanHash})
+	require.Empty(t, errMessage)
+	assert.False(t, applied.Applied)
+	assert.Equal(t, []string{"create Datasource/prometheus", "failed create Variable/job", "skipped create Dashboard/overview"}, stepActions(applied))
+	assert.Equal(t, []string{"POST projects/shop/datasources/prometheus"}, production.Writes())
+}
@@ -0,0 +1,100 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package instance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/perses/mcp-server/pkg/tools"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+)
+
+// Instance is a Perses backend the MCP server can talk to.
+type Instance struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	ReadOnly bool   `json:"read_only"`
+	// Resources lists the resources whose tools are available on this instance. Empty means every resource.
+	Resources []string                  `json:"resources,omitempty"`
+	Primary   bool                      `json:"primary"`
+	Client    apiClient.ClientInterface `json:"-"`
+}
+
+// Allows returns true when the tool can be called on this instance, according to its read-only mode and resource filter.
+func (i *Instance) Allows(tool *tools.Tool) bool {
+	if i.ReadOnly && tool.IsWriteTool {
+		return false
+	}
+	return i.AllowsResource(tool.ResourceType)
+}
+
+// AllowsResource returns true when the tools of the given resource are available on this instance.
+func (i *Instance) AllowsResource(resource tools.Resource) bool {
+	return len(i.Resources) == 0 || resource == tools.MultiResource || slices.Contains(i.Resources, string(resource))
+}
+
+// Registry holds the Perses instances configured in the MCP server.
+type Registry struct {
+	instances []*Instance
+	primary   *Instance
+}
+
+// NewRegistry creates a registry from the given instances. The primary instance is the one
+// used when a tool call doesn't name any instance. It defaults to the first instance.
+func NewRegistry(instances []*Instance, primary string) *Registry {
+	r := &Registry{instances: instances}
+	for _, i := range instances {
+		if r.primary == nil && (primary == "" || i.Name == primary) {
+			r.primary = i
+		}
+	}
+	if r.primary != nil {
+		r.primary.Primary = true
+	}
+	return r
+}
+
+func (r *Registry) Primary() *Instance {
+	return r.primary
+}
+
+func (r *Registry) List() []*Instance {
+	return r.instances
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.instances))
+	for _, i := range r.instances {
+		names = append(names, i.Name)
+	}
+	return names
+}
+
+// Get returns the instance with the given name, or the primary instance when name is empty.
+func (r *Registry) Get(name string) (*Instance, error) {
+	if name == "" {
+		if r.primary == nil {
+			return nil, fmt.Errorf("no Perses instance configured")
+		}
+		return r.primary, nil
+	}
+	for _, i := range r.instances {
+		if i.Name == name {
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown Perses instance '%s', available instances are: %s", name, strings.Join(r.Names(), ", "))
+}
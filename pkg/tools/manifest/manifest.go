@@ -0,0 +1,245 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifest provides the tools working on sets of Perses object definitions,
+// such as the files of a GitOps repository.
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	"github.com/perses/mcp-server/pkg/tools/resource"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+)
+
+type manifest struct {
+	client apiClient.ClientInterface
+	// directory is the directory in which the tools can read the object definitions. Empty disables the reading of files.
+	directory string
+}
+
+func New(client apiClient.ClientInterface, directory string) resource.Resource {
+	return &manifest{
+		client:    client,
+		directory: directory,
+	}
+}
+
+func (m *manifest) GetTools() []*tools.Tool {
+	return []*tools.Tool{
+		m.Apply(),
+		m.Diff(),
+	}
+}
+
+// load reads the objects from the content, or from the given path of the manifests directory,
+// and verifies that the current call is allowed to use each of them.
+func (m *manifest) load(ctx context.Context, content string, path string) ([]modelAPI.Entity, error) {
+	var list []modelAPI.Entity
+	var err error
+	switch {
+	case content != "" && path != "":
+		return nil, fmt.Errorf("only one of content and directory can be provided")
+	case path != "":
+		if m.directory == "" {
+			return nil, fmt.Errorf("reading the objects from a directory is disabled: manifests_directory is not configured")
+		}
+		// Cleaning the path as an absolute one prevents escaping the manifests directory with "..".
+		list, err = gitops.Load(filepath.Join(m.directory, filepath.Clean("/"+path)))
+	default:
+		list, err = gitops.Decode([]byte(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid objects: %w", err)
+	}
+	for _, obj := range list {
+		r := tools.ResourceFromKind(obj.GetKind())
+		if !slices.Contains(tools.ValidResources, r) || !tools.ResourceAllowed(ctx, r) {
+			return nil, fmt.Errorf("%s is not supported: resource '%s' is not available", objects.String(obj), r)
+		}
+	}
+	return list, nil
+}
+
+type ApplyInput struct {
+	Content     string `json:"content"`
+	Prune       bool   `json:"prune,omitempty"`
+	PruneGlobal bool   `json:"prune_global,omitempty"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+}
+
+func (m *manifest) Apply() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_apply",
+		Description: "Apply a set of Perses objects of any kind (projects, dashboards, datasources, variables, roles, role bindings and their global counterparts), " +
+			"like `percli apply`. Each object is created or updated as needed, in dependency order. " +
+			"With prune, the objects of the same kinds and projects that are not in the set are deleted, and with prune_global the global objects of the same kinds as well. " +
+			"Returns the result for every object.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"content": {
+					Type:        "string",
+					Description: "YAML or JSON definition of the objects: a single object, a list of objects, or several YAML documents separated by ---",
+				},
+				"prune": {
+					Type:        "boolean",
+					Description: "Delete the objects that are not in the set, within the kinds and projects present in the set. Projects are never deleted",
+				},
+				"prune_global": {
+					Type:        "boolean",
+					Description: "With prune, also delete the global objects (e.g. global datasources) of the kinds present in the set that are not in the set",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report what would be done, without modifying anything",
+				},
+			},
+			Required: []string{"content"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Applies a set of Perses objects",
+			ReadOnlyHint:    false,
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ApplyInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		list, err := m.load(ctx, input.Content, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		results, err := gitops.Apply(tools.Client(ctx, m.client), list, gitops.ApplyOptions{Prune: input.Prune, PruneGlobal: input.PruneGlobal, DryRun: input.DryRun})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error applying objects: %w", err)
+		}
+		resultsJSON, err := json.Marshal(results)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling apply results: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultsJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.MultiResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+type DiffInput struct {
+	Content   string `json:"content,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+func (m *manifest) Diff() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_diff",
+		Description: "Compare a set of Perses objects with the live objects, to detect drift before applying them. " +
+			"The metadata managed by Perses is ignored. For every object, returns its status (only_local, only_live, changed or unchanged) " +
+			"and a semantic diff: panels added or removed, queries changed, variables changed, datasource URLs changed, and other field changes.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"content": {
+					Type:        "string",
+					Description: "YAML or JSON definition of the objects: a single object, a list of objects, or several YAML documents separated by ---",
+				},
+				"directory": {
+					Type:        "string",
+					Description: "Exported directory (or file) to compare, relative to the manifests directory configured in the MCP server. Used instead of content",
+				},
+			},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Compares a set of objects with the live Perses objects",
+			ReadOnlyHint:    true,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DiffInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		list, err := m.load(ctx, input.Content, input.Directory)
+		if err != nil {
+			return nil, nil, err
+		}
+		diffs, err := gitops.Diff(tools.Client(ctx, m.client), list)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error comparing objects: %w", err)
+		}
+		diffsJSON, err := json.Marshal(diffs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling diff: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(diffsJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.MultiResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// Get is not applicable to manifests
+func (m *manifest) Get() *tools.Tool {
+	return nil
+}
+
+// List is not applicable to manifests
+func (m *manifest) List() *tools.Tool {
+	return nil
+}
+
+// Create is not applicable to manifests
+func (m *manifest) Create() *tools.Tool {
+	return nil
+}
+
+// Update is not applicable to manifests
+func (m *manifest) Update() *tools.Tool {
+	return nil
+}
+
+// Delete is not applicable to manifests
+func (m *manifest) Delete() *tools.Tool {
+	return nil
+}
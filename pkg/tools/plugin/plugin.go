@@ -54,8 +54,8 @@ func (p *plugin) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		plugins, err := p.client.Plugin().List()
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input map[string]any) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		plugins, err := tools.Client(ctx, p.client).Plugin().List()
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving plugins: %w", err)
 		}
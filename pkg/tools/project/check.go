@@ -0,0 +1,101 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/references"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type CheckReferencesInput struct {
+	Project string `json:"project,omitempty" jsonschema:"Project to check (defaults to every project)"`
+}
+
+type checkReferencesResult struct {
+	Issues []references.Issue `json:"issues"`
+	// SkippedKinds lists the kinds that were not checked, with the reason.
+	SkippedKinds []references.SkippedKind `json:"skipped_kinds,omitempty"`
+}
+
+func (p *project) CheckReferences() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_check_references",
+		Description: "Check the references between the dashboards, variables, datasources and role bindings of one project or of every project. " +
+			"Reports the datasource selectors pointing at missing datasources, the queries without datasource selector having no default datasource, " +
+			"the default datasources of a kind conflicting with another one, the $variable usages with no definition, the unused variables, " +
+			"the datasources using a missing secret and the role bindings pointing at missing roles. " +
+			"The kinds that are not available or that can't be listed are reported as skipped_kinds, and the checks depending on them are not done",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Checks for broken references in Perses",
+			ReadOnlyHint:    true,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project to check. When empty, every project and the global objects are checked",
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]*$",
+				},
+			},
+		},
+	}
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CheckReferencesInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		var projects []string
+		if input.Project != "" {
+			projects = []string{input.Project}
+		}
+		// The check must not reveal the objects of the resources that are disabled on the MCP server.
+		inventory, err := references.Load(tools.Client(ctx, p.client), projects, func(kind v1.Kind) bool {
+			return tools.ResourceAllowed(ctx, tools.ResourceFromKind(string(kind)))
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking references: %w", err)
+		}
+		issues, err := references.Check(inventory)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking references: %w", err)
+		}
+		text, err := json.Marshal(checkReferencesResult{Issues: issues, SkippedKinds: inventory.SkippedKinds})
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling reference issues: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.ProjectResource,
+		RegisterWith: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
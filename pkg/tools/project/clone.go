@@ -0,0 +1,241 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+// cloneKinds lists the kinds copied along with a project, in the order they are created:
+// the datasources and variables before the dashboards, and the roles before their bindings.
+// The secrets are not copied: Perses only returns their masked values.
+var cloneKinds = []v1.Kind{
+	v1.KindDatasource,
+	v1.KindVariable,
+	v1.KindDashboard,
+	v1.KindRole,
+	v1.KindRoleBinding,
+}
+
+type CloneProjectInput struct {
+	Project        string            `json:"project"`
+	NewProject     string            `json:"new_project"`
+	DisplayName    string            `json:"display_name,omitempty"`
+	ExcludeKinds   []string          `json:"exclude_kinds,omitempty"`
+	SubjectMapping map[string]string `json:"subject_mapping,omitempty"`
+}
+
+type cloneProjectResult struct {
+	Project *v1.Project     `json:"project"`
+	Objects []gitops.Result `json:"objects"`
+	// SecretsToRecreate lists the secrets of the copied project, which must be created with their values in the new project
+	// before the datasources using them work.
+	SecretsToRecreate []string `json:"secrets_to_recreate,omitempty"`
+}
+
+func (p *project) Clone() *tools.Tool {
+	kindNames := make([]any, 0, len(cloneKinds))
+	for _, kind := range cloneKinds {
+		kindNames = append(kindNames, string(kind))
+	}
+	tool := &mcp.Tool{
+		Name: "perses_clone_project",
+		Description: "Create a new project as a copy of an existing one, with its datasources, variables, dashboards, roles and role bindings. " +
+			"The secret values cannot be read from Perses, so the secrets are not copied: they are listed in secrets_to_recreate and must be created in the new project. " +
+			"The subjects of the role bindings can be substituted with subject_mapping. Returns the result for every copied object.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Name of the project to copy",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"new_project": {
+					Type:        "string",
+					Description: "Name of the project to create. It must not exist",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"display_name": {
+					Type:        "string",
+					Description: "Display name of the new project (defaults to the one of the copied project)",
+					MaxLength:   jsonschema.Ptr(75),
+				},
+				"exclude_kinds": {
+					Type:        "array",
+					Description: "Kinds of objects not to copy",
+					Items: &jsonschema.Schema{
+						Type: "string",
+						Enum: kindNames,
+					},
+				},
+				"subject_mapping": {
+					Type:        "object",
+					Description: "Role binding subjects to substitute, as a map of current subject name to new subject name",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+			},
+			Required: []string{"project", "new_project"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Clones a project with all its objects in Perses",
+			ReadOnlyHint:    false,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  false,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CloneProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		kinds := slices.DeleteFunc(slices.Clone(cloneKinds), func(kind v1.Kind) bool {
+			return slices.Contains(input.ExcludeKinds, string(kind))
+		})
+		for _, kind := range kinds {
+			r := tools.ResourceFromKind(string(kind))
+			if !tools.ResourceAllowed(ctx, r) {
+				return nil, nil, fmt.Errorf("the %s objects cannot be copied: resource '%s' is not available. Exclude the kind to clone the rest of the project", kind, r)
+			}
+		}
+		result, err := cloneProject(tools.Client(ctx, p.client), input, kinds)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error cloning project '%s' to '%s': %w", input.Project, input.NewProject, err)
+		}
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling cloned project '%s': %w", input.NewProject, err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.ProjectResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
+
+// cloneProject creates the new project, then copies the objects of the given kinds into it.
+// An error is returned only when the project cannot be created; the failure of an object is reported in its result.
+func cloneProject(client apiClient.ClientInterface, input CloneProjectInput, kinds []v1.Kind) (*cloneProjectResult, error) {
+	if input.Project == input.NewProject {
+		return nil, fmt.Errorf("the new project must have another name")
+	}
+	source, err := client.Project().Get(input.Project)
+	if err != nil {
+		return nil, err
+	}
+	_, err = client.Project().Get(input.NewProject)
+	if err == nil {
+		return nil, fmt.Errorf("project '%s' already exists", input.NewProject)
+	}
+	if !objects.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to check whether project '%s' exists: %w", input.NewProject, err)
+	}
+
+	// The objects are listed before anything is created, so that nothing is left behind when the project cannot be read.
+	var list []modelAPI.Entity
+	for _, kind := range kinds {
+		accessor, err := objects.For(client, kind, input.Project)
+		if err != nil {
+			return nil, err
+		}
+		kindObjects, err := accessor.List()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the %s objects of project '%s': %w", kind, input.Project, err)
+		}
+		list = append(list, kindObjects...)
+	}
+	secrets, err := client.Secret(input.Project).List("")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the secrets of project '%s': %w", input.Project, err)
+	}
+
+	newProject := &v1.Project{
+		Kind:     v1.KindProject,
+		Metadata: v1.Metadata{Name: input.NewProject, Tags: source.Metadata.Tags},
+		Spec:     source.Spec,
+	}
+	if input.DisplayName != "" {
+		display := common.Display{Name: input.DisplayName}
+		if source.Spec.Display != nil {
+			display.Description = source.Spec.Display.Description
+		}
+		newProject.Spec.Display = &display
+	}
+	created, err := client.Project().Create(newProject)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &cloneProjectResult{Project: created, Objects: make([]gitops.Result, 0, len(list))}
+	for _, obj := range list {
+		result.Objects = append(result.Objects, cloneObject(client, obj, input.NewProject, input.SubjectMapping))
+	}
+	for _, secret := range secrets {
+		result.SecretsToRecreate = append(result.SecretsToRecreate, secret.Metadata.Name)
+	}
+	return result, nil
+}
+
+func cloneObject(client apiClient.ClientInterface, obj modelAPI.Entity, project string, subjectMapping map[string]string) gitops.Result {
+	result := gitops.Result{Kind: objects.Kind(obj), Project: project, Name: objects.Name(obj), Action: gitops.ActionCreate}
+	clone, err := objects.Copy(obj)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	objects.SetProject(clone, project)
+	if roleBinding, ok := clone.(*v1.RoleBinding); ok {
+		for i, subject := range roleBinding.Spec.Subjects {
+			if newName, substituted := subjectMapping[subject.Name]; substituted {
+				roleBinding.Spec.Subjects[i].Name = newName
+			}
+		}
+	}
+	accessor, err := objects.ForObject(client, clone)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if _, err := accessor.Create(clone); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/persestest"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	shop        = `{"kind":"Project","metadata":{"name":"shop"},"spec":{"display":{"name":"Shop","description":"Online shop"}}}`
+	credentials = `{"kind":"Secret","metadata":{"name":"credentials","project":"shop"},"spec":{"basicAuth":{"username":"admin","password":"<secret>"}}}`
+	prometheus  = `{"kind":"Datasource","metadata":{"name":"prometheus","project":"shop"},"spec":{"default":true,"plugin":{"kind":"PrometheusDatasource","spec":{"proxy":{"kind":"HTTPProxy","spec":{"url":"http://prometheus:9090","secret":"credentials"}}}}}}`
+	job         = `{"kind":"Variable","metadata":{"name":"job","project":"shop"},"spec":{"kind":"TextVariable","spec":{"value":"api"}}}`
+	overview    = `{"kind":"Dashboard","metadata":{"name":"overview","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`
+	viewer      = `{"kind":"Role","metadata":{"name":"viewer","project":"shop"},"spec":{"permissions":[{"actions":["read"],"scopes":["Dashboard"]}]}}`
+	viewers     = `{"kind":"RoleBinding","metadata":{"name":"viewers","project":"shop"},"spec":{"role":"viewer","subjects":[{"kind":"User","name":"alice"},{"kind":"User","name":"bob"}]}}`
+)
+
+var shopObjects = []string{shop, credentials, prometheus, job, overview, viewer, viewers}
+
+// resultNames returns the kind and the name of every result, e.g. "Datasource/prometheus", prefixed with "failed" on error.
+func resultNames(results []gitops.Result) []string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		name := string(result.Kind) + "/" + result.Name
+		if result.Error != "" {
+			name = "failed " + name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestCloneProject(t *testing.T) {
+	testSuite := []struct {
+		title   string
+		input   CloneProjectInput
+		kinds   []v1.Kind
+		objects []string
+		writes  []string
+	}{
+		{
+			title:   "every kind",
+			input:   CloneProjectInput{Project: "shop", NewProject: "shop-copy"},
+			kinds:   cloneKinds,
+			objects: []string{"Datasource/prometheus", "Variable/job", "Dashboard/overview", "Role/viewer", "RoleBinding/viewers"},
+			writes: []string{
+				"POST projects/shop-copy",
+				"POST projects/shop-copy/datasources/prometheus",
+				"POST projects/shop-copy/variables/job",
+				"POST projects/shop-copy/dashboards/overview",
+				"POST projects/shop-copy/roles/viewer",
+				"POST projects/shop-copy/rolebindings/viewers",
+			},
+		},
+		{
+			title:   "excluded kinds",
+			input:   CloneProjectInput{Project: "shop", NewProject: "shop-copy"},
+			kinds:   []v1.Kind{v1.KindDashboard},
+			objects: []string{"Dashboard/overview"},
+			writes:  []string{"POST projects/shop-copy", "POST projects/shop-copy/dashboards/overview"},
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			store, client := persestest.New(t, shopObjects...)
+			result, err := cloneProject(client, test.input, test.kinds)
+			require.NoError(t, err)
+			assert.Equal(t, "shop-copy", result.Project.Metadata.Name)
+			assert.Equal(t, test.objects, resultNames(result.Objects))
+			assert.Equal(t, []string{"credentials"}, result.SecretsToRecreate)
+			assert.Equal(t, test.writes, store.Writes(), "the secrets are never copied")
+			_, ok := store.Get("projects/shop-copy/secrets/credentials")
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestCloneProjectSubstitutions(t *testing.T) {
+	store, client := persestest.New(t, shopObjects...)
+	input := CloneProjectInput{
+		Project:        "shop",
+		NewProject:     "shop-copy",
+		DisplayName:    "Shop copy",
+		SubjectMapping: map[string]string{"alice": "carol"},
+	}
+	result, err := cloneProject(client, input, cloneKinds)
+	require.NoError(t, err)
+	require.NotNil(t, result.Project.Spec.Display)
+	assert.Equal(t, "Shop copy", result.Project.Spec.Display.Name)
+	assert.Equal(t, "Online shop", result.Project.Spec.Display.Description, "the description is kept")
+
+	data, ok := store.Get("projects/shop-copy/rolebindings/viewers")
+	require.True(t, ok)
+	var roleBinding v1.RoleBinding
+	require.NoError(t, json.Unmarshal(data, &roleBinding))
+	assert.Equal(t, "shop-copy", roleBinding.Metadata.Project)
+	var subjects []string
+	for _, subject := range roleBinding.Spec.Subjects {
+		subjects = append(subjects, subject.Name)
+	}
+	assert.Equal(t, []string{"carol", "bob"}, subjects)
+}
+
+func TestCloneProjectErrors(t *testing.T) {
+	testSuite := []struct {
+		title string
+		input CloneProjectInput
+		err   string
+	}{
+		{
+			title: "same project",
+			input: CloneProjectInput{Project: "shop", NewProject: "shop"},
+			err:   "the new project must have another name",
+		},
+		{
+			title: "new project already exists",
+			input: CloneProjectInput{Project: "shop", NewProject: "store"},
+			err:   "project 'store' already exists",
+		},
+		{
+			title: "missing project",
+			input: CloneProjectInput{Project: "bank", NewProject: "bank-copy"},
+			err:   "not found",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			store, client := persestest.New(t, append([]string{`{"kind":"Project","metadata":{"name":"store"},"spec":{}}`}, shopObjects...)...)
+			_, err := cloneProject(client, test.input, cloneKinds)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+			assert.Empty(t, store.Writes())
+		})
+	}
+}
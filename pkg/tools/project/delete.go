@@ -0,0 +1,200 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/objects"
+	"github.com/perses/mcp-server/pkg/tools"
+	modelAPI "github.com/perses/perses/pkg/model/api"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+// cascadeKinds lists the kinds of the objects that Perses deletes along with a project.
+var cascadeKinds = []v1.Kind{
+	v1.KindDashboard,
+	v1.KindEphemeralDashboard,
+	v1.KindFolder,
+	v1.KindDatasource,
+	v1.KindVariable,
+	v1.KindSecret,
+	v1.KindRole,
+	v1.KindRoleBinding,
+}
+
+type DeleteProjectInput struct {
+	Name           string `json:"name" jsonschema:"Name of the project to delete"`
+	ConfirmCascade bool   `json:"confirm_cascade,omitempty" jsonschema:"Delete the project and everything it contains"`
+	CascadeHash    string `json:"cascade_hash,omitempty" jsonschema:"cascade_hash of the reviewed preview, required with confirm_cascade"`
+	Snapshot       bool   `json:"snapshot,omitempty" jsonschema:"Save the project and its objects in the snapshots directory before deleting it"`
+}
+
+// cascadedKind describes the objects of one kind deleted along with a project.
+type cascadedKind struct {
+	Kind  v1.Kind  `json:"kind"`
+	Count int      `json:"count"`
+	Names []string `json:"names,omitempty"`
+}
+
+type deletionResult struct {
+	Project string         `json:"project"`
+	Objects []cascadedKind `json:"objects"`
+	// CascadeHash identifies the objects deleted along with the project. It must be given back to delete the project.
+	CascadeHash string `json:"cascade_hash"`
+	Deleted     bool   `json:"deleted"`
+	// Snapshot is the directory where the project was saved before being deleted.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+func (p *project) Delete() *tools.Tool {
+	tool := &mcp.Tool{
+		Name: "perses_delete_project",
+		Description: "Delete a Perses project along with its dashboards, ephemeral dashboards, folders, datasources, variables, secrets, roles and role bindings. " +
+			"Without confirm_cascade, nothing is deleted: the tool returns the count and the names of the objects that would be lost, and the cascade_hash of this preview. " +
+			"Call it again with confirm_cascade=true and the cascade_hash to delete the project; the call fails if the content of the project changed in the meantime. " +
+			"Add snapshot=true to save the project in the snapshots directory first " +
+			"(the snapshot holds the datasources, variables, dashboards, roles and role bindings).",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Deletes a project in Perses",
+			ReadOnlyHint:    false,
+			DestructiveHint: jsonschema.Ptr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"name": {
+					Type:        "string",
+					Description: "Name of the project to delete",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"confirm_cascade": {
+					Type:        "boolean",
+					Description: "Delete the project and everything it contains. When false, the objects that would be deleted are only listed",
+				},
+				"cascade_hash": {
+					Type:        "string",
+					Description: "cascade_hash of the reviewed preview, required with confirm_cascade",
+				},
+				"snapshot": {
+					Type:        "boolean",
+					Description: "Save the project and its objects in the snapshots directory before deleting it",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		if input.Snapshot && p.snapshotsDirectory == "" {
+			return nil, nil, fmt.Errorf("taking a snapshot is disabled: snapshots_directory is not configured")
+		}
+		client := tools.Client(ctx, p.client)
+		list, err := gitops.ExportProject(client, input.Name, cascadeKinds)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing the content of project '%s': %w", input.Name, err)
+		}
+		result := &deletionResult{Project: input.Name}
+		for _, kind := range cascadeKinds {
+			cascaded := cascadedKind{Kind: kind}
+			for _, obj := range list {
+				if objects.Kind(obj) == kind {
+					cascaded.Count++
+					cascaded.Names = append(cascaded.Names, objects.Name(obj))
+				}
+			}
+			slices.Sort(cascaded.Names)
+			result.Objects = append(result.Objects, cascaded)
+		}
+		result.CascadeHash, err = hashCascade(result)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.ConfirmCascade {
+			if input.CascadeHash == "" {
+				return nil, nil, fmt.Errorf("cascade_hash is required with confirm_cascade: review the objects deleted along with project '%s' first", input.Name)
+			}
+			if input.CascadeHash != result.CascadeHash {
+				return nil, nil, fmt.Errorf("the content of project '%s' changed since cascade_hash '%s' was computed, review the new preview (cascade_hash '%s') before confirming it", input.Name, input.CascadeHash, result.CascadeHash)
+			}
+			if input.Snapshot {
+				// Only the kinds that can be applied again are saved: the secret values are masked by Perses.
+				snapshot := slices.DeleteFunc(slices.Clone(list), func(obj modelAPI.Entity) bool {
+					kind := objects.Kind(obj)
+					return kind != v1.KindProject && !slices.Contains(gitops.ProjectKinds, kind)
+				})
+				files, err := gitops.ToFiles(snapshot, gitops.YAMLFormat)
+				if err != nil {
+					return nil, nil, fmt.Errorf("error taking a snapshot of project '%s': %w", input.Name, err)
+				}
+				dir := filepath.Join(p.snapshotsDirectory, time.Now().UTC().Format("20060102T150405Z"))
+				if err := gitops.WriteFiles(dir, files); err != nil {
+					return nil, nil, fmt.Errorf("error taking a snapshot of project '%s': %w", input.Name, err)
+				}
+				result.Snapshot = filepath.Join(dir, input.Name)
+			}
+			if err := client.Project().Delete(input.Name); err != nil {
+				return nil, nil, fmt.Errorf("error deleting project '%s': %w", input.Name, err)
+			}
+			result.Deleted = true
+		}
+
+		text, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling deletion of project '%s': %w", input.Name, err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(text),
+				},
+			},
+		}, nil, nil
+	}
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  true,
+		ResourceType: tools.ProjectResource,
+		RegisterWith: func(server *mcp.Server) {
+			mcp.AddTool(server, tool, handler)
+		},
+	}
+}
+
+// hashCascade returns the hash of the objects deleted along with the project.
+func hashCascade(result *deletionResult) (string, error) {
+	data, err := json.Marshal(struct {
+		Project string         `json:"project"`
+		Objects []cascadedKind `json:"objects"`
+	}{Project: result.Project, Objects: result.Objects})
+	if err != nil {
+		return "", fmt.Errorf("error hashing the content of project '%s': %w", result.Project, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
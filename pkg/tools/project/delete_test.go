@@ -0,0 +1,162 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/persestest"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	folder    = `{"kind":"Folder","metadata":{"name":"team","project":"shop"},"spec":[{"kind":"Dashboard","name":"overview"}]}`
+	ephemeral = `{"kind":"EphemeralDashboard","metadata":{"name":"debug","project":"shop"},"spec":{"ttl":"1d","duration":"1h","panels":{},"layouts":[]}}`
+)
+
+// deleteSession registers perses_delete_project for the fake Perses holding the given objects,
+// and returns a client session calling it.
+func deleteSession(t *testing.T, snapshotsDirectory string, live ...string) (*mcp.ClientSession, *persestest.Store) {
+	store, client := persestest.New(t, live...)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	New(client, snapshotsDirectory).(*project).Delete().RegisterWith(server)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	return session, store
+}
+
+// deleteProject calls perses_delete_project on the project shop, and returns the result or the error message of the tool.
+func deleteProject(t *testing.T, session *mcp.ClientSession, arguments map[string]any) (*deletionResult, string) {
+	arguments["name"] = "shop"
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "perses_delete_project", Arguments: arguments})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError {
+		return nil, text
+	}
+	var deletion deletionResult
+	require.NoError(t, json.Unmarshal([]byte(text), &deletion))
+	return &deletion, ""
+}
+
+func TestDeleteProjectPreview(t *testing.T) {
+	session, store := deleteSession(t, "", append([]string{folder, ephemeral}, shopObjects...)...)
+	preview, errMessage := deleteProject(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+	assert.False(t, preview.Deleted)
+	assert.NotEmpty(t, preview.CascadeHash)
+	assert.Equal(t, []cascadedKind{
+		{Kind: v1.KindDashboard, Count: 1, Names: []string{"overview"}},
+		{Kind: v1.KindEphemeralDashboard, Count: 1, Names: []string{"debug"}},
+		{Kind: v1.KindFolder, Count: 1, Names: []string{"team"}},
+		{Kind: v1.KindDatasource, Count: 1, Names: []string{"prometheus"}},
+		{Kind: v1.KindVariable, Count: 1, Names: []string{"job"}},
+		{Kind: v1.KindSecret, Count: 1, Names: []string{"credentials"}},
+		{Kind: v1.KindRole, Count: 1, Names: []string{"viewer"}},
+		{Kind: v1.KindRoleBinding, Count: 1, Names: []string{"viewers"}},
+	}, preview.Objects)
+	for _, kind := range cascadeKinds {
+		assert.Contains(t, store.Requests(), "GET projects/shop/"+v1.PluralKindMap[kind], "every cascaded kind is listed")
+	}
+	assert.Empty(t, store.Writes(), "nothing is deleted without confirm_cascade")
+}
+
+func TestDeleteProjectConfirm(t *testing.T) {
+	session, store := deleteSession(t, "", shopObjects...)
+	preview, errMessage := deleteProject(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+
+	deletion, errMessage := deleteProject(t, session, map[string]any{"confirm_cascade": true, "cascade_hash": preview.CascadeHash})
+	require.Empty(t, errMessage)
+	assert.True(t, deletion.Deleted)
+	assert.Equal(t, []string{"DELETE projects/shop"}, store.Writes())
+	_, ok := store.Get("projects/shop/dashboards/overview")
+	assert.False(t, ok)
+}
+
+func TestDeleteProjectRejectsInvalidCascadeHash(t *testing.T) {
+	testSuite := []struct {
+		title string
+		// change modifies the project after the preview.
+		change      func(t *testing.T, store *persestest.Store)
+		cascadeHash func(preview *deletionResult) string
+		err         string
+	}{
+		{
+			title:       "missing cascade hash",
+			cascadeHash: func(*deletionResult) string { return "" },
+			err:         "cascade_hash is required with confirm_cascade",
+		},
+		{
+			title:       "mismatched cascade hash",
+			cascadeHash: func(*deletionResult) string { return "0123456789abcdef" },
+			err:         "the content of project 'shop' changed since cascade_hash '0123456789abcdef' was computed",
+		},
+		{
+			title: "object added after the preview",
+			change: func(t *testing.T, store *persestest.Store) {
+				store.Put(t, `{"kind":"Dashboard","metadata":{"name":"errors","project":"shop"},"spec":{"duration":"1h","panels":{},"layouts":[]}}`)
+			},
+			cascadeHash: func(preview *deletionResult) string { return preview.CascadeHash },
+			err:         "the content of project 'shop' changed",
+		},
+	}
+	for _, test := range testSuite {
+		t.Run(test.title, func(t *testing.T) {
+			session, store := deleteSession(t, "", shopObjects...)
+			preview, errMessage := deleteProject(t, session, map[string]any{})
+			require.Empty(t, errMessage)
+			if test.change != nil {
+				test.change(t, store)
+			}
+			arguments := map[string]any{"confirm_cascade": true}
+			if hash := test.cascadeHash(preview); hash != "" {
+				arguments["cascade_hash"] = hash
+			}
+			_, errMessage = deleteProject(t, session, arguments)
+			assert.Contains(t, errMessage, test.err)
+			assert.Empty(t, store.Writes())
+		})
+	}
+}
+
+func TestDeleteProjectSnapshot(t *testing.T) {
+	snapshotsDirectory := t.TempDir()
+	session, _ := deleteSession(t, snapshotsDirectory, shopObjects...)
+	preview, errMessage := deleteProject(t, session, map[string]any{})
+	require.Empty(t, errMessage)
+
+	deletion, errMessage := deleteProject(t, session, map[string]any{"confirm_cascade": true, "cascade_hash": preview.CascadeHash, "snapshot": true})
+	require.Empty(t, errMessage)
+	require.True(t, deletion.Deleted)
+	assert.FileExists(t, filepath.Join(deletion.Snapshot, "project.yaml"))
+	assert.FileExists(t, filepath.Join(deletion.Snapshot, "dashboards", "overview.yaml"))
+	_, err := os.Stat(filepath.Join(deletion.Snapshot, "secrets"))
+	assert.True(t, os.IsNotExist(err), "the secrets are not saved")
+}
@@ -0,0 +1,145 @@
+// Copyright The Perses Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/perses/mcp-server/pkg/gitops"
+	"github.com/perses/mcp-server/pkg/tools"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+type ExportProjectInput struct {
+	Project string   `json:"project" jsonschema:"Name of the project to export"`
+	Format  string   `json:"format,omitempty" jsonschema:"Format of the files: yaml (default) or json"`
+	Kinds   []string `json:"kinds,omitempty" jsonschema:"Kinds of objects to export"`
+}
+
+type exportResult struct {
+	Files []gitops.File `json:"files"`
+	// SkippedKinds lists the kinds that were not exported, with the reason.
+	SkippedKinds []skippedKind `json:"skipped_kinds,omitempty"`
+}
+
+type skippedKind struct {
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+func (p *project) Export() *tools.Tool {
+	kindNames := make([]any, 0, len(gitops.ProjectKinds))
+	for _, kind := range gitops.ProjectKinds {
+		kindNames = append(kindNames, string(kind))
+	}
+	tool := &mcp.Tool{
+		Name: "perses_export_project",
+		Description: "Export a project with its dashboards, datasources, variables, roles and role bindings as one file per object, " +
+			"in a directory layout that can be committed to git and applied with `percli apply -d`. " +
+			"The metadata managed by Perses (version, createdAt, updatedAt) is removed. Returns the path and the content of every file, " +
+			"and the kinds that were skipped because they are unknown or not available on the MCP server.",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"project": {
+					Type:        "string",
+					Description: "Project name",
+					MinLength:   jsonschema.Ptr(1),
+					MaxLength:   jsonschema.Ptr(75),
+					Pattern:     "^[a-zA-Z0-9_.-]+$",
+				},
+				"format": {
+					Type:        "string",
+					Description: "Format of the files",
+					Enum:        []any{string(gitops.YAMLFormat), string(gitops.JSONFormat)},
+					Default:     json.RawMessage(`"yaml"`),
+				},
+				"kinds": {
+					Type:        "array",
+					Description: "Kinds of objects to export (defaults to all of them)",
+					Items: &jsonschema.Schema{
+						Type: "string",
+						Enum: kindNames,
+					},
+				},
+			},
+			Required: []string{"project"},
+		},
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Exports a project to a GitOps directory layout",
+			ReadOnlyHint:    true,
+			DestructiveHint: jsonschema.Ptr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   jsonschema.Ptr(false),
+		},
+	}
+
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ExportProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		format, err := gitops.ParseFormat(input.Format)
+		if err != nil {
+			return nil, nil, err
+		}
+		result := exportResult{}
+		kinds := slices.Clone(gitops.ProjectKinds)
+		if len(input.Kinds) > 0 {
+			kinds = slices.DeleteFunc(kinds, func(kind v1.Kind) bool {
+				return !slices.Contains(input.Kinds, string(kind))
+			})
+			for _, kind := range input.Kinds {
+				if !slices.Contains(gitops.ProjectKinds, v1.Kind(kind)) {
+					result.SkippedKinds = append(result.SkippedKinds, skippedKind{Kind: kind, Reason: "not a kind exported with a project"})
+				}
+			}
+		}
+		// The export must not reveal the objects of the resources that are disabled on the MCP server.
+		kinds = slices.DeleteFunc(kinds, func(kind v1.Kind) bool {
+			if tools.ResourceAllowed(ctx, tools.ResourceFromKind(string(kind))) {
+				return false
+			}
+			result.SkippedKinds = append(result.SkippedKinds, skippedKind{Kind: string(kind), Reason: "resource is not available"})
+			return true
+		})
+		list, err := gitops.ExportProject(tools.Client(ctx, p.client), input.Project, kinds)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error exporting project '%s': %w", input.Project, err)
+		}
+		result.Files, err = gitops.ToFiles(list, format)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error exporting project '%s': %w", input.Project, err)
+		}
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error marshalling exported files of project '%s': %w", input.Project, err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{
+					Text: string(resultJSON),
+				},
+			},
+		}, nil, nil
+	}
+
+	return &tools.Tool{
+		MCPTool:      tool,
+		IsWriteTool:  false,
+		ResourceType: tools.ProjectResource,
+		RegisterWith: func(server *mcp.Server) { mcp.AddTool(server, tool, handler) },
+	}
+}
@@ -29,11 +29,14 @@ import (
 
 type project struct {
 	client apiClient.ClientInterface
+	// snapshotsDirectory is where the projects are saved before being deleted. Snapshots are disabled when it is empty.
+	snapshotsDirectory string
 }
 
-func New(client apiClient.ClientInterface) resource.Resource {
+func New(client apiClient.ClientInterface, snapshotsDirectory string) resource.Resource {
 	return &project{
-		client: client,
+		client:             client,
+		snapshotsDirectory: snapshotsDirectory,
 	}
 }
 
@@ -80,7 +83,7 @@ func (p *project) Create() *tools.Tool {
 		},
 		Name: "perses_create_project",
 	}
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		newProjectRequest := &v1.Project{
 			Kind: "Project",
 			Metadata: v1.Metadata{
@@ -93,7 +96,7 @@ func (p *project) Create() *tools.Tool {
 				},
 			},
 		}
-		response, err := p.client.Project().Create(newProjectRequest)
+		response, err := tools.Client(ctx, p.client).Project().Create(newProjectRequest)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating project '%s': %w", input.Project, err)
 		}
@@ -133,8 +136,8 @@ func (p *project) List() *tools.Tool {
 			OpenWorldHint:   jsonschema.Ptr(false),
 		},
 	}
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input ListProjectsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		projects, err := p.client.Project().List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ListProjectsInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		projects, err := tools.Client(ctx, p.client).Project().List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving projects: %w", err)
 		}
@@ -202,7 +205,7 @@ func (p *project) Update() *tools.Tool {
 			Required: []string{"name"},
 		},
 	}
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		updateProjectRequest := &v1.Project{
 			Kind: "Project",
 			Metadata: v1.Metadata{
@@ -215,7 +218,7 @@ func (p *project) Update() *tools.Tool {
 				},
 			},
 		}
-		response, err := p.client.Project().Update(updateProjectRequest)
+		response, err := tools.Client(ctx, p.client).Project().Update(updateProjectRequest)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating project '%s': %w", input.Name, err)
 		}
@@ -241,58 +244,6 @@ func (p *project) Update() *tools.Tool {
 	}
 }
 
-type DeleteProjectInput struct {
-	Name string `json:"name" jsonschema:"Name of the project to delete"`
-}
-
-func (p *project) Delete() *tools.Tool {
-	tool := &mcp.Tool{
-		Name:        "perses_delete_project",
-		Description: "Delete a Perses project",
-		Annotations: &mcp.ToolAnnotations{
-			Title:           "Deletes a project in Perses",
-			ReadOnlyHint:    false,
-			DestructiveHint: jsonschema.Ptr(true),
-			IdempotentHint:  true,
-			OpenWorldHint:   jsonschema.Ptr(false),
-		},
-		InputSchema: &jsonschema.Schema{
-			Type: "object",
-			Properties: map[string]*jsonschema.Schema{
-				"name": {
-					Type:        "string",
-					Description: "Name of the project to delete",
-					MinLength:   jsonschema.Ptr(1),
-					MaxLength:   jsonschema.Ptr(75),
-					Pattern:     "^[a-zA-Z0-9_.-]+$",
-				},
-			},
-			Required: []string{"name"},
-		},
-	}
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := p.client.Project().Delete(input.Name)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error deleting project '%s': %w", input.Name, err)
-		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("Project '%s' deleted successfully", input.Name),
-				},
-			},
-		}, nil, nil
-	}
-	return &tools.Tool{
-		MCPTool:      tool,
-		IsWriteTool:  true,
-		ResourceType: tools.ProjectResource,
-		RegisterWith: func(server *mcp.Server) {
-			mcp.AddTool(server, tool, handler)
-		},
-	}
-}
-
 type GetProjectInput struct {
 	Name string `json:"name" jsonschema:"Name of the project to retrieve"`
 }
@@ -322,8 +273,8 @@ func (p *project) Get() *tools.Tool {
 			Required: []string{"name"},
 		},
 	}
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		response, err := p.client.Project().Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetProjectInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		response, err := tools.Client(ctx, p.client).Project().Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving project '%s': %w", input.Name, err)
 		}
@@ -356,5 +307,8 @@ func (p *project) GetTools() []*tools.Tool {
 		p.Create(),
 		p.Update(),
 		p.Delete(),
+		p.Export(),
+		p.Clone(),
+		p.CheckReferences(),
 	}
 }
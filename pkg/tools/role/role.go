@@ -75,8 +75,8 @@ func (r *role) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input ProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		roles, err := r.client.Role(input.Project).List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		roles, err := tools.Client(ctx, r.client).Role(input.Project).List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving roles in project '%s': %w", input.Project, err)
 		}
@@ -140,8 +140,8 @@ func (r *role) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetProjectRoleByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		role, err := r.client.Role(input.Project).Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetProjectRoleByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		role, err := tools.Client(ctx, r.client).Role(input.Project).Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving role '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -221,7 +221,7 @@ func (r *role) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		actions := make([]roleModel.Action, len(input.Actions))
 		for i, a := range input.Actions {
 			actions[i] = roleModel.Action(a)
@@ -251,7 +251,7 @@ func (r *role) Create() *tools.Tool {
 			},
 		}
 
-		result, err := r.client.Role(input.Project).Create(roleObj)
+		result, err := tools.Client(ctx, r.client).Role(input.Project).Create(roleObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating role '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -331,7 +331,7 @@ func (r *role) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		actions := make([]roleModel.Action, len(input.Actions))
 		for i, a := range input.Actions {
 			actions[i] = roleModel.Action(a)
@@ -361,7 +361,7 @@ func (r *role) Update() *tools.Tool {
 			},
 		}
 
-		result, err := r.client.Role(input.Project).Update(roleObj)
+		result, err := tools.Client(ctx, r.client).Role(input.Project).Update(roleObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating role '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -425,8 +425,8 @@ func (r *role) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := r.client.Role(input.Project).Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteProjectRoleInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, r.client).Role(input.Project).Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting role '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
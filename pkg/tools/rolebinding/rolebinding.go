@@ -76,8 +76,8 @@ func (r *roleBinding) List() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input ProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		roleBindings, err := r.client.RoleBinding(input.Project).List("")
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input ProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		roleBindings, err := tools.Client(ctx, r.client).RoleBinding(input.Project).List("")
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving role bindings in project '%s': %w", input.Project, err)
 		}
@@ -141,8 +141,8 @@ func (r *roleBinding) Get() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input GetProjectRoleBindingByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		roleBinding, err := r.client.RoleBinding(input.Project).Get(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input GetProjectRoleBindingByNameInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		roleBinding, err := tools.Client(ctx, r.client).RoleBinding(input.Project).Get(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error retrieving role binding '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -220,7 +220,7 @@ func (r *roleBinding) Create() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input CreateProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input CreateProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		subjects := make([]v1.Subject, len(input.Subjects))
 		for i, s := range input.Subjects {
 			subjects[i] = v1.Subject{
@@ -245,7 +245,7 @@ func (r *roleBinding) Create() *tools.Tool {
 			},
 		}
 
-		result, err := r.client.RoleBinding(input.Project).Create(roleBindingObj)
+		result, err := tools.Client(ctx, r.client).RoleBinding(input.Project).Create(roleBindingObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error creating role binding '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -323,7 +323,7 @@ func (r *roleBinding) Update() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input UpdateProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input UpdateProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
 		subjects := make([]v1.Subject, len(input.Subjects))
 		for i, s := range input.Subjects {
 			subjects[i] = v1.Subject{
@@ -348,7 +348,7 @@ func (r *roleBinding) Update() *tools.Tool {
 			},
 		}
 
-		result, err := r.client.RoleBinding(input.Project).Update(roleBindingObj)
+		result, err := tools.Client(ctx, r.client).RoleBinding(input.Project).Update(roleBindingObj)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error updating role binding '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
@@ -412,8 +412,8 @@ func (r *roleBinding) Delete() *tools.Tool {
 		},
 	}
 
-	handler := func(_ context.Context, _ *mcp.CallToolRequest, input DeleteProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
-		err := r.client.RoleBinding(input.Project).Delete(input.Name)
+	handler := func(ctx context.Context, _ *mcp.CallToolRequest, input DeleteProjectRoleBindingInput) (*mcp.CallToolResult, any, error) { //nolint:unparam
+		err := tools.Client(ctx, r.client).RoleBinding(input.Project).Delete(input.Name)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error deleting role binding '%s' in project '%s': %w", input.Name, input.Project, err)
 		}
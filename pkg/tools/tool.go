@@ -13,26 +13,40 @@
 
 package tools
 
-import "github.com/modelcontextprotocol/go-sdk/mcp"
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apiClient "github.com/perses/perses/pkg/client/api/v1"
+)
 
 type Resource string
 
 const (
-	DashboardResource         Resource = "dashboard"
-	DatasourceResource        Resource = "datasource"
-	ProjectResource           Resource = "project"
-	GlobalDatasourceResource  Resource = "globaldatasource"
-	RoleResource              Resource = "role"
-	GlobalRoleResource        Resource = "globalrole"
-	RoleBindingResource       Resource = "rolebinding"
-	GlobalRoleBindingResource Resource = "globalrolebinding"
-	VariableResource          Resource = "variable"
-	GlobalVariableResource    Resource = "globalvariable"
-	PluginResource            Resource = "plugin"
+	DashboardResource          Resource = "dashboard"
+	EphemeralDashboardResource Resource = "ephemeraldashboard"
+	DatasourceResource         Resource = "datasource"
+	ProjectResource            Resource = "project"
+	GlobalDatasourceResource   Resource = "globaldatasource"
+	RoleResource               Resource = "role"
+	GlobalRoleResource         Resource = "globalrole"
+	RoleBindingResource        Resource = "rolebinding"
+	GlobalRoleBindingResource  Resource = "globalrolebinding"
+	VariableResource           Resource = "variable"
+	GlobalVariableResource     Resource = "globalvariable"
+	PluginResource             Resource = "plugin"
+	// InstanceResource groups the tools describing the Perses instances themselves.
+	// They are always registered, so it is not part of ValidResources.
+	InstanceResource Resource = "instance"
+	// MultiResource groups the tools handling objects of several resources (e.g. perses_apply).
+	// They are available whenever an instance is, and check each object against ResourceAllowed.
+	MultiResource Resource = "*"
 )
 
 var ValidResources = []Resource{
 	DashboardResource,
+	EphemeralDashboardResource,
 	DatasourceResource,
 	ProjectResource,
 	GlobalDatasourceResource,
@@ -55,3 +69,40 @@ type Tool struct {
 	// This function encapsulates the typed handler registration
 	RegisterWith func(server *mcp.Server)
 }
+
+type clientContextKey struct{}
+
+type resourceFilterContextKey struct{}
+
+// WithClient returns a copy of ctx carrying the Perses client the tools must use for the current call.
+// It is used to route a tool call to the Perses instance selected by the caller.
+func WithClient(ctx context.Context, client apiClient.ClientInterface) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// Client returns the Perses client carried by ctx, or defaultClient when there is none.
+func Client(ctx context.Context, defaultClient apiClient.ClientInterface) apiClient.ClientInterface {
+	if client, ok := ctx.Value(clientContextKey{}).(apiClient.ClientInterface); ok && client != nil {
+		return client
+	}
+	return defaultClient
+}
+
+// WithResourceFilter returns a copy of ctx carrying the function telling which resources can be used by the current call.
+func WithResourceFilter(ctx context.Context, allows func(resource Resource) bool) context.Context {
+	return context.WithValue(ctx, resourceFilterContextKey{}, allows)
+}
+
+// ResourceAllowed returns true when the current call can use the given resource.
+// Every resource is allowed when ctx doesn't carry any filter.
+func ResourceAllowed(ctx context.Context, resource Resource) bool {
+	if allows, ok := ctx.Value(resourceFilterContextKey{}).(func(resource Resource) bool); ok {
+		return allows(resource)
+	}
+	return true
+}
+
+// ResourceFromKind returns the resource of a Perses kind (e.g. "dashboard" for Dashboard).
+func ResourceFromKind(kind string) Resource {
+	return Resource(strings.ToLower(kind))
+}